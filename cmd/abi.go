@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dyng/ramen/internal/common"
+	conf "github.com/dyng/ramen/internal/config"
+	"github.com/dyng/ramen/internal/service"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// defaultEditor is used to edit ABI when $EDITOR is not set
+const defaultEditor = "vi"
+
+func abiCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "abi",
+		Short: "Manage ABI registry",
+		Long:  "Manage ABI registry, where ABIs imported in ramen are saved by chain id and contract address",
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			// read and parse configurations from config file
+			if err := conf.ParseConfig(config); err != nil {
+				common.Exit("Cannot parse config file: %v", err)
+			}
+		},
+	}
+
+	cmd.AddCommand(abiListCmd())
+	cmd.AddCommand(abiEditCmd())
+	cmd.AddCommand(abiDeleteCmd())
+
+	return cmd
+}
+
+func abiListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List ABIs in registry",
+		Long:  "List ABIs in registry",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			entries, err := service.NewABIRegistry(config.ABIDir).List()
+			if err != nil {
+				common.Exit("Cannot list ABI registry: %v", err)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CHAIN\tADDRESS\tPATH")
+			for _, entry := range entries {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", entry.ChainId, entry.Address.Hex(), entry.Path)
+			}
+			w.Flush()
+		},
+	}
+}
+
+func abiEditCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit <chain id> <address>",
+		Short: "Edit or add an ABI in registry with $EDITOR",
+		Long:  "Edit or add an ABI in registry with $EDITOR, it is saved only if it is a valid ABI json, or an object containing `abi` and `storageLayout`",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			chainId, address := parseRegistryKey(args)
+			registry := service.NewABIRegistry(config.ABIDir)
+
+			abiJson, found, err := registry.Get(chainId, address)
+			if err != nil {
+				common.Exit("Cannot read ABI registry: %v", err)
+			}
+			if !found {
+				abiJson = "[]"
+			}
+
+			edited, err := editInEditor(abiJson)
+			if err != nil {
+				common.Exit("Cannot edit ABI: %v", err)
+			}
+			if edited == abiJson {
+				common.PrintMessage("ABI of %s on chain %s is not changed", address.Hex(), chainId)
+				return
+			}
+
+			if err := registry.Put(chainId, address, edited); err != nil {
+				common.Exit("Cannot save ABI: %v", err)
+			}
+			common.PrintMessage("ABI of %s on chain %s is saved to %s", address.Hex(), chainId, registry.Path(chainId, address))
+		},
+	}
+}
+
+func abiDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <chain id> <address>",
+		Short: "Delete an ABI from registry",
+		Long:  "Delete an ABI from registry",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			chainId, address := parseRegistryKey(args)
+			if err := service.NewABIRegistry(config.ABIDir).Delete(chainId, address); err != nil {
+				common.Exit("Cannot delete ABI: %v", err)
+			}
+			common.PrintMessage("ABI of %s on chain %s is deleted", address.Hex(), chainId)
+		},
+	}
+}
+
+func parseRegistryKey(args []string) (string, common.Address) {
+	chainId, address := args[0], args[1]
+	if _, ok := new(big.Int).SetString(chainId, 10); !ok {
+		common.Exit("Invalid chain id: %s", chainId)
+	}
+	if !gcommon.IsHexAddress(address) {
+		common.Exit("Invalid address: %s", address)
+	}
+	return chainId, gcommon.HexToAddress(address)
+}
+
+// editInEditor opens text in $EDITOR through a temporary file, and returns
+// the edited text.
+func editInEditor(text string) (string, error) {
+	file, err := os.CreateTemp("", "ramen-abi-*.json")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = defaultEditor
+	}
+	// editor may come with arguments, e.g. `code --wait`
+	fields := strings.Fields(editor)
+	if len(fields) == 0 {
+		return "", errors.New("$EDITOR is blank")
+	}
+	c := exec.Command(fields[0], append(fields[1:], file.Name())...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return "", err
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+	return string(edited), nil
+}
@@ -23,6 +23,7 @@ var (
 
 func init() {
 	rootCmd.AddCommand(versionCmd())
+	rootCmd.AddCommand(abiCmd())
 }
 
 func Execute() {
@@ -47,13 +48,6 @@ func NewRootCmd() *cobra.Command {
 		false,
 		"Should ramen run in debug mode",
 	)
-	flags.StringVarP(
-		&config.ConfigFile,
-		"config-file",
-		"c",
-		conf.DefaultConfigFile,
-		"Path to ramen's config file",
-	)
 	flags.StringVarP(
 		&config.Network,
 		"network",
@@ -80,6 +74,47 @@ func NewRootCmd() *cobra.Command {
 		"",
 		"ApiKey for Etherscan API",
 	)
+	flags.StringVar(
+		&config.KeystoreDir,
+		"keystore",
+		conf.DefaultKeystoreDir,
+		"Directory of keystore files",
+	)
+	flags.StringVar(
+		&config.ExternalSigner,
+		"signer",
+		"",
+		"Endpoint of an external signer speaking Clef's API",
+	)
+	flags.StringVar(
+		&config.ProjectDir,
+		"project",
+		"",
+		"Directory of a Hardhat or Foundry project to find ABIs of local contracts",
+	)
+	flags.BoolVar(
+		&config.SkipSimulation,
+		"skip-simulation",
+		false,
+		"Do not simulate transactions before sending them",
+	)
+
+	// flags shared with subcommands
+	persistentFlags := cmd.PersistentFlags()
+
+	persistentFlags.StringVarP(
+		&config.ConfigFile,
+		"config-file",
+		"c",
+		conf.DefaultConfigFile,
+		"Path to ramen's config file",
+	)
+	persistentFlags.StringVar(
+		&config.ABIDir,
+		"abi-dir",
+		conf.DefaultABIDir,
+		"Directory of ABI registry",
+	)
 
 	return &cmd
 }
@@ -2,123 +2,336 @@ package conv
 
 import (
 	"math/big"
+	"reflect"
 	"strconv"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/pkg/errors"
 )
 
-// PackArgument packs a single argument into a string
+// indentUnit is the indentation of each nested level in pretty-printed values.
+const indentUnit = "  "
+
+// PackArgument packs a single argument into a string. Arrays and tuples are
+// packed in one line, e.g. [1, 2] and {to: 0x..., amount: 1}, which can be
+// unpacked by UnpackArgument.
 func PackArgument(t abi.Type, v any) (string, error) {
+	return formatValue(t, reflect.ValueOf(v), false, false, 0)
+}
+
+// PackArgumentPretty is like PackArgument, but prints nested arrays and tuples
+// over multiple lines with indentation.
+func PackArgumentPretty(t abi.Type, v any) (string, error) {
+	return formatValue(t, reflect.ValueOf(v), true, false, 0)
+}
+
+// UnpackArgument converts string format of a value into the Go type corresponding to given argument type.
+// Arrays and tuples are written in JSON-like syntax, e.g. [1, 2] and [0x..., 1] or {to: 0x..., amount: 1},
+// where strings in them should be quoted if they contain spaces or special characters.
+func UnpackArgument(t abi.Type, s string) (any, error) {
+	var val reflect.Value
+	var err error
 	switch t.T {
+	case abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		var lit *literal
+		lit, err = parseLiteral(s)
+		if err != nil {
+			return nil, err
+		}
+		val, err = fromLiteral(t, lit)
 	case abi.StringTy:
-		if v, ok := v.(string); ok {
-			return v, nil
-		} else {
-			return "", errors.Errorf("cannot convert %v to string", v)
+		// take top level string as it is
+		val = reflect.ValueOf(s)
+	default:
+		val, err = parseScalar(t, strings.TrimSpace(s))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return val.Interface(), nil
+}
+
+// fromLiteral converts a parsed literal into a value of given type.
+func fromLiteral(t abi.Type, lit *literal) (reflect.Value, error) {
+	switch t.T {
+	case abi.SliceTy:
+		if lit.kind != listLiteral {
+			return reflect.Value{}, errors.Errorf("expect a list for type %s", t)
 		}
-	case abi.IntTy, abi.UintTy:
-		return formatInteger(t, v)
-	case abi.BoolTy:
-		if v, ok := v.(bool); ok {
-			return strconv.FormatBool(v), nil
-		} else {
-			return "", errors.Errorf("cannot convert %v to bool", v)
+		val := reflect.MakeSlice(t.GetType(), len(lit.items), len(lit.items))
+		for i, item := range lit.items {
+			elem, err := fromLiteral(*t.Elem, item)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			val.Index(i).Set(elem)
 		}
-	case abi.AddressTy:
-		if v, ok := v.(common.Address); ok {
-			return v.Hex(), nil
-		} else {
-			return "", errors.Errorf("cannot convert %v to address", v)
+		return val, nil
+	case abi.ArrayTy:
+		if lit.kind != listLiteral {
+			return reflect.Value{}, errors.Errorf("expect a list for type %s", t)
 		}
-	case abi.HashTy:
-		if v, ok := v.(common.Hash); ok {
-			return v.Hex(), nil
-		} else {
-			return "", errors.Errorf("cannot convert %v to hash", v)
+		if len(lit.items) != t.Size {
+			return reflect.Value{}, errors.Errorf("expect %d items for type %s, got %d", t.Size, t, len(lit.items))
 		}
+		val := reflect.New(t.GetType()).Elem()
+		for i, item := range lit.items {
+			elem, err := fromLiteral(*t.Elem, item)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			val.Index(i).Set(elem)
+		}
+		return val, nil
+	case abi.TupleTy:
+		return tupleFromLiteral(t, lit)
 	default:
-		return "", errors.Errorf("unsupported argument type %v", t.T)
+		if lit.kind != scalarLiteral {
+			return reflect.Value{}, errors.Errorf("expect a single value for type %s", t)
+		}
+		return parseScalar(t, lit.text)
 	}
 }
 
-// UnpackArgument converts string format of a value into the Go type corresponding to given argument type.
-func UnpackArgument(t abi.Type, s string) (any, error) {
+// tupleFromLiteral converts a list of fields in order, or an object of fields
+// by name, into a tuple.
+func tupleFromLiteral(t abi.Type, lit *literal) (reflect.Value, error) {
+	items := lit.items
+	switch lit.kind {
+	case listLiteral:
+		if len(items) != len(t.TupleElems) {
+			return reflect.Value{}, errors.Errorf("expect %d fields for type %s, got %d", len(t.TupleElems), t, len(items))
+		}
+	case objectLiteral:
+		items = make([]*literal, len(t.TupleElems))
+		for i, key := range lit.keys {
+			idx := indexOf(t.TupleRawNames, key)
+			if idx < 0 {
+				return reflect.Value{}, errors.Errorf("unknown field %s of type %s", key, t)
+			}
+			items[idx] = lit.items[i]
+		}
+		for i, item := range items {
+			if item == nil {
+				return reflect.Value{}, errors.Errorf("missing field %s of type %s", t.TupleRawNames[i], t)
+			}
+		}
+	default:
+		return reflect.Value{}, errors.Errorf("expect a list or an object for type %s", t)
+	}
+
+	val := reflect.New(t.GetType()).Elem()
+	for i, item := range items {
+		field, err := fromLiteral(*t.TupleElems[i], item)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		val.Field(i).Set(field)
+	}
+	return val, nil
+}
+
+// parseScalar converts string format of a value of elementary type.
+func parseScalar(t abi.Type, s string) (reflect.Value, error) {
 	switch t.T {
 	case abi.StringTy:
-		return s, nil
+		return reflect.ValueOf(s), nil
 	case abi.IntTy, abi.UintTy:
 		return parseInteger(t, s)
 	case abi.BoolTy:
-		return strconv.ParseBool(s)
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return reflect.Value{}, errors.Errorf("cannot parse %s as type %s", s, t)
+		}
+		return reflect.ValueOf(b), nil
 	case abi.AddressTy:
-		return common.HexToAddress(s), nil
-	case abi.HashTy:
-		return common.HexToHash(s), nil
+		if !common.IsHexAddress(s) {
+			return reflect.Value{}, errors.Errorf("cannot parse %s as type %s", s, t)
+		}
+		return reflect.ValueOf(common.HexToAddress(s)), nil
+	case abi.BytesTy:
+		b, err := HexToBytes(s)
+		if err != nil {
+			return reflect.Value{}, errors.Errorf("cannot parse %s as type %s", s, t)
+		}
+		return reflect.ValueOf(b), nil
+	case abi.FixedBytesTy, abi.HashTy, abi.FixedPointTy, abi.FunctionTy:
+		b, err := HexToBytes(s)
+		if err != nil {
+			return reflect.Value{}, errors.Errorf("cannot parse %s as type %s", s, t)
+		}
+		val := reflect.New(t.GetType()).Elem()
+		if len(b) > val.Len() {
+			return reflect.Value{}, errors.Errorf("%s is too long for type %s", s, t)
+		}
+		// bytesN is left aligned, so shorter input is padded with zeros on the right
+		reflect.Copy(val, reflect.ValueOf(b))
+		return val, nil
 	default:
-		return nil, errors.Errorf("unsupported argument type %v", t.T)
+		return reflect.Value{}, errors.Errorf("unsupported argument type %s", t)
 	}
 }
 
-func formatInteger(t abi.Type, v any) (string, error) {
-	if t.T == abi.UintTy {
-		switch t.Size {
-		case 8, 16, 32, 64:
-			if v, ok := v.(uint64); ok {
-				return strconv.FormatUint(v, 10), nil
-			} else {
-				return "", errors.Errorf("cannot convert %v to uint64", v)
-			}
-		default:
-			if v, ok := v.(*big.Int); ok {
-				return v.String(), nil
-			} else {
-				return "", errors.Errorf("cannot convert %v to *big.Int", v)
-			}
+// parseInteger parses a decimal or hex (0x prefixed) integer, and checks if it
+// fits in given type.
+func parseInteger(t abi.Type, s string) (reflect.Value, error) {
+	var i *big.Int
+	var ok bool
+	if has0xPrefix(s) {
+		i, ok = new(big.Int).SetString(s[2:], 16)
+	} else if strings.HasPrefix(s, "-0x") || strings.HasPrefix(s, "-0X") {
+		i, ok = new(big.Int).SetString(s[3:], 16)
+		if ok {
+			i.Neg(i)
 		}
 	} else {
-		switch t.Size {
-		case 8, 16, 32, 64:
-			if v, ok := v.(int64); ok {
-				return strconv.FormatInt(v, 10), nil
-			} else {
-				return "", errors.Errorf("cannot convert %v to int64", v)
+		i, ok = new(big.Int).SetString(s, 10)
+	}
+	if !ok {
+		return reflect.Value{}, errors.Errorf("cannot parse %s as type %s", s, t)
+	}
+
+	var min, max *big.Int
+	if t.T == abi.UintTy {
+		min = big.NewInt(0)
+		max = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(t.Size)), big.NewInt(1))
+	} else {
+		max = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(t.Size-1)), big.NewInt(1))
+		min = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), uint(t.Size-1)))
+	}
+	if i.Cmp(min) < 0 || i.Cmp(max) > 0 {
+		return reflect.Value{}, errors.Errorf("%s is out of range of type %s", s, t)
+	}
+
+	// integers of 8, 16, 32 and 64 bits are represented by native types, others by *big.Int
+	typ := t.GetType()
+	switch typ.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		val := reflect.New(typ).Elem()
+		val.SetInt(i.Int64())
+		return val, nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		val := reflect.New(typ).Elem()
+		val.SetUint(i.Uint64())
+		return val, nil
+	default:
+		return reflect.ValueOf(i), nil
+	}
+}
+
+// formatValue formats a value of given type, strings are quoted if nested.
+func formatValue(t abi.Type, v reflect.Value, pretty bool, nested bool, depth int) (string, error) {
+	if !v.IsValid() {
+		return "", errors.Errorf("cannot convert nil to %s", t)
+	}
+
+	switch t.T {
+	case abi.SliceTy, abi.ArrayTy:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return "", errors.Errorf("cannot convert %v to %s", v, t)
+		}
+		items := make([]string, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, err := formatValue(*t.Elem, v.Index(i), pretty, true, depth+1)
+			if err != nil {
+				return "", err
 			}
-		default:
-			if v, ok := v.(*big.Int); ok {
-				return v.String(), nil
-			} else {
-				return "", errors.Errorf("cannot convert %v to *big.Int", v)
+			items[i] = item
+		}
+		return joinItems("[", "]", items, pretty, depth), nil
+	case abi.TupleTy:
+		if v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct || v.NumField() != len(t.TupleElems) {
+			return "", errors.Errorf("cannot convert %v to %s", v, t)
+		}
+		items := make([]string, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			item, err := formatValue(*t.TupleElems[i], v.Field(i), pretty, true, depth+1)
+			if err != nil {
+				return "", err
 			}
+			items[i] = t.TupleRawNames[i] + ": " + item
 		}
+		return joinItems("{", "}", items, pretty, depth), nil
+	default:
+		return formatScalar(t, v, nested)
 	}
 }
 
-func parseInteger(t abi.Type, s string) (any, error) {
-	if t.T == abi.UintTy {
-		switch t.Size {
-		case 8, 16, 32, 64:
-			return strconv.ParseUint(s, 10, 64)
-		default:
-			i, ok := new(big.Int).SetString(s, 10)
-			if !ok {
-				return nil, errors.Errorf("cannot parse %s as type %v", s, t.T)
-			} else {
-				return i, nil
-			}
+// formatScalar formats a value of elementary type.
+func formatScalar(t abi.Type, v reflect.Value, quote bool) (string, error) {
+	switch t.T {
+	case abi.StringTy:
+		if v.Kind() != reflect.String {
+			return "", errors.Errorf("cannot convert %v to string", v)
 		}
-	} else {
-		switch t.Size {
-		case 8, 16, 32, 64:
-			return strconv.ParseInt(s, 10, 64)
-		default:
-			i, ok := new(big.Int).SetString(s, 10)
-			if !ok {
-				return nil, errors.Errorf("cannot parse %s as type %v", s, t.T)
-			} else {
-				return i, nil
-			}
+		if quote {
+			return strconv.Quote(v.String()), nil
+		}
+		return v.String(), nil
+	case abi.IntTy, abi.UintTy:
+		return formatInteger(t, v)
+	case abi.BoolTy:
+		if v.Kind() != reflect.Bool {
+			return "", errors.Errorf("cannot convert %v to bool", v)
+		}
+		return strconv.FormatBool(v.Bool()), nil
+	case abi.AddressTy:
+		if addr, ok := v.Interface().(common.Address); ok {
+			return addr.Hex(), nil
+		}
+		return "", errors.Errorf("cannot convert %v to address", v)
+	case abi.BytesTy, abi.FixedBytesTy, abi.HashTy, abi.FixedPointTy, abi.FunctionTy:
+		if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Type().Elem().Kind() != reflect.Uint8 {
+			return "", errors.Errorf("cannot convert %v to %s", v, t)
+		}
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		return hexutil.Encode(b), nil
+	default:
+		return "", errors.Errorf("unsupported argument type %s", t)
+	}
+}
+
+func formatInteger(t abi.Type, v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	default:
+		if i, ok := v.Interface().(*big.Int); ok && i != nil {
+			return i.String(), nil
+		}
+		return "", errors.Errorf("cannot convert %v to %s", v, t)
+	}
+}
+
+// joinItems joins formatted items of an array or tuple, one item per line if
+// pretty is true.
+func joinItems(open string, close string, items []string, pretty bool, depth int) string {
+	if len(items) == 0 {
+		return open + close
+	}
+	if !pretty {
+		return open + strings.Join(items, ", ") + close
+	}
+
+	indent := strings.Repeat(indentUnit, depth+1)
+	return open + "\n" + indent + strings.Join(items, ",\n"+indent) + "\n" + strings.Repeat(indentUnit, depth) + close
+}
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
 		}
 	}
+	return -1
 }
@@ -0,0 +1,137 @@
+package conv
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnpackArgument_Scalar(t *testing.T) {
+	tests := []struct {
+		typ      string
+		input    string
+		expected any
+	}{
+		{"string", "hello world", "hello world"},
+		{"uint8", "255", uint8(255)},
+		{"int16", "-0x10", int16(-16)},
+		{"uint256", "1000000000000000000000", bigInt("1000000000000000000000")},
+		{"int24", "-1", big.NewInt(-1)},
+		{"bool", "true", true},
+		{"address", "0xFABB0ac9d68B0B445fB7357272Ff202C5651694a", common.HexToAddress("0xFABB0ac9d68B0B445fB7357272Ff202C5651694a")},
+		{"bytes", "0x0102", []byte{1, 2}},
+		{"bytes4", "0x01", [4]byte{1, 0, 0, 0}},
+	}
+
+	for _, test := range tests {
+		// process
+		val, err := UnpackArgument(newType(t, test.typ, nil), test.input)
+
+		// verify
+		assert.NoError(t, err, test.typ)
+		assert.Equal(t, test.expected, val, test.typ)
+	}
+}
+
+func TestUnpackArgument_Invalid(t *testing.T) {
+	tests := []struct {
+		typ   string
+		input string
+	}{
+		{"uint8", "256"},
+		{"int8", "-129"},
+		{"uint256", "-1"},
+		{"address", "0x1234"},
+		{"bytes2", "0x010203"},
+		{"uint8[2]", "[1]"},
+		{"uint8[]", "[1, 2"},
+		{"uint8[]", "1"},
+	}
+
+	for _, test := range tests {
+		// process
+		_, err := UnpackArgument(newType(t, test.typ, nil), test.input)
+
+		// verify
+		assert.Error(t, err, test.typ+" "+test.input)
+	}
+}
+
+func TestUnpackArgument_Nested(t *testing.T) {
+	// prepare
+	typ := newType(t, "tuple[]", []abi.ArgumentMarshaling{
+		{Name: "name", Type: "string"},
+		{Name: "amounts", Type: "uint64[2]"},
+	})
+
+	// process
+	val, err := UnpackArgument(typ, `[["a, b", [1, 2]], {amounts: [3, 4], "name": c}]`)
+
+	// verify
+	assert.NoError(t, err)
+	str, err := PackArgument(typ, val)
+	assert.NoError(t, err)
+	assert.Equal(t, `[{name: "a, b", amounts: [1, 2]}, {name: "c", amounts: [3, 4]}]`, str)
+}
+
+func TestPackArgument_Packable(t *testing.T) {
+	// prepare
+	typ := newType(t, "tuple", []abi.ArgumentMarshaling{
+		{Name: "to", Type: "address"},
+		{Name: "data", Type: "bytes"},
+	})
+	args := abi.Arguments{{Type: typ}}
+	val, err := UnpackArgument(typ, "[0xFABB0ac9d68B0B445fB7357272Ff202C5651694a, 0xabcd]")
+	assert.NoError(t, err)
+
+	// process
+	packed, err := args.Pack(val)
+	assert.NoError(t, err)
+	unpacked, err := args.Unpack(packed)
+	assert.NoError(t, err)
+	str, err := PackArgument(typ, unpacked[0])
+
+	// verify
+	assert.NoError(t, err)
+	assert.Equal(t, "{to: 0xFABB0ac9d68B0B445fB7357272Ff202C5651694a, data: 0xabcd}", str)
+}
+
+func TestPackArgumentPretty(t *testing.T) {
+	// prepare
+	typ := newType(t, "tuple", []abi.ArgumentMarshaling{
+		{Name: "id", Type: "uint256"},
+		{Name: "tags", Type: "string[]"},
+		{Name: "empty", Type: "bool[]"},
+	})
+	val, err := UnpackArgument(typ, `[1, [x, y], []]`)
+	assert.NoError(t, err)
+
+	// process
+	str, err := PackArgumentPretty(typ, val)
+
+	// verify
+	assert.NoError(t, err)
+	expected := "{\n" +
+		"  id: 1,\n" +
+		"  tags: [\n" +
+		"    \"x\",\n" +
+		"    \"y\"\n" +
+		"  ],\n" +
+		"  empty: []\n" +
+		"}"
+	assert.Equal(t, expected, str)
+}
+
+func newType(t *testing.T, typ string, components []abi.ArgumentMarshaling) abi.Type {
+	ty, err := abi.NewType(typ, "", components)
+	assert.NoError(t, err)
+	return ty
+}
+
+func bigInt(s string) *big.Int {
+	i, _ := new(big.Int).SetString(s, 10)
+	return i
+}
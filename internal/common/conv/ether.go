@@ -4,6 +4,8 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
 )
 
 // ToEther converts values in wei to ether.
@@ -27,3 +29,21 @@ func FromGwei(n *big.Float) *big.Int {
 	i, _ := new(big.Float).Mul(n, big.NewFloat(params.GWei)).Int(nil)
 	return i
 }
+
+// ParseUnits converts a decimal string in token units to the smallest unit,
+// e.g. "1.5" with 6 decimals is 1500000.
+func ParseUnits(s string, decimals uint8) (*big.Int, error) {
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return nil, errors.Errorf("cannot parse amount %s", s)
+	}
+	if d.IsNegative() {
+		return nil, errors.Errorf("amount %s is negative", s)
+	}
+
+	shifted := d.Shift(int32(decimals))
+	if !shifted.Equal(shifted.Truncate(0)) {
+		return nil, errors.Errorf("amount %s has more than %d decimal places", s, decimals)
+	}
+	return shifted.BigInt(), nil
+}
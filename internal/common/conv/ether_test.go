@@ -0,0 +1,39 @@
+package conv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUnits(t *testing.T) {
+	tests := []struct {
+		input    string
+		decimals uint8
+		expected string
+	}{
+		{"1", 18, "1000000000000000000"},
+		{"1.5", 6, "1500000"},
+		{"0.000001", 6, "1"},
+		{"42", 0, "42"},
+	}
+
+	for _, test := range tests {
+		// process
+		n, err := ParseUnits(test.input, test.decimals)
+
+		// verify
+		assert.NoError(t, err, test.input)
+		assert.Equal(t, test.expected, n.String(), test.input)
+	}
+}
+
+func TestParseUnits_Invalid(t *testing.T) {
+	for _, input := range []string{"", "abc", "-1", "0.0000001"} {
+		// process
+		_, err := ParseUnits(input, 6)
+
+		// verify
+		assert.Error(t, err, input)
+	}
+}
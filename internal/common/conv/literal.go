@@ -0,0 +1,203 @@
+package conv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+type literalKind int
+
+const (
+	scalarLiteral literalKind = iota
+	listLiteral
+	objectLiteral
+)
+
+// literal is a parsed value in JSON-like syntax, which is a scalar, a list
+// such as [1, 2], or an object such as {a: 1, b: "x"}. Scalars may be quoted
+// or bare words.
+type literal struct {
+	kind  literalKind
+	text  string
+	items []*literal
+	keys  []string
+}
+
+// parseLiteral parses a value in JSON-like syntax.
+func parseLiteral(s string) (*literal, error) {
+	p := &literalParser{input: []rune(s)}
+	lit, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if !p.eof() {
+		return nil, p.errorf("unexpected '%c'", p.peek())
+	}
+	return lit, nil
+}
+
+type literalParser struct {
+	input []rune
+	pos   int
+}
+
+func (p *literalParser) parseValue() (*literal, error) {
+	p.skipSpaces()
+	if p.eof() {
+		return nil, p.errorf("unexpected end of input")
+	}
+
+	switch p.peek() {
+	case '[':
+		return p.parseList()
+	case '{':
+		return p.parseObject()
+	case '"':
+		text, err := p.parseQuoted()
+		if err != nil {
+			return nil, err
+		}
+		return &literal{kind: scalarLiteral, text: text}, nil
+	default:
+		text := p.parseBare()
+		if text == "" {
+			return nil, p.errorf("unexpected '%c'", p.peek())
+		}
+		return &literal{kind: scalarLiteral, text: text}, nil
+	}
+}
+
+func (p *literalParser) parseList() (*literal, error) {
+	p.pos++ // skip '['
+	lit := &literal{kind: listLiteral, items: []*literal{}}
+	for {
+		p.skipSpaces()
+		if p.consume(']') {
+			return lit, nil
+		}
+
+		item, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		lit.items = append(lit.items, item)
+
+		if err := p.parseSeparator(']'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *literalParser) parseObject() (*literal, error) {
+	p.pos++ // skip '{'
+	lit := &literal{kind: objectLiteral, items: []*literal{}, keys: []string{}}
+	for {
+		p.skipSpaces()
+		if p.consume('}') {
+			return lit, nil
+		}
+
+		var key string
+		if !p.eof() && p.peek() == '"' {
+			k, err := p.parseQuoted()
+			if err != nil {
+				return nil, err
+			}
+			key = k
+		} else {
+			key = p.parseBare()
+		}
+		if key == "" {
+			return nil, p.errorf("missing key")
+		}
+
+		p.skipSpaces()
+		if !p.consume(':') {
+			return nil, p.errorf("missing ':' after key %s", key)
+		}
+
+		item, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		lit.keys = append(lit.keys, key)
+		lit.items = append(lit.items, item)
+
+		if err := p.parseSeparator('}'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseSeparator consumes a comma between items, the closing bracket is left
+// to the caller.
+func (p *literalParser) parseSeparator(closing rune) error {
+	p.skipSpaces()
+	if p.consume(',') {
+		return nil
+	}
+	if !p.eof() && p.peek() == closing {
+		return nil
+	}
+	return p.errorf("missing ',' or '%c'", closing)
+}
+
+func (p *literalParser) parseQuoted() (string, error) {
+	start := p.pos
+	p.pos++ // skip opening quote
+	for !p.eof() {
+		switch p.input[p.pos] {
+		case '\\':
+			p.pos += 2
+		case '"':
+			p.pos++
+			text, err := strconv.Unquote(string(p.input[start:p.pos]))
+			if err != nil {
+				return "", errors.Errorf("invalid string %s", string(p.input[start:p.pos]))
+			}
+			return text, nil
+		default:
+			p.pos++
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *literalParser) parseBare() string {
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune("[]{},:\"", p.peek()) {
+		p.pos++
+	}
+	return strings.TrimSpace(string(p.input[start:p.pos]))
+}
+
+func (p *literalParser) skipSpaces() {
+	for !p.eof() && unicode.IsSpace(p.peek()) {
+		p.pos++
+	}
+}
+
+func (p *literalParser) consume(r rune) bool {
+	if !p.eof() && p.peek() == r {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *literalParser) peek() rune {
+	return p.input[p.pos]
+}
+
+func (p *literalParser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *literalParser) errorf(format string, args ...any) error {
+	return errors.Errorf("%s at position %d", fmt.Sprintf(format, args...), p.pos)
+}
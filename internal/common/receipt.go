@@ -0,0 +1,31 @@
+package common
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Receipt is a wrapper around geth Receipt, with fields not yet supported by geth.
+type Receipt struct {
+	*types.Receipt
+	EffectiveGasPrice BigInt
+}
+
+// Succeeded returns true if transaction is executed successfully.
+func (r *Receipt) Succeeded() bool {
+	return r.Status == types.ReceiptStatusSuccessful
+}
+
+// Fee returns total fee paid by this transaction, in wei.
+func (r *Receipt) Fee() BigInt {
+	if r.EffectiveGasPrice == nil {
+		return nil
+	}
+	return new(big.Int).Mul(r.EffectiveGasPrice, new(big.Int).SetUint64(r.GasUsed))
+}
+
+// HasContractAddress returns true if a contract is created by this transaction.
+func (r *Receipt) HasContractAddress() bool {
+	return r.ContractAddress != (Address{})
+}
@@ -0,0 +1,58 @@
+package common
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// CallFrame is a call made during execution of a transaction, as reported by
+// the callTracer of debug_traceTransaction.
+type CallFrame struct {
+	Type         string         `json:"type"`
+	From         Address        `json:"from"`
+	To           *Address       `json:"to,omitempty"`
+	Value        *hexutil.Big   `json:"value,omitempty"`
+	Gas          hexutil.Uint64 `json:"gas"`
+	GasUsed      hexutil.Uint64 `json:"gasUsed"`
+	Input        hexutil.Bytes  `json:"input"`
+	Output       hexutil.Bytes  `json:"output,omitempty"`
+	Error        string         `json:"error,omitempty"`
+	RevertReason string         `json:"revertReason,omitempty"`
+	Calls        []*CallFrame   `json:"calls,omitempty"`
+}
+
+// GetValue returns ethers sent along with this call, which is zero for
+// calls that cannot carry value such as STATICCALL.
+func (f *CallFrame) GetValue() BigInt {
+	if f.Value == nil {
+		return new(big.Int)
+	}
+	return f.Value.ToInt()
+}
+
+// Failed returns true if this call is reverted or runs out of gas.
+func (f *CallFrame) Failed() bool {
+	return f.Error != ""
+}
+
+// IsCreate returns true if this call deploys a contract.
+func (f *CallFrame) IsCreate() bool {
+	return f.Type == "CREATE" || f.Type == "CREATE2"
+}
+
+// AccountState is the state of an account reported by prestateTracer, where
+// fields not touched by transaction are omitted.
+type AccountState struct {
+	Balance *hexutil.Big  `json:"balance,omitempty"`
+	Nonce   uint64        `json:"nonce,omitempty"`
+	Code    hexutil.Bytes `json:"code,omitempty"`
+	Storage map[Hash]Hash `json:"storage,omitempty"`
+}
+
+// StateDiff is the states of accounts changed by a transaction, as reported
+// by prestateTracer in diff mode.
+type StateDiff struct {
+	Pre  map[Address]*AccountState `json:"pre"`
+	Post map[Address]*AccountState `json:"post"`
+}
@@ -1,10 +1,11 @@
 package common
 
 import (
-	"crypto/ecdsa"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
 )
 
 // Transaction represents an Ethereum transaction.
@@ -26,12 +27,107 @@ type Transaction interface {
 
 // TxnRequest represents a transaction to be submitted for execution
 type TxnRequest struct {
-	PrivateKey *ecdsa.PrivateKey
-	To         *Address
-	Value      BigInt
-	Data       []byte
-	GasLimit   uint64
-	GasPrice   BigInt
+	From      Address
+	Nonce     *uint64 // use pending nonce of sender if nil
+	To        *Address
+	Value     BigInt
+	Data      []byte
+	GasLimit  uint64
+	GasPrice  BigInt // only used by legacy transaction
+	GasFeeCap BigInt // max fee per gas, only used by dynamic fee transaction
+	GasTipCap BigInt // max priority fee per gas, only used by dynamic fee transaction
+}
+
+// IsDynamicFee returns true if this request should be sent as an EIP-1559 transaction.
+func (r *TxnRequest) IsDynamicFee() bool {
+	return r.GasFeeCap != nil && r.GasTipCap != nil
+}
+
+// MaxFee returns the maximum fee this request may pay, i.e. gas limit times
+// fee cap (or gas price for legacy transaction).
+func (r *TxnRequest) MaxFee() BigInt {
+	price := r.GasPrice
+	if r.IsDynamicFee() {
+		price = r.GasFeeCap
+	}
+	if price == nil {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Mul(price, new(big.Int).SetUint64(r.GasLimit))
+}
+
+// SetFee populates fee fields of this request from the given fee data.
+func (r *TxnRequest) SetFee(fee *FeeData) {
+	if fee.IsDynamic() {
+		r.GasFeeCap = fee.GasFeeCap
+		r.GasTipCap = fee.GasTipCap
+	} else {
+		r.GasPrice = fee.GasPrice
+	}
+}
+
+// CallOpts overrides defaults of a contract call request. Nil or zero fields
+// keep the estimated values.
+type CallOpts struct {
+	Value     BigInt // ethers sent along with the call, only for payable methods
+	GasLimit  uint64
+	GasFeeCap BigInt // max fee per gas, used as gas price for legacy transaction
+	GasTipCap BigInt // max priority fee per gas, ignored by legacy transaction
+}
+
+// Override applies the given options to this request. An error is returned
+// if the resulting priority fee exceeds the max fee, which nodes reject.
+func (r *TxnRequest) Override(opts *CallOpts) error {
+	if opts == nil {
+		return nil
+	}
+
+	if opts.Value != nil {
+		r.Value = opts.Value
+	}
+	if opts.GasLimit != 0 {
+		r.GasLimit = opts.GasLimit
+	}
+	if opts.GasFeeCap != nil {
+		if r.IsDynamicFee() {
+			r.GasFeeCap = opts.GasFeeCap
+		} else {
+			r.GasPrice = opts.GasFeeCap
+		}
+	}
+	if opts.GasTipCap != nil && r.IsDynamicFee() {
+		r.GasTipCap = opts.GasTipCap
+	}
+
+	if r.IsDynamicFee() && r.GasTipCap.Cmp(r.GasFeeCap) > 0 {
+		return errors.Errorf("Max priority fee (%s wei) cannot be higher than max fee (%s wei)", r.GasTipCap, r.GasFeeCap)
+	}
+	return nil
+}
+
+// FeeData represents the suggested fee of a transaction.
+//
+// On a chain supporting London hardfork (EIP-1559), BaseFee, GasTipCap and
+// GasFeeCap are populated. Otherwise only GasPrice is available.
+type FeeData struct {
+	BaseFee   BigInt
+	GasTipCap BigInt
+	GasFeeCap BigInt
+	GasPrice  BigInt
+}
+
+// IsDynamic returns true if fees are priced by EIP-1559.
+func (f *FeeData) IsDynamic() bool {
+	return f.BaseFee != nil
+}
+
+// MaxCost returns the maximum fee may be paid for given gas limit.
+func (f *FeeData) MaxCost(gasLimit uint64) BigInt {
+	price := f.GasPrice
+	if f.IsDynamic() {
+		price = f.GasFeeCap
+	}
+	return new(big.Int).Mul(price, new(big.Int).SetUint64(gasLimit))
 }
 
 // WrappedTransaction is a wrapper around geth Transaction for convenience
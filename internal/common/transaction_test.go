@@ -0,0 +1,77 @@
+package common
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxnRequest_Override(t *testing.T) {
+	// prepare
+	req := &TxnRequest{
+		GasLimit:  21000,
+		GasFeeCap: big.NewInt(100),
+		GasTipCap: big.NewInt(2),
+	}
+
+	// process
+	err := req.Override(&CallOpts{
+		Value:     big.NewInt(1),
+		GasTipCap: big.NewInt(5),
+	})
+
+	// verify
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1), req.Value)
+	assert.Equal(t, uint64(21000), req.GasLimit, "gas limit should be kept if not overridden")
+	assert.Equal(t, big.NewInt(100), req.GasFeeCap, "fee cap should be kept if not overridden")
+	assert.Equal(t, big.NewInt(5), req.GasTipCap)
+}
+
+func TestTxnRequest_OverrideLegacy(t *testing.T) {
+	// prepare
+	req := &TxnRequest{
+		GasLimit: 21000,
+		GasPrice: big.NewInt(100),
+	}
+
+	// process
+	err := req.Override(&CallOpts{
+		GasLimit:  50000,
+		GasFeeCap: big.NewInt(200),
+		GasTipCap: big.NewInt(5),
+	})
+
+	// verify
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(50000), req.GasLimit)
+	assert.Equal(t, big.NewInt(200), req.GasPrice, "fee cap should be used as gas price")
+	assert.Nil(t, req.GasTipCap, "tip cap should be ignored by legacy transaction")
+	assert.False(t, req.IsDynamicFee())
+}
+
+func TestTxnRequest_OverrideInvalidTip(t *testing.T) {
+	tests := []struct {
+		name string
+		opts *CallOpts
+	}{
+		{"fee cap below suggested tip", &CallOpts{GasFeeCap: big.NewInt(1)}},
+		{"tip above suggested fee cap", &CallOpts{GasTipCap: big.NewInt(101)}},
+	}
+
+	for _, test := range tests {
+		// prepare
+		req := &TxnRequest{
+			GasLimit:  21000,
+			GasFeeCap: big.NewInt(100),
+			GasTipCap: big.NewInt(2),
+		}
+
+		// process
+		err := req.Override(test.opts)
+
+		// verify
+		assert.Error(t, err, test.name)
+	}
+}
@@ -12,15 +12,27 @@ import (
 )
 
 var (
-	DefaultProvider   = "alchemy"
-	DefaultNetwork    = "mainnet"
-	DefaultConfigFile = os.Getenv("HOME") + "/.ramen.json"
+	DefaultProvider    = "alchemy"
+	DefaultNetwork     = "mainnet"
+	DefaultConfigFile  = os.Getenv("HOME") + "/.ramen.json"
+	DefaultKeystoreDir = os.Getenv("HOME") + "/.ramen/keystore"
+	DefaultABIDir      = os.Getenv("HOME") + "/.ramen/abi"
+	DefaultSignatures  = os.Getenv("HOME") + "/.ramen/signatures.txt"
 )
 
 type configJSON struct {
-	Provider        *string `json:"provider,omitempty"`
-	ApiKey          *string `json:"apikey,omitempty"`
-	EtherscanApiKey *string `json:"etherscanApikey,omitempty"`
+	Provider        *string             `json:"provider,omitempty"`
+	ApiKey          *string             `json:"apikey,omitempty"`
+	EtherscanApiKey *string             `json:"etherscanApikey,omitempty"`
+	Tokens          map[string][]string `json:"tokens,omitempty"`
+	KeystoreDir     *string             `json:"keystore,omitempty"`
+	HDPath          *string             `json:"hdPath,omitempty"`
+	ExternalSigner  *string             `json:"externalSigner,omitempty"`
+	ABIDir          *string             `json:"abiDir,omitempty"`
+	ProjectDir      *string             `json:"project,omitempty"`
+	SignatureFile   *string             `json:"signatures,omitempty"`
+	SkipSimulation  *bool               `json:"skipSimulation,omitempty"`
+	IPFSGateway     *string             `json:"ipfsGateway,omitempty"`
 }
 
 type Config struct {
@@ -41,10 +53,47 @@ type Config struct {
 
 	// EtherscanApiKey is the key for Etherscan API
 	EtherscanApiKey string
+
+	// Tokens is a list of ERC-20 token addresses to query balances of, keyed
+	// by chain id. Only used when provider cannot list tokens of an account.
+	Tokens map[string][]string
+
+	// KeystoreDir is the directory of encrypted key files
+	KeystoreDir string
+
+	// HDPath is the derivation path of accounts derived from mnemonic, where
+	// i is replaced by index of account, e.g. m/44'/60'/0'/0/i
+	HDPath string
+
+	// ExternalSigner is the endpoint of an external signer speaking Clef's
+	// JSON-RPC API, e.g. http://localhost:8550
+	ExternalSigner string
+
+	// ABIDir is the directory of ABI registry, where imported ABIs are saved
+	// by chain id and address
+	ABIDir string
+
+	// ProjectDir is the directory of a Hardhat or Foundry project, whose
+	// artifacts are used for contracts on devnet
+	ProjectDir string
+
+	// SignatureFile is a file of function and event signatures, which
+	// extends the builtin ones to decode calldata and logs without ABI
+	SignatureFile string
+
+	// SkipSimulation disables the dry run of transactions before they are
+	// reviewed and sent
+	SkipSimulation bool
+
+	// IPFSGateway is the HTTP gateway to download NFT metadata stored on
+	// IPFS, e.g. https://ipfs.io/ipfs/
+	IPFSGateway string
 }
 
 func NewConfig() *Config {
-	return &Config{}
+	return &Config{
+		SignatureFile: DefaultSignatures,
+	}
 }
 
 // ParseConfig extract config file location from Config struct, read and parse
@@ -79,6 +128,33 @@ func ParseConfig(config *Config) error {
 	if configJson.EtherscanApiKey != nil && config.EtherscanApiKey == "" {
 		config.EtherscanApiKey = *configJson.EtherscanApiKey
 	}
+	if configJson.Tokens != nil {
+		config.Tokens = configJson.Tokens
+	}
+	if configJson.KeystoreDir != nil && config.KeystoreDir == DefaultKeystoreDir {
+		config.KeystoreDir = *configJson.KeystoreDir
+	}
+	if configJson.HDPath != nil {
+		config.HDPath = *configJson.HDPath
+	}
+	if configJson.ExternalSigner != nil && config.ExternalSigner == "" {
+		config.ExternalSigner = *configJson.ExternalSigner
+	}
+	if configJson.ABIDir != nil && config.ABIDir == DefaultABIDir {
+		config.ABIDir = *configJson.ABIDir
+	}
+	if configJson.ProjectDir != nil && config.ProjectDir == "" {
+		config.ProjectDir = *configJson.ProjectDir
+	}
+	if configJson.SignatureFile != nil {
+		config.SignatureFile = *configJson.SignatureFile
+	}
+	if configJson.SkipSimulation != nil && !config.SkipSimulation {
+		config.SkipSimulation = *configJson.SkipSimulation
+	}
+	if configJson.IPFSGateway != nil {
+		config.IPFSGateway = *configJson.IPFSGateway
+	}
 
 	return nil
 }
@@ -28,17 +28,36 @@ type (
 		Decimal string `json:"decimal"`
 	}
 
+	AlchemyERC1155Metadata struct {
+		TokenId string `json:"tokenId"`
+		Value   string `json:"value"`
+	}
+
 	AlchemyTransfer struct {
-		Category    string              `json:"category"`
-		BlockNum    string              `json:"blockNum"`
-		From        string              `json:"from"`
-		To          string              `json:"to"`
-		Value       float64             `json:"value"`
-		TokenId     string              `json:"tokenId"`
-		Asset       string              `json:"asset"`
-		UniqueId    string              `json:"uniqueId"`
-		Hash        string              `json:"hash"`
-		RawContract *AlchemyRawContract `json:"rawContract"`
+		Category        string                    `json:"category"`
+		BlockNum        string                    `json:"blockNum"`
+		From            string                    `json:"from"`
+		To              string                    `json:"to"`
+		Value           float64                   `json:"value"`
+		TokenId         string                    `json:"tokenId"`
+		ERC721TokenId   string                    `json:"erc721TokenId"`
+		ERC1155Metadata []*AlchemyERC1155Metadata `json:"erc1155Metadata"`
+		Asset           string                    `json:"asset"`
+		UniqueId        string                    `json:"uniqueId"`
+		Hash            string                    `json:"hash"`
+		RawContract     *AlchemyRawContract       `json:"rawContract"`
+	}
+
+	GetTokenBalancesResult struct {
+		Address       string                 `json:"address"`
+		TokenBalances []*AlchemyTokenBalance `json:"tokenBalances"`
+		PageKey       string                 `json:"pageKey"`
+	}
+
+	AlchemyTokenBalance struct {
+		ContractAddress string  `json:"contractAddress"`
+		TokenBalance    string  `json:"tokenBalance"`
+		Error           *string `json:"error"`
 	}
 )
 
@@ -53,3 +72,21 @@ func (p *Provider) GetAssetTransfers(params GetAssetTransfersParams) (*GetAssetT
 	}
 	return result, nil
 }
+
+// GetTokenBalances returns balances of all ERC-20 tokens held by given address.
+func (p *Provider) GetTokenBalances(address string, pageKey string) (*GetTokenBalancesResult, error) {
+	ctx, cancel := p.createContext()
+	defer cancel()
+
+	args := []any{address, "erc20"}
+	if pageKey != "" {
+		args = append(args, map[string]string{"pageKey": pageKey})
+	}
+
+	var result *GetTokenBalancesResult
+	err := p.rpcClient.CallContext(ctx, &result, "alchemy_getTokenBalances", args...)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return result, nil
+}
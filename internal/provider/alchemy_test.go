@@ -30,3 +30,15 @@ func TestGetAssetTransfers_NoError(t *testing.T) {
 	assert.NotEmpty(t, transfer.From, "sender should not be nil")
 	assert.NotEmpty(t, transfer.To, "receiver should not be nil")
 }
+
+func TestGetTokenBalances_NoError(t *testing.T) {
+	// prepare
+	provider := NewProvider(testAlchemyEndpoint, ProviderAlchemy)
+
+	// process
+	result, err := provider.GetTokenBalances("0xde0b295669a9fd93d5f28d9ec85e40f4cb697bae", "")
+
+	// verify
+	assert.NoError(t, err)
+	assert.NotEmpty(t, result.TokenBalances, "should hold some tokens")
+}
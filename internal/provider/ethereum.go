@@ -4,15 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"math/big"
+	"strings"
 	"time"
 
 	"github.com/dyng/ramen/internal/common"
 	"github.com/dyng/ramen/internal/common/conv"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
+	gcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
@@ -21,6 +22,13 @@ import (
 
 var (
 	ErrProviderNotSupport = errors.New("provider does not support this vendor-specific api")
+	ErrMethodNotFound     = errors.New("provider does not support this method")
+	// ErrPendingNotSupported is returned if provider supports a method on
+	// mined blocks only, it is also an ErrMethodNotFound
+	ErrPendingNotSupported = errors.WithMessage(ErrMethodNotFound, "pending block is not supported")
+
+	// PendingBlock is the block number representing the pending state
+	PendingBlock = big.NewInt(-1)
 )
 
 const (
@@ -53,10 +61,37 @@ func (tx *rpcTransaction) UnmarshalJSON(msg []byte) error {
 }
 
 func (tx *rpcTransaction) ToTransaction() common.Transaction {
+	// pending transaction has no block number
+	if tx.BlockNumber == nil {
+		return common.WrapTransaction(tx.tx, nil, tx.From, tx.Timestamp)
+	}
 	blockNumer, _ := conv.HexToInt(*tx.BlockNumber)
 	return common.WrapTransaction(tx.tx, big.NewInt(blockNumer), tx.From, tx.Timestamp)
 }
 
+type rpcReceipt struct {
+	receipt *types.Receipt
+	receiptExtraInfo
+}
+
+type receiptExtraInfo struct {
+	EffectiveGasPrice *hexutil.Big `json:"effectiveGasPrice,omitempty"`
+}
+
+func (r *rpcReceipt) UnmarshalJSON(msg []byte) error {
+	if err := json.Unmarshal(msg, &r.receipt); err != nil {
+		return errors.WithStack(err)
+	}
+	return json.Unmarshal(msg, &r.receiptExtraInfo)
+}
+
+func (r *rpcReceipt) ToReceipt() *common.Receipt {
+	return &common.Receipt{
+		Receipt:           r.receipt,
+		EffectiveGasPrice: (*big.Int)(r.EffectiveGasPrice),
+	}
+}
+
 type rpcBlock struct {
 	*types.Header
 	rpcBlockBody
@@ -134,6 +169,9 @@ func (s forkchainSigner) SignatureValues(tx *types.Transaction, sig []byte) (R *
 	return s.getSigner(tx).SignatureValues(tx, sig)
 }
 
+// SignFunc signs a transaction for given chain.
+type SignFunc func(txn *types.Transaction, chainId common.BigInt) (*types.Transaction, error)
+
 type Provider struct {
 	url          string
 	providerType string
@@ -190,6 +228,49 @@ func (p *Provider) GetGasPrice() (common.BigInt, error) {
 	return gasPrice, errors.WithStack(err)
 }
 
+func (p *Provider) GetGasTipCap() (common.BigInt, error) {
+	ctx, cancel := p.createContext()
+	defer cancel()
+	gasTipCap, err := p.client.SuggestGasTipCap(ctx)
+	return gasTipCap, errors.WithStack(err)
+}
+
+// GetFeeData returns suggested fees for next transaction.
+//
+// If the chain supports London hardfork, fees are derived from the base fee
+// of latest block plus a suggested priority fee (eth_maxPriorityFeePerGas).
+// Otherwise it falls back to legacy gas price.
+func (p *Provider) GetFeeData() (*common.FeeData, error) {
+	header, err := p.GetHeaderByNumber(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// pre-London chain, use legacy gas price
+	if header.BaseFee == nil {
+		gasPrice, err := p.GetGasPrice()
+		if err != nil {
+			return nil, err
+		}
+		return &common.FeeData{GasPrice: gasPrice}, nil
+	}
+
+	gasTipCap, err := p.GetGasTipCap()
+	if err != nil {
+		return nil, err
+	}
+
+	// leave room for base fee to grow in next few blocks
+	gasFeeCap := new(big.Int).Add(new(big.Int).Mul(header.BaseFee, big.NewInt(2)), gasTipCap)
+
+	return &common.FeeData{
+		BaseFee:   header.BaseFee,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		GasPrice:  new(big.Int).Add(header.BaseFee, gasTipCap),
+	}, nil
+}
+
 func (p *Provider) GetSigner() (types.Signer, error) {
 	_, err := p.GetNetwork()
 	if err != nil {
@@ -205,6 +286,18 @@ func (p *Provider) GetCode(addr common.Address) ([]byte, error) {
 	return code, errors.WithStack(err)
 }
 
+// GetStorageAt returns the value of a storage slot of contract at given
+// block, or latest block if blockNumber is nil.
+func (p *Provider) GetStorageAt(addr common.Address, slot common.Hash, blockNumber common.BigInt) (common.Hash, error) {
+	ctx, cancel := p.createContext()
+	defer cancel()
+	value, err := p.client.StorageAt(ctx, addr, slot, blockNumber)
+	if err != nil {
+		return common.Hash{}, errors.WithStack(err)
+	}
+	return gcommon.BytesToHash(value), nil
+}
+
 func (p *Provider) GetBalance(addr common.Address) (common.BigInt, error) {
 	ctx, cancel := p.createContext()
 	defer cancel()
@@ -212,6 +305,29 @@ func (p *Provider) GetBalance(addr common.Address) (common.BigInt, error) {
 	return balance, errors.WithStack(err)
 }
 
+// GetBalanceAt returns balance of address at given block, nil means the
+// latest block and PendingBlock means the pending state.
+func (p *Provider) GetBalanceAt(addr common.Address, blockNumber common.BigInt) (common.BigInt, error) {
+	ctx, cancel := p.createContext()
+	defer cancel()
+	balance, err := p.client.BalanceAt(ctx, addr, blockNumber)
+	return balance, errors.WithStack(err)
+}
+
+func (p *Provider) GetNonce(addr common.Address) (uint64, error) {
+	ctx, cancel := p.createContext()
+	defer cancel()
+	nonce, err := p.client.NonceAt(ctx, addr, nil)
+	return nonce, errors.WithStack(err)
+}
+
+func (p *Provider) GetPendingNonce(addr common.Address) (uint64, error) {
+	ctx, cancel := p.createContext()
+	defer cancel()
+	nonce, err := p.client.PendingNonceAt(ctx, addr)
+	return nonce, errors.WithStack(err)
+}
+
 func (p *Provider) GetBlockHeight() (uint64, error) {
 	ctx, cancel := p.createContext()
 	defer cancel()
@@ -219,6 +335,13 @@ func (p *Provider) GetBlockHeight() (uint64, error) {
 	return height, errors.WithStack(err)
 }
 
+func (p *Provider) GetHeaderByNumber(number common.BigInt) (*common.Header, error) {
+	ctx, cancel := p.createContext()
+	defer cancel()
+	header, err := p.client.HeaderByNumber(ctx, number)
+	return header, errors.WithStack(err)
+}
+
 func (p *Provider) GetBlockByHash(hash common.Hash) (*common.Block, error) {
 	ctx, cancel := p.createContext()
 	defer cancel()
@@ -262,6 +385,33 @@ func (p *Provider) BatchBlockByNumber(numberList []common.BigInt) ([]*common.Blo
 	return result, nil
 }
 
+// GetTransactionByHash returns transaction of given hash, or nil if not found.
+func (p *Provider) GetTransactionByHash(hash common.Hash) (common.Transaction, error) {
+	ctx, cancel := p.createContext()
+	defer cancel()
+
+	var rpcRes *rpcTransaction
+	err := p.rpcClient.CallContext(ctx, &rpcRes, "eth_getTransactionByHash", hash)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if rpcRes == nil {
+		return nil, nil
+	}
+
+	// eth_getTransactionByHash does not return timestamp, take it from block
+	if rpcRes.BlockHash != nil {
+		header, err := p.client.HeaderByHash(ctx, *rpcRes.BlockHash)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		rpcRes.Timestamp = header.Time
+	}
+
+	return rpcRes.ToTransaction(), nil
+}
+
 func (p *Provider) BatchTransactionByHash(hashList []common.Hash) (common.Transactions, error) {
 	size := len(hashList)
 	rpcRes := make([]rpcTransaction, size)
@@ -291,12 +441,67 @@ func (p *Provider) BatchTransactionByHash(hashList []common.Hash) (common.Transa
 	return result, nil
 }
 
-func (p *Provider) EstimateGas(address common.Address, from common.Address, input []byte) (uint64, error) {
+// GetTransactionReceipt returns receipt of given transaction, or nil if
+// transaction is not mined yet.
+func (p *Provider) GetTransactionReceipt(hash common.Hash) (*common.Receipt, error) {
+	ctx, cancel := p.createContext()
+	defer cancel()
+
+	var rpcRes *rpcReceipt
+	err := p.rpcClient.CallContext(ctx, &rpcRes, "eth_getTransactionReceipt", hash)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if rpcRes == nil {
+		return nil, nil
+	}
+	return rpcRes.ToReceipt(), nil
+}
+
+// BatchTransactionReceipt returns receipts of given transactions. The
+// receipt will be nil if corresponding transaction is not mined yet.
+func (p *Provider) BatchTransactionReceipt(hashList []common.Hash) ([]*common.Receipt, error) {
+	size := len(hashList)
+	rpcRes := make([]*rpcReceipt, size)
+	reqs := make([]rpc.BatchElem, size)
+	for i := range reqs {
+		reqs[i] = rpc.BatchElem{
+			Method: "eth_getTransactionReceipt",
+			Args:   []any{hashList[i]},
+			Result: &rpcRes[i],
+		}
+	}
+
+	ctx, cancel := p.createContext()
+	defer cancel()
+
+	err := p.rpcClient.BatchCallContext(ctx, reqs)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := make([]*common.Receipt, size)
+	for i := range result {
+		if reqs[i].Error != nil {
+			log.Warn("Failed to fetch receipt", "hash", hashList[i], "error", reqs[i].Error)
+			continue
+		}
+		if rpcRes[i] != nil {
+			result[i] = rpcRes[i].ToReceipt()
+		}
+	}
+
+	return result, nil
+}
+
+func (p *Provider) EstimateGas(address common.Address, from common.Address, value common.BigInt, input []byte) (uint64, error) {
 	// build call message
 	msg := ethereum.CallMsg{
-		From: from,
-		To:   &address,
-		Data: input,
+		From:  from,
+		To:    &address,
+		Value: value,
+		Data:  input,
 	}
 
 	ctx, cancel := p.createContext()
@@ -310,6 +515,96 @@ func (p *Provider) EstimateGas(address common.Address, from common.Address, inpu
 	return gasLimit, nil
 }
 
+// EstimateTransactionGas estimates gas used by a transaction request on the
+// state after given block, the latest block if nil, or the pending state if
+// PendingBlock. Gas limit of request is ignored.
+func (p *Provider) EstimateTransactionGas(txnReq *common.TxnRequest, blockNumber common.BigInt) (uint64, error) {
+	ctx, cancel := p.createContext()
+	defer cancel()
+
+	arg := toCallArg(txnReq)
+	delete(arg, "gas")
+
+	var gasLimit hexutil.Uint64
+	err := p.rpcClient.CallContext(ctx, &gasLimit, "eth_estimateGas", arg, toBlockNumArg(blockNumber))
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	return uint64(gasLimit), nil
+}
+
+// CallTransaction executes a transaction request by eth_call on the state
+// after given block, the latest block if nil, or the pending state if
+// PendingBlock, and returns its output.
+func (p *Provider) CallTransaction(txnReq *common.TxnRequest, blockNumber common.BigInt) ([]byte, error) {
+	ctx, cancel := p.createContext()
+	defer cancel()
+
+	data, err := p.client.CallContract(ctx, toCallMsg(txnReq), blockNumber)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return data, nil
+}
+
+// TraceCallStateDiff executes a transaction request by debug_traceCall with
+// prestateTracer in diff mode on the state after given block, the same as
+// CallTransaction, and returns the states of accounts before and after it.
+// ErrMethodNotFound is returned if provider does not support it, or
+// ErrPendingNotSupported if it does not support tracing on the pending state
+// like Geth.
+func (p *Provider) TraceCallStateDiff(txnReq *common.TxnRequest, blockNumber common.BigInt) (*common.StateDiff, error) {
+	ctx, cancel := p.createContext()
+	defer cancel()
+
+	config := map[string]any{
+		"tracer":       "prestateTracer",
+		"tracerConfig": map[string]any{"diffMode": true},
+	}
+
+	var diff *common.StateDiff
+	block := toBlockNumArg(blockNumber)
+	err := p.rpcClient.CallContext(ctx, &diff, "debug_traceCall", toCallArg(txnReq), block, config)
+	if err != nil {
+		if block == "pending" && isPendingNotSupported(err) {
+			return nil, ErrPendingNotSupported
+		}
+		if isMethodNotFound(err) {
+			return nil, ErrMethodNotFound
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	// tracers without diff mode ignore the config and give another format
+	if diff == nil || len(diff.Pre) == 0 {
+		return nil, ErrMethodNotFound
+	}
+	return diff, nil
+}
+
+// TraceTransaction replays a mined transaction with callTracer, and returns
+// the tree of calls made by it. ErrMethodNotFound is returned if provider
+// does not support debug_traceTransaction.
+func (p *Provider) TraceTransaction(hash common.Hash) (*common.CallFrame, error) {
+	ctx, cancel := p.createContext()
+	defer cancel()
+
+	var frame *common.CallFrame
+	err := p.rpcClient.CallContext(ctx, &frame, "debug_traceTransaction", hash, map[string]any{"tracer": "callTracer"})
+	if err != nil {
+		if isMethodNotFound(err) {
+			return nil, ErrMethodNotFound
+		}
+		return nil, errors.WithStack(err)
+	}
+	if frame == nil {
+		return nil, errors.Errorf("Transaction %s is not found", hash.Hex())
+	}
+	return frame, nil
+}
+
 func (p *Provider) CallContract(address common.Address, abi *abi.ABI, method string, args ...any) ([]any, error) {
 	// encode calldata
 	input, err := abi.Pack(method, args...)
@@ -339,42 +634,131 @@ func (p *Provider) CallContract(address common.Address, abi *abi.ABI, method str
 	return vals, nil
 }
 
-func (p *Provider) SendTransaction(txnReq *common.TxnRequest) (common.Hash, error) {
+// ContractCall is a read-only call of contract method.
+type ContractCall struct {
+	Address common.Address
+	ABI     *abi.ABI
+	Method  string
+	Args    []any
+}
+
+// BatchCallContract calls contract methods in one batch. The returned values
+// of a failed call are nil, and its error is set in the returned error list.
+func (p *Provider) BatchCallContract(calls []ContractCall) ([][]any, []error, error) {
+	size := len(calls)
+	rpcRes := make([]hexutil.Bytes, size)
+	reqs := make([]rpc.BatchElem, size)
+	for i, call := range calls {
+		input, err := call.ABI.Pack(call.Method, call.Args...)
+		if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+		arg := map[string]any{
+			"to":   call.Address,
+			"data": hexutil.Bytes(input),
+		}
+		reqs[i] = rpc.BatchElem{
+			Method: "eth_call",
+			Args:   []any{arg, "latest"},
+			Result: &rpcRes[i],
+		}
+	}
+
 	ctx, cancel := p.createContext()
 	defer cancel()
 
-	key := txnReq.PrivateKey
-	from := crypto.PubkeyToAddress(key.PublicKey)
-
-	// fetch the next nonce
-	nonce, err := p.client.PendingNonceAt(ctx, from)
+	err := p.rpcClient.BatchCallContext(ctx, reqs)
 	if err != nil {
-		return common.Hash{}, errors.WithStack(err)
+		return nil, nil, errors.WithStack(err)
+	}
+
+	result := make([][]any, size)
+	errs := make([]error, size)
+	for i, call := range calls {
+		if reqs[i].Error != nil {
+			errs[i] = errors.WithStack(reqs[i].Error)
+			continue
+		}
+		vals, err := call.ABI.Unpack(call.Method, rpcRes[i])
+		if err != nil {
+			errs[i] = errors.WithStack(err)
+			continue
+		}
+		result[i] = vals
 	}
 
-	txn := types.NewTx(&types.LegacyTx{
-		Nonce:    nonce,
-		GasPrice: txnReq.GasPrice,
-		Gas:      txnReq.GasLimit,
-		To:       txnReq.To,
-		Value:    txnReq.Value,
-		Data:     txnReq.Data,
-	})
+	return result, errs, nil
+}
+
+// SendTransaction builds a transaction from request, signs it with given
+// function and sends it to the network.
+func (p *Provider) SendTransaction(txnReq *common.TxnRequest, sign SignFunc) (common.Hash, error) {
+	from := txnReq.From
+
+	// fetch the next nonce if not specified
+	var nonce uint64
+	if txnReq.Nonce != nil {
+		nonce = *txnReq.Nonce
+	} else {
+		ctx, cancel := p.createContext()
+		n, err := p.client.PendingNonceAt(ctx, from)
+		cancel()
+		if err != nil {
+			return common.Hash{}, errors.WithStack(err)
+		}
+		nonce = n
+	}
 
-	signer, err := p.GetSigner()
+	chainId, err := p.GetNetwork()
 	if err != nil {
 		return common.Hash{}, err
 	}
 
-	signedTx, err := types.SignTx(txn, signer, key)
+	var txn *types.Transaction
+	if txnReq.IsDynamicFee() {
+		txn = types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainId,
+			Nonce:     nonce,
+			GasTipCap: txnReq.GasTipCap,
+			GasFeeCap: txnReq.GasFeeCap,
+			Gas:       txnReq.GasLimit,
+			To:        txnReq.To,
+			Value:     txnReq.Value,
+			Data:      txnReq.Data,
+		})
+	} else {
+		// legacy transaction for pre-London chains
+		txn = types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: txnReq.GasPrice,
+			Gas:      txnReq.GasLimit,
+			To:       txnReq.To,
+			Value:    txnReq.Value,
+			Data:     txnReq.Data,
+		})
+	}
+
+	signedTx, err := sign(txn, chainId)
 	if err != nil {
-		return common.Hash{}, errors.WithStack(err)
+		return common.Hash{}, err
 	}
 
+	// signing may take long, e.g. waiting for approval of an external signer,
+	// so timeout of broadcasting starts after it
+	ctx, cancel := p.createContext()
+	defer cancel()
 	err = p.client.SendTransaction(ctx, signedTx)
 	return signedTx.Hash(), errors.WithStack(err)
 }
 
+// FilterLogs returns logs matching given filter.
+func (p *Provider) FilterLogs(query ethereum.FilterQuery) ([]types.Log, error) {
+	ctx, cancel := p.createContext()
+	defer cancel()
+	logs, err := p.client.FilterLogs(ctx, query)
+	return logs, errors.WithStack(err)
+}
+
 func (p *Provider) SubscribeNewHead(ch chan<- *common.Header) (ethereum.Subscription, error) {
 	ctx, cancel := p.createContext()
 	defer cancel()
@@ -386,6 +770,65 @@ func (p *Provider) createContext() (context.Context, context.CancelFunc) {
 	return context.WithTimeout(context.Background(), DefaultTimeout)
 }
 
+func toCallMsg(txnReq *common.TxnRequest) ethereum.CallMsg {
+	return ethereum.CallMsg{
+		From:  txnReq.From,
+		To:    txnReq.To,
+		Gas:   txnReq.GasLimit,
+		Value: txnReq.Value,
+		Data:  txnReq.Data,
+	}
+}
+
+// toCallArg converts request to the argument of debug_traceCall. Unlike
+// eth_call made by ethclient, fees are included so that they are deducted
+// from sender's balance.
+func toCallArg(txnReq *common.TxnRequest) map[string]any {
+	arg := map[string]any{
+		"from": txnReq.From,
+		"to":   txnReq.To,
+	}
+	if len(txnReq.Data) > 0 {
+		arg["data"] = hexutil.Bytes(txnReq.Data)
+	}
+	if txnReq.Value != nil {
+		arg["value"] = (*hexutil.Big)(txnReq.Value)
+	}
+	if txnReq.GasLimit != 0 {
+		arg["gas"] = hexutil.Uint64(txnReq.GasLimit)
+	}
+	if txnReq.IsDynamicFee() {
+		arg["maxFeePerGas"] = (*hexutil.Big)(txnReq.GasFeeCap)
+		arg["maxPriorityFeePerGas"] = (*hexutil.Big)(txnReq.GasTipCap)
+	} else if txnReq.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(txnReq.GasPrice)
+	}
+	return arg
+}
+
+// isMethodNotFound returns true if error means that the method is not
+// available, either unknown or disabled by provider.
+func isMethodNotFound(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32601 {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "method not found") ||
+		strings.Contains(msg, "does not exist") ||
+		strings.Contains(msg, "not supported") ||
+		strings.Contains(msg, "unsupported method")
+}
+
+// isPendingNotSupported returns true if error means that the method cannot
+// be used on the pending state, e.g. "tracing on top of pending is not
+// supported" of Geth.
+func isPendingNotSupported(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "pending") &&
+		(strings.Contains(msg, "not supported") || strings.Contains(msg, "unsupported"))
+}
+
 func toBlockNumArg(number *big.Int) string {
 	if number == nil {
 		return "latest"
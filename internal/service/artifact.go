@@ -0,0 +1,562 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dyng/ramen/internal/common"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+)
+
+var (
+	// artifactCheckInterval is the minimum time between two checks of
+	// whether project directory has changed, tests may lower it
+	artifactCheckInterval = 2 * time.Second
+
+	// directories of compiled artifacts and deployments, relative to project directory
+	hardhatArtifactsDir   = "artifacts"
+	foundryOutDir         = "out"
+	hardhatDeploymentsDir = "deployments"
+	foundryBroadcastDir   = "broadcast"
+
+	// linkPlaceholderPattern matches placeholders of libraries in unlinked bytecode
+	linkPlaceholderPattern = regexp.MustCompile(`__\$[0-9a-fA-F]{34}\$__`)
+)
+
+// Artifact is a contract compiled by Hardhat or Foundry.
+type Artifact struct {
+	Name          string
+	SourceName    string // path of source file relative to project directory
+	Path          string // path of artifact file
+	ABI           string
+	StorageLayout json.RawMessage // may be empty if not generated
+
+	deployedCode []byte
+	masks        []codeRange // ranges of immutables and libraries in deployedCode
+}
+
+// deployment is an address where an artifact is deployed to.
+type deployment struct {
+	chainId  string // empty if unknown
+	address  common.Address
+	name     string
+	artifact *Artifact // artifact in deployment file, nil if looked up by name
+}
+
+type codeRange struct {
+	Start  int `json:"start"`
+	Length int `json:"length"`
+}
+
+// ArtifactIndex scans a Hardhat or Foundry project for compiled artifacts
+// and deployments, and rescans it when it changes.
+type ArtifactIndex struct {
+	sync.Mutex
+
+	dir         string
+	artifacts   []*Artifact
+	deployments []*deployment
+	fingerprint string
+	checkedAt   time.Time
+	version     int
+}
+
+// NewArtifactIndex creates an index of project in dir, which is disabled if dir is empty.
+func NewArtifactIndex(dir string) *ArtifactIndex {
+	return &ArtifactIndex{dir: dir}
+}
+
+// Refresh rescans project directory if it has changed, and returns version
+// of the index, which increases every time it is rescanned.
+func (x *ArtifactIndex) Refresh() int {
+	x.Lock()
+	defer x.Unlock()
+
+	if x.dir == "" || time.Since(x.checkedAt) < artifactCheckInterval {
+		return x.version
+	}
+	x.checkedAt = time.Now()
+
+	fingerprint := x.computeFingerprint()
+	if x.version > 0 && fingerprint == x.fingerprint {
+		return x.version
+	}
+
+	log.Debug("Scanning artifacts in project directory", "dir", x.dir)
+	x.artifacts, x.deployments = x.scan()
+	x.fingerprint = fingerprint
+	x.version++
+	log.Info("Scanned artifacts in project directory", "dir", x.dir, "artifacts", len(x.artifacts), "deployments", len(x.deployments))
+
+	return x.version
+}
+
+// Match finds the artifact of contract at address, by deployments on chain
+// at first, then by deployed bytecode.
+func (x *ArtifactIndex) Match(chainId string, address common.Address, code []byte) *Artifact {
+	x.Lock()
+	defer x.Unlock()
+
+	for _, d := range x.deployments {
+		if d.address != address || (d.chainId != "" && d.chainId != chainId) {
+			continue
+		}
+		if d.artifact != nil {
+			return d.artifact
+		}
+		if artifact := x.findByName(d.name); artifact != nil {
+			return artifact
+		}
+	}
+
+	// exact match at first, then ignore metadata, immutables and libraries
+	for _, artifact := range x.artifacts {
+		if len(artifact.deployedCode) > 0 && bytes.Equal(artifact.deployedCode, code) {
+			return artifact
+		}
+	}
+	for _, artifact := range x.artifacts {
+		if len(artifact.deployedCode) > 0 && codeMatches(code, artifact.deployedCode, artifact.masks) {
+			return artifact
+		}
+	}
+	return nil
+}
+
+func (x *ArtifactIndex) findByName(name string) *Artifact {
+	for _, artifact := range x.artifacts {
+		if artifact.Name == name {
+			return artifact
+		}
+	}
+	return nil
+}
+
+// computeFingerprint summarizes file names, sizes and modification times of
+// project, so that any change of them can be detected.
+func (x *ArtifactIndex) computeFingerprint() string {
+	var count, size int64
+	var latest time.Time
+	for _, root := range x.roots() {
+		filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return nil
+			}
+			count++
+			size += info.Size()
+			if info.ModTime().After(latest) {
+				latest = info.ModTime()
+			}
+			return nil
+		})
+	}
+	return fmt.Sprintf("%d:%d:%d", count, size, latest.UnixNano())
+}
+
+func (x *ArtifactIndex) roots() []string {
+	return []string{
+		filepath.Join(x.dir, hardhatArtifactsDir),
+		filepath.Join(x.dir, foundryOutDir),
+		filepath.Join(x.dir, hardhatDeploymentsDir),
+		filepath.Join(x.dir, foundryBroadcastDir),
+	}
+}
+
+func (x *ArtifactIndex) scan() ([]*Artifact, []*deployment) {
+	artifacts := make([]*Artifact, 0)
+	for _, root := range []string{filepath.Join(x.dir, hardhatArtifactsDir), filepath.Join(x.dir, foundryOutDir)} {
+		walkJSON(root, func(path string) {
+			if strings.HasSuffix(path, ".dbg.json") {
+				return
+			}
+			artifact, err := ParseArtifact(path)
+			if err != nil {
+				log.Debug("Skip file which is not an artifact", "path", path, "error", err)
+				return
+			}
+			artifacts = append(artifacts, artifact)
+		})
+	}
+
+	deployments := make([]*deployment, 0)
+	deployments = append(deployments, scanHardhatDeployments(filepath.Join(x.dir, hardhatDeploymentsDir))...)
+	deployments = append(deployments, scanFoundryBroadcasts(filepath.Join(x.dir, foundryBroadcastDir))...)
+
+	sort.SliceStable(artifacts, func(i, j int) bool {
+		return artifacts[i].Path < artifacts[j].Path
+	})
+	return artifacts, deployments
+}
+
+// artifactJSON contains fields of Hardhat artifacts, Foundry artifacts and
+// hardhat-deploy deployments.
+type artifactJSON struct {
+	ContractName     string          `json:"contractName"`
+	SourceName       string          `json:"sourceName"`
+	Address          string          `json:"address"`
+	ABI              json.RawMessage `json:"abi"`
+	DeployedBytecode json.RawMessage `json:"deployedBytecode"`
+	LinkReferences   json.RawMessage `json:"deployedLinkReferences"`
+	StorageLayout    json.RawMessage `json:"storageLayout"`
+	Metadata         json.RawMessage `json:"metadata"`
+	AST              *struct {
+		AbsolutePath string `json:"absolutePath"`
+	} `json:"ast"`
+}
+
+// foundryBytecodeJSON is the bytecode object of Foundry artifacts.
+type foundryBytecodeJSON struct {
+	Object              string          `json:"object"`
+	LinkReferences      json.RawMessage `json:"linkReferences"`
+	ImmutableReferences json.RawMessage `json:"immutableReferences"`
+}
+
+// ParseArtifact parses an artifact file of Hardhat or Foundry, or a
+// deployment file of hardhat-deploy.
+func ParseArtifact(path string) (*Artifact, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var aj artifactJSON
+	if err := json.Unmarshal(data, &aj); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(aj.ABI) == 0 || aj.ABI[0] != '[' || len(aj.DeployedBytecode) == 0 {
+		return nil, errors.New("Either 'abi' or 'deployedBytecode' is missing")
+	}
+
+	artifact := &Artifact{
+		Name:       aj.ContractName,
+		SourceName: aj.SourceName,
+		Path:       path,
+		ABI:        string(aj.ABI),
+		masks:      make([]codeRange, 0),
+	}
+	if artifact.Name == "" {
+		artifact.Name = strings.TrimSuffix(filepath.Base(path), ".json")
+	}
+	if string(aj.StorageLayout) != "null" {
+		artifact.StorageLayout = aj.StorageLayout
+	}
+
+	// deployed bytecode is a hex string in Hardhat, and an object in Foundry
+	var code string
+	if err := json.Unmarshal(aj.DeployedBytecode, &code); err == nil {
+		artifact.masks = append(artifact.masks, parseCodeRanges(aj.LinkReferences)...)
+	} else {
+		var fb foundryBytecodeJSON
+		if err := json.Unmarshal(aj.DeployedBytecode, &fb); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		code = fb.Object
+		artifact.masks = append(artifact.masks, parseCodeRanges(fb.LinkReferences)...)
+		artifact.masks = append(artifact.masks, parseImmutableRanges(fb.ImmutableReferences)...)
+	}
+	if !strings.HasPrefix(code, "0x") {
+		code = "0x" + code
+	}
+	code = linkPlaceholderPattern.ReplaceAllString(code, strings.Repeat("0", 40))
+	artifact.deployedCode, err = hexutil.Decode(code)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if artifact.SourceName == "" {
+		artifact.SourceName = foundrySourceName(aj)
+	}
+
+	return artifact, nil
+}
+
+// ReadSource reads source file of artifact in project directory, and
+// returns it in the format accepted by ParseSourceFiles.
+func (a *Artifact) ReadSource(projectDir string) (string, error) {
+	if a.SourceName == "" {
+		return "", nil
+	}
+
+	content, err := os.ReadFile(filepath.Join(projectDir, a.SourceName))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	source, err := json.Marshal(map[string]sourceFileJSON{
+		a.SourceName: {Content: string(content)},
+	})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return string(source), nil
+}
+
+// matchArtifact imports ABI, name, source and storage layout of contract
+// from artifacts in project directory, or clears them if the artifact they
+// were loaded from no longer matches. It returns true if contract is
+// changed, and false if nothing changed since last time.
+func (s *Service) matchArtifact(contract *Contract) bool {
+	version := s.artifacts.Refresh()
+	if version == 0 || version == contract.artifactVersion {
+		return false
+	}
+	contract.artifactVersion = version
+
+	artifact := s.artifacts.Match(s.GetNetwork().ChainId.String(), contract.address, contract.code)
+	if artifact == nil {
+		if !contract.artifactMatched {
+			return false
+		}
+		// artifact is deleted or recompiled with different bytecode
+		log.Debug("Artifact of contract is gone", "address", contract.address, "name", contract.name)
+		contract.clearArtifact()
+		return true
+	}
+
+	log.Debug("Found artifact of contract", "address", contract.address, "name", artifact.Name, "path", artifact.Path)
+	if err := contract.loadArtifact(artifact, s.config.ProjectDir); err != nil {
+		log.Warn("Cannot load artifact of contract", "address", contract.address, "path", artifact.Path, "error", err)
+		return false
+	}
+	contract.artifactMatched = true
+	return true
+}
+
+// clearArtifact drops everything imported from artifact.
+func (c *Contract) clearArtifact() {
+	c.abi = nil
+	c.layout = nil
+	c.name = ""
+	c.source = ""
+	c.artifactMatched = false
+}
+
+// loadArtifact imports ABI, name, source and storage layout from artifact.
+func (c *Contract) loadArtifact(artifact *Artifact, projectDir string) error {
+	if err := c.ImportABI(artifact.ABI); err != nil {
+		return err
+	}
+
+	c.layout = nil
+	if len(artifact.StorageLayout) > 0 {
+		layout, err := ParseStorageLayout(artifact.StorageLayout)
+		if err != nil {
+			return err
+		}
+		c.layout = layout
+	}
+
+	source, err := artifact.ReadSource(projectDir)
+	if err != nil {
+		log.Warn("Cannot read source of artifact", "path", artifact.Path, "error", err)
+	}
+	c.name = artifact.Name
+	c.source = source
+	return nil
+}
+
+// foundrySourceName finds source file from metadata or AST of Foundry artifacts.
+func foundrySourceName(aj artifactJSON) string {
+	var metadata struct {
+		Settings struct {
+			CompilationTarget map[string]string `json:"compilationTarget"`
+		} `json:"settings"`
+	}
+	if json.Unmarshal(aj.Metadata, &metadata) == nil {
+		for source := range metadata.Settings.CompilationTarget {
+			return source
+		}
+	}
+	if aj.AST != nil {
+		return aj.AST.AbsolutePath
+	}
+	return ""
+}
+
+// scanHardhatDeployments reads deployments of hardhat-deploy, which are
+// saved as `deployments/<network>/<name>.json`, along with chain id of
+// network in `.chainId`.
+func scanHardhatDeployments(root string) []*deployment {
+	networks, err := os.ReadDir(root)
+	if err != nil {
+		return []*deployment{}
+	}
+
+	result := make([]*deployment, 0)
+	for _, network := range networks {
+		if !network.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, network.Name())
+
+		chainId := ""
+		if data, err := os.ReadFile(filepath.Join(dir, ".chainId")); err == nil {
+			chainId = strings.TrimSpace(string(data))
+		}
+
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+				continue
+			}
+			path := filepath.Join(dir, file.Name())
+
+			var aj artifactJSON
+			data, err := os.ReadFile(path)
+			if err != nil || json.Unmarshal(data, &aj) != nil || !gcommon.IsHexAddress(aj.Address) {
+				continue
+			}
+			artifact, err := ParseArtifact(path)
+			if err != nil {
+				log.Debug("Skip invalid deployment", "path", path, "error", err)
+				continue
+			}
+			result = append(result, &deployment{
+				chainId:  chainId,
+				address:  gcommon.HexToAddress(aj.Address),
+				name:     artifact.Name,
+				artifact: artifact,
+			})
+		}
+	}
+	return result
+}
+
+// scanFoundryBroadcasts reads contracts created by Foundry scripts, which
+// are saved as `broadcast/<script>/<chain id>/run-latest.json`.
+func scanFoundryBroadcasts(root string) []*deployment {
+	result := make([]*deployment, 0)
+	walkJSON(root, func(path string) {
+		if filepath.Base(path) != "run-latest.json" {
+			return
+		}
+
+		var broadcast struct {
+			Transactions []struct {
+				TransactionType string `json:"transactionType"`
+				ContractName    string `json:"contractName"`
+				ContractAddress string `json:"contractAddress"`
+			} `json:"transactions"`
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || json.Unmarshal(data, &broadcast) != nil {
+			return
+		}
+
+		chainId := filepath.Base(filepath.Dir(path))
+		for _, txn := range broadcast.Transactions {
+			if !strings.HasPrefix(txn.TransactionType, "CREATE") || txn.ContractName == "" || !gcommon.IsHexAddress(txn.ContractAddress) {
+				continue
+			}
+			result = append(result, &deployment{
+				chainId: chainId,
+				address: gcommon.HexToAddress(txn.ContractAddress),
+				name:    txn.ContractName,
+			})
+		}
+	})
+	return result
+}
+
+// walkJSON calls fn with every JSON file under root, except those of build
+// info which are huge and contain no artifact.
+func walkJSON(root string, fn func(path string)) {
+	filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.IsDir() {
+			if entry.Name() == "build-info" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) == ".json" {
+			fn(path)
+		}
+		return nil
+	})
+}
+
+// parseCodeRanges parses link references in the form of
+// `{"file": {"library": [{"start": 0, "length": 20}]}}`.
+func parseCodeRanges(raw json.RawMessage) []codeRange {
+	var refs map[string]map[string][]codeRange
+	if len(raw) == 0 || json.Unmarshal(raw, &refs) != nil {
+		return []codeRange{}
+	}
+	result := make([]codeRange, 0)
+	for _, libs := range refs {
+		for _, ranges := range libs {
+			result = append(result, ranges...)
+		}
+	}
+	return result
+}
+
+// parseImmutableRanges parses immutable references in the form of
+// `{"id": [{"start": 0, "length": 32}]}`.
+func parseImmutableRanges(raw json.RawMessage) []codeRange {
+	var refs map[string][]codeRange
+	if len(raw) == 0 || json.Unmarshal(raw, &refs) != nil {
+		return []codeRange{}
+	}
+	result := make([]codeRange, 0)
+	for _, ranges := range refs {
+		result = append(result, ranges...)
+	}
+	return result
+}
+
+// codeMatches compares bytecode on chain with compiled one, ignoring
+// metadata appended by solc, and bytes in masks.
+func codeMatches(code []byte, compiled []byte, masks []codeRange) bool {
+	code, compiled = stripMetadata(code), stripMetadata(compiled)
+	if len(code) != len(compiled) || len(code) == 0 {
+		return false
+	}
+
+	code, compiled = append([]byte{}, code...), append([]byte{}, compiled...)
+	for _, m := range masks {
+		if m.Start < 0 || m.Start+m.Length > len(code) {
+			return false
+		}
+		for i := m.Start; i < m.Start+m.Length; i++ {
+			code[i], compiled[i] = 0, 0
+		}
+	}
+	return bytes.Equal(code, compiled)
+}
+
+// stripMetadata removes CBOR-encoded metadata at the end of bytecode, whose
+// length is given by the last two bytes.
+func stripMetadata(code []byte) []byte {
+	if len(code) < 2 {
+		return code
+	}
+	length := int(code[len(code)-2])<<8 | int(code[len(code)-1])
+	start := len(code) - 2 - length
+	if length == 0 || start < 0 || code[start]&0xf0 != 0xa0 {
+		return code
+	}
+	return code[:start]
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dyng/ramen/internal/common"
+	conf "github.com/dyng/ramen/internal/config"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	// bytecode of counter, followed by metadata of 3 bytes
+	testCounterCode = "0x6001600055" + "a10102" + "0003"
+	// bytecode of vault, whose 2nd to 5th bytes are an immutable
+	testVaultCode = "0x63aabbccdd50" + "a10102" + "0003"
+
+	testArtifactABI = `[{"type":"function","name":"count","inputs":[],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"}]`
+)
+
+func TestArtifactIndex_MatchByBytecode(t *testing.T) {
+	// prepare
+	index := NewArtifactIndex(newTestProject(t))
+	index.Refresh()
+
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{testCounterCode, "Counter"},
+		{"0x6001600055" + "a10909" + "0003", "Counter"},      // different metadata
+		{"0x6311223344" + "50" + "a10102" + "0003", "Vault"}, // different immutable
+		{"0x6002600055" + "a10102" + "0003", ""},
+	}
+
+	for _, test := range tests {
+		// process
+		artifact := index.Match("31337", gcommon.HexToAddress("0x01"), hexutil.MustDecode(test.code))
+
+		// verify
+		if test.expected == "" {
+			assert.Nil(t, artifact, test.code)
+		} else if assert.NotNil(t, artifact, test.code) {
+			assert.Equal(t, test.expected, artifact.Name, test.code)
+		}
+	}
+}
+
+func TestArtifactIndex_MatchByDeployment(t *testing.T) {
+	// prepare
+	index := NewArtifactIndex(newTestProject(t))
+	index.Refresh()
+	token := gcommon.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	vault := gcommon.HexToAddress("0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512")
+
+	// process & verify: hardhat-deploy deployment
+	artifact := index.Match("31337", token, []byte{0x00})
+	if assert.NotNil(t, artifact) {
+		assert.Equal(t, "Token", artifact.Name)
+	}
+	assert.Nil(t, index.Match("1", token, []byte{0x00}), "deployed on another chain")
+
+	// process & verify: foundry broadcast
+	artifact = index.Match("31337", vault, []byte{0x00})
+	if assert.NotNil(t, artifact) {
+		assert.Equal(t, "Vault", artifact.Name)
+		assert.Equal(t, "src/Vault.sol", artifact.SourceName)
+		assert.NotEmpty(t, artifact.StorageLayout)
+	}
+}
+
+func TestArtifactIndex_Rescan(t *testing.T) {
+	// prepare
+	defer func(interval time.Duration) { artifactCheckInterval = interval }(artifactCheckInterval)
+	artifactCheckInterval = 0
+	dir := newTestProject(t)
+	index := NewArtifactIndex(dir)
+	code := hexutil.MustDecode("0x6003600055")
+
+	// process & verify: nothing changed
+	version := index.Refresh()
+	assert.Equal(t, version, index.Refresh())
+	assert.Nil(t, index.Match("31337", common.Address{}, code))
+
+	// process & verify: a new artifact is compiled
+	writeTestFile(t, dir, "artifacts/contracts/Box.sol/Box.json", `{"contractName":"Box","abi":[],"deployedBytecode":"0x6003600055"}`)
+	assert.Greater(t, index.Refresh(), version)
+	artifact := index.Match("31337", common.Address{}, code)
+	if assert.NotNil(t, artifact) {
+		assert.Equal(t, "Box", artifact.Name)
+	}
+
+	// process & verify: the artifact is deleted
+	version = index.Refresh()
+	assert.NoError(t, os.Remove(filepath.Join(dir, "artifacts/contracts/Box.sol/Box.json")))
+	assert.Greater(t, index.Refresh(), version)
+	assert.Nil(t, index.Match("31337", common.Address{}, code))
+}
+
+func TestMatchArtifact_Rescan(t *testing.T) {
+	// prepare
+	defer func(interval time.Duration) { artifactCheckInterval = interval }(artifactCheckInterval)
+	artifactCheckInterval = 0
+	dir := newTestProject(t)
+	serv := newStubService(t, nil)
+	serv.config = &conf.Config{ProjectDir: dir}
+	serv.artifacts = NewArtifactIndex(dir)
+	path := filepath.Join(dir, "artifacts/contracts/Counter.sol/Counter.json")
+	contract := &Contract{Account: &Account{service: serv, code: hexutil.MustDecode(testCounterCode)}}
+
+	// process & verify: matched
+	assert.True(t, serv.matchArtifact(contract))
+	assert.Equal(t, "Counter", contract.GetName())
+	assert.True(t, contract.HasABI())
+
+	// process & verify: recompiled with different bytecode
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	recompiled := strings.Replace(string(content), testCounterCode, "0x6005600055", 1)
+	assert.NoError(t, os.WriteFile(path, []byte(recompiled), 0600))
+	assert.True(t, serv.matchArtifact(contract))
+	assert.Empty(t, contract.GetName())
+	assert.False(t, contract.HasABI(), "stale ABI should be cleared")
+
+	// process & verify: nothing changed since
+	assert.False(t, serv.matchArtifact(contract))
+}
+
+func TestContract_LoadArtifact(t *testing.T) {
+	// prepare
+	dir := newTestProject(t)
+	artifact, err := ParseArtifact(filepath.Join(dir, "artifacts/contracts/Counter.sol/Counter.json"))
+	assert.NoError(t, err)
+	contract := &Contract{}
+
+	// process
+	err = contract.loadArtifact(artifact, dir)
+
+	// verify
+	assert.NoError(t, err)
+	assert.Equal(t, "Counter", contract.GetName())
+	assert.Contains(t, contract.GetABI().Methods, "count")
+	files := contract.GetSourceFiles()
+	if assert.Len(t, files, 1) {
+		assert.Equal(t, "contracts/Counter.sol", files[0].Name)
+		assert.Equal(t, "contract Counter {}", files[0].Content)
+	}
+}
+
+// newTestProject creates a project with artifacts of Hardhat and Foundry.
+func newTestProject(t *testing.T) string {
+	dir := t.TempDir()
+
+	// hardhat
+	writeTestFile(t, dir, "contracts/Counter.sol", "contract Counter {}")
+	writeTestFile(t, dir, "artifacts/contracts/Counter.sol/Counter.json",
+		`{"_format":"hh-sol-artifact-1","contractName":"Counter","sourceName":"contracts/Counter.sol","abi":`+testArtifactABI+`,"deployedBytecode":"`+testCounterCode+`","deployedLinkReferences":{}}`)
+	writeTestFile(t, dir, "artifacts/contracts/Counter.sol/Counter.dbg.json", `{"_format":"hh-sol-dbg-1","buildInfo":"../../build-info/1.json"}`)
+	writeTestFile(t, dir, "artifacts/build-info/1.json", `{"abi":[],"deployedBytecode":"0x"}`)
+
+	// hardhat-deploy
+	writeTestFile(t, dir, "deployments/localhost/.chainId", "31337")
+	writeTestFile(t, dir, "deployments/localhost/Token.json",
+		`{"address":"0x5FbDB2315678afecb367f032d93F642f64180aa3","abi":[],"deployedBytecode":"0x6004600055"}`)
+
+	// foundry
+	writeTestFile(t, dir, "out/Vault.sol/Vault.json",
+		`{"abi":[],"deployedBytecode":{"object":"`+testVaultCode+`","linkReferences":{},"immutableReferences":{"7":[{"start":1,"length":4}]}},`+
+			`"metadata":{"settings":{"compilationTarget":{"src/Vault.sol":"Vault"}}},`+
+			`"storageLayout":{"storage":[{"label":"owner","offset":0,"slot":"0","type":"t_address"}],"types":{"t_address":{"encoding":"inplace","label":"address","numberOfBytes":"20"}}}}`)
+	writeTestFile(t, dir, "broadcast/Deploy.s.sol/31337/run-latest.json",
+		`{"transactions":[{"transactionType":"CREATE","contractName":"Vault","contractAddress":"0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512"}]}`)
+
+	return dir
+}
+
+func writeTestFile(t *testing.T, dir string, path string, content string) {
+	path = filepath.Join(dir, path)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
@@ -19,3 +19,21 @@ func (s *Service) cacheKey(address common.Address, accountType AccountType) stri
 	return chainId.String() + ":" + address.Hex() + ":" + accountType.String()
 }
 
+func (s *Service) receiptCacheKey(hash common.Hash) string {
+	chainId := s.GetNetwork().ChainId
+	return chainId.String() + ":receipt:" + hash.Hex()
+}
+
+func (s *Service) ensCacheKey(key string) string {
+	chainId := s.GetNetwork().ChainId
+	return chainId.String() + ":ens:" + key
+}
+
+func (s *Service) tokenCacheKey(address common.Address) string {
+	chainId := s.GetNetwork().ChainId
+	return chainId.String() + ":token:" + address.Hex()
+}
+
+func (s *Service) nftMetadataCacheKey(uri string) string {
+	return "nft-metadata:" + uri
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"encoding/json"
 	"strings"
 
 	"github.com/dyng/ramen/internal/common"
@@ -14,6 +15,15 @@ type Contract struct {
 	*Account
 	abi    *abi.ABI
 	source string
+	layout *StorageLayout
+	proxy  *Proxy
+	name   string
+
+	// version of artifact index this contract was last matched against, 0
+	// if it has never been matched
+	artifactVersion int
+	// true if ABI, name, source and layout are loaded from an artifact
+	artifactMatched bool
 }
 
 // HasABI returns true if this contract has a known ABI.
@@ -26,6 +36,11 @@ func (c *Contract) GetABI() *abi.ABI {
 	return c.abi
 }
 
+// GetName returns name of this contract, may be empty if it is unknown.
+func (c *Contract) GetName() string {
+	return c.name
+}
+
 // GetSource returns source of this contract, may be empty if source cannot be retrieved.
 func (c *Contract) GetSource() string {
 	return c.source
@@ -44,6 +59,55 @@ func (c *Contract) ImportABI(abiJson string) error {
 	return nil
 }
 
+// ImportJSON imports either an ABI, or an object accepted by ImportArtifact.
+func (c *Contract) ImportJSON(text string) error {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "{") {
+		return c.ImportArtifact(text)
+	}
+	return c.ImportABI(text)
+}
+
+// ImportArtifact imports ABI and storage layout from a JSON object, which is
+// either a storage layout, or a solc output containing `abi` and
+// `storageLayout`.
+func (c *Contract) ImportArtifact(artifactJson string) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(artifactJson), &fields); err != nil {
+		return errors.WithStack(err)
+	}
+
+	// the storage layout itself
+	if _, ok := fields["storage"]; ok {
+		layout, err := ParseStorageLayout([]byte(artifactJson))
+		if err != nil {
+			return err
+		}
+		c.layout = layout
+		return nil
+	}
+
+	abiJson, hasABI := fields["abi"]
+	layoutJson, hasLayout := fields["storageLayout"]
+	if !hasABI && !hasLayout {
+		return errors.New("Neither 'abi' nor 'storageLayout' is found")
+	}
+
+	if hasABI {
+		if err := c.ImportABI(string(abiJson)); err != nil {
+			return err
+		}
+	}
+	if hasLayout {
+		layout, err := ParseStorageLayout(layoutJson)
+		if err != nil {
+			return err
+		}
+		c.layout = layout
+	}
+	return nil
+}
+
 // ParseCalldata parses calldata into method name and arguments.
 func (c *Contract) ParseCalldata(data []byte) (*abi.Method, []any, error) {
 	if c.abi == nil {
@@ -75,15 +139,40 @@ func (c *Contract) Call(method string, args ...any) ([]any, error) {
 	}
 
 	log.Debug("Try to call contract", "method", method, "args", args)
-	return c.service.provider.CallContract(c.address, c.abi, method, args...)
+	vals, err := c.service.provider.CallContract(c.address, c.abi, method, args...)
+	if err != nil {
+		return nil, decodeCallError(err, c.abi)
+	}
+	return vals, nil
 }
 
 // Send invokes a non-constant method of this contract. This method will sign and send the transaction to the network.
-func (c *Contract) Send(signer *Signer, method string, args ...any) (common.Hash, error) {
+func (c *Contract) Send(signer Signer, opts *common.CallOpts, method string, args ...any) (common.Hash, error) {
 	_, ok := c.abi.Methods[method]
 	if !ok {
 		return common.Hash{}, errors.Errorf("Method %s is not found in contract", method)
 	}
 
-	return signer.CallContract(c.GetAddress(), c.abi, method, args...)
+	return signer.CallContract(c.GetAddress(), c.abi, opts, method, args...)
+}
+
+// Prepare builds a request of invoking a non-constant method of this contract, without signing it.
+func (c *Contract) Prepare(signer Signer, opts *common.CallOpts, method string, args ...any) (*common.TxnRequest, error) {
+	m, ok := c.abi.Methods[method]
+	if !ok {
+		return nil, errors.Errorf("Method %s is not found in contract", method)
+	}
+
+	if !m.IsPayable() && opts != nil && opts.Value != nil && opts.Value.Sign() > 0 {
+		return nil, errors.Errorf("Method %s is not payable", method)
+	}
+
+	return signer.PrepareCall(c.GetAddress(), c.abi, opts, method, args...)
+}
+
+// Simulate executes a request of invoking this contract without submitting
+// it, whose outputs and revert data are decoded by ABI of this contract.
+func (c *Contract) Simulate(txnReq *common.TxnRequest) (*Simulation, error) {
+	log.Debug("Try to simulate contract call", "contract", c.address)
+	return c.service.Simulate(txnReq, c.abi)
 }
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/dyng/ramen/internal/provider"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/patrickmn/go-cache"
+	"github.com/pkg/errors"
+)
+
+const ensRegistryABI = `[{"inputs":[{"internalType":"bytes32","name":"node","type":"bytes32"}],"name":"resolver","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"}]`
+
+const ensResolverABI = `[{"inputs":[{"internalType":"bytes32","name":"node","type":"bytes32"}],"name":"addr","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"bytes32","name":"node","type":"bytes32"}],"name":"name","outputs":[{"internalType":"string","name":"","type":"string"}],"stateMutability":"view","type":"function"}]`
+
+var (
+	registryABI abi.ABI
+	resolverABI abi.ABI
+)
+
+func init() {
+	var err error
+	registryABI, err = abi.JSON(strings.NewReader(ensRegistryABI))
+	if err != nil {
+		log.Error("Cannot parse ABI of ENS registry", "error", errors.WithStack(err))
+		common.Exit("Cannot parse ABI of ENS registry: %v", err)
+	}
+	resolverABI, err = abi.JSON(strings.NewReader(ensResolverABI))
+	if err != nil {
+		log.Error("Cannot parse ABI of ENS resolver", "error", errors.WithStack(err))
+		common.Exit("Cannot parse ABI of ENS resolver: %v", err)
+	}
+}
+
+// ENS resolves names to addresses and addresses to their primary names by
+// the ENS registry of connected network. Results are cached.
+type ENS struct {
+	service *Service
+}
+
+func NewENS(service *Service) *ENS {
+	return &ENS{
+		service: service,
+	}
+}
+
+// IsSupported returns true if ENS is deployed on connected network.
+func (e *ENS) IsSupported() bool {
+	return e.service.GetNetwork().ENS != nil
+}
+
+// Resolve returns the address that given name points to.
+func (e *ENS) Resolve(name string) (common.Address, error) {
+	name = strings.ToLower(name)
+	key := e.service.ensCacheKey("name:" + name)
+	if addr, found := e.service.cache.Get(key); found {
+		return addr.(common.Address), nil
+	}
+
+	node := NameHash(name)
+
+	resolver, err := e.resolver(node)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	vals, err := e.service.provider.CallContract(resolver, &resolverABI, "addr", node)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	addr := vals[0].(common.Address)
+	if addr == (common.Address{}) {
+		return common.Address{}, errors.Errorf("name %s does not resolve to any address", name)
+	}
+
+	e.service.cache.Set(key, addr, cache.DefaultExpiration)
+	return addr, nil
+}
+
+// LookupAddress returns the primary name of given address, or an empty string
+// if it has none.
+func (e *ENS) LookupAddress(addr common.Address) (string, error) {
+	names, err := e.LookupAddresses([]common.Address{addr})
+	if err != nil {
+		return "", err
+	}
+	return names[addr], nil
+}
+
+// LookupAddresses returns primary names of given addresses in batch.
+// Addresses without a primary name are absent from the result.
+//
+// A primary name is only accepted if it resolves back to the same address,
+// as anyone can claim any name in their reverse record.
+func (e *ENS) LookupAddresses(addrs []common.Address) (map[common.Address]string, error) {
+	result := make(map[common.Address]string)
+	if !e.IsSupported() {
+		return result, nil
+	}
+
+	// collect addresses not in cache
+	missing := make([]common.Address, 0)
+	seen := make(map[common.Address]bool)
+	for _, addr := range addrs {
+		if seen[addr] {
+			continue
+		}
+		seen[addr] = true
+
+		if name, found := e.CachedName(addr); found {
+			if name != "" {
+				result[addr] = name
+			}
+			continue
+		}
+		missing = append(missing, addr)
+	}
+
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	// reverse lookup
+	reverseNodes := make([]common.Hash, len(missing))
+	for i, addr := range missing {
+		reverseNodes[i] = ReverseNode(addr)
+	}
+	names, err := e.batchResolve(reverseNodes, "name")
+	if err != nil {
+		return nil, err
+	}
+
+	// forward lookup for verification
+	forwardNodes := make([]common.Hash, len(missing))
+	for i, name := range names {
+		if name != nil && name.(string) != "" {
+			forwardNodes[i] = NameHash(name.(string))
+		}
+	}
+	forwardAddrs, err := e.batchResolve(forwardNodes, "addr")
+	if err != nil {
+		return nil, err
+	}
+
+	for i, addr := range missing {
+		name := ""
+		if forwardAddrs[i] != nil && forwardAddrs[i].(common.Address) == addr {
+			name = names[i].(string)
+			result[addr] = name
+		}
+		e.service.cache.Set(e.service.ensCacheKey("addr:"+addr.Hex()), name, cache.DefaultExpiration)
+	}
+
+	return result, nil
+}
+
+// CachedName returns the primary name of given address only if it is in cache.
+// An empty string is returned for address known to have no primary name.
+func (e *ENS) CachedName(addr common.Address) (string, bool) {
+	if name, found := e.service.cache.Get(e.service.ensCacheKey("addr:" + addr.Hex())); found {
+		return name.(string), true
+	}
+	return "", false
+}
+
+// resolver returns the resolver of given node from registry.
+func (e *ENS) resolver(node common.Hash) (common.Address, error) {
+	network := e.service.GetNetwork()
+	if network.ENS == nil {
+		return common.Address{}, errors.Errorf("ENS is not supported on network %s", network.Name)
+	}
+
+	vals, err := e.service.provider.CallContract(network.ENS.Registry, &registryABI, "resolver", node)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	resolver := vals[0].(common.Address)
+	if resolver == (common.Address{}) {
+		return common.Address{}, errors.New("name is not registered or has no resolver")
+	}
+	return resolver, nil
+}
+
+// batchResolve calls given method of resolvers of nodes in batch, and returns
+// the first return value of each call. Result is nil for empty node, node
+// without resolver and failed call.
+func (e *ENS) batchResolve(nodes []common.Hash, method string) ([]any, error) {
+	registry := e.service.GetNetwork().ENS.Registry
+	result := make([]any, len(nodes))
+
+	// find resolvers of nodes
+	calls := make([]provider.ContractCall, 0)
+	indices := make([]int, 0)
+	for i, node := range nodes {
+		if node == (common.Hash{}) {
+			continue
+		}
+		calls = append(calls, provider.ContractCall{
+			Address: registry,
+			ABI:     &registryABI,
+			Method:  "resolver",
+			Args:    []any{node},
+		})
+		indices = append(indices, i)
+	}
+	if len(calls) == 0 {
+		return result, nil
+	}
+
+	vals, errs, err := e.service.provider.BatchCallContract(calls)
+	if err != nil {
+		return nil, err
+	}
+
+	// call method of resolvers
+	resolverCalls := make([]provider.ContractCall, 0)
+	resolverIndices := make([]int, 0)
+	for j, i := range indices {
+		if errs[j] != nil {
+			log.Debug("Failed to fetch ENS resolver", "node", nodes[i], "error", errs[j])
+			continue
+		}
+		resolver := vals[j][0].(common.Address)
+		if resolver == (common.Address{}) {
+			continue
+		}
+		resolverCalls = append(resolverCalls, provider.ContractCall{
+			Address: resolver,
+			ABI:     &resolverABI,
+			Method:  method,
+			Args:    []any{nodes[i]},
+		})
+		resolverIndices = append(resolverIndices, i)
+	}
+	if len(resolverCalls) == 0 {
+		return result, nil
+	}
+
+	vals, errs, err = e.service.provider.BatchCallContract(resolverCalls)
+	if err != nil {
+		return nil, err
+	}
+
+	for j, i := range resolverIndices {
+		if errs[j] != nil {
+			log.Debug("Failed to call ENS resolver", "node", nodes[i], "method", method, "error", errs[j])
+			continue
+		}
+		result[i] = vals[j][0]
+	}
+
+	return result, nil
+}
+
+// IsENSName returns true if given string looks like an ENS name, e.g. "vitalik.eth".
+func IsENSName(name string) bool {
+	if !strings.HasSuffix(name, ".eth") {
+		return false
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || strings.ContainsAny(label, " \t/\\:") {
+			return false
+		}
+	}
+	return true
+}
+
+// NameHash computes the namehash of given name as specified in EIP-137.
+// Name is only lower-cased, full UTS-46 normalization is not performed.
+func NameHash(name string) common.Hash {
+	node := common.Hash{}
+	if name == "" {
+		return node
+	}
+
+	labels := strings.Split(strings.ToLower(name), ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		labelHash := crypto.Keccak256([]byte(labels[i]))
+		node = crypto.Keccak256Hash(node.Bytes(), labelHash)
+	}
+	return node
+}
+
+// ReverseNode returns the node of given address's reverse record, which is
+// the namehash of "<address>.addr.reverse".
+func ReverseNode(addr common.Address) common.Hash {
+	return NameHash(strings.ToLower(addr.Hex()[2:]) + ".addr.reverse")
+}
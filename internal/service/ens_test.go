@@ -0,0 +1,27 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNameHash(t *testing.T) {
+	// process & verify
+	assert.Equal(t, common.Hash{}, NameHash(""))
+	assert.Equal(t, common.HexToHash("0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"), NameHash("eth"))
+	assert.Equal(t, common.HexToHash("0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"), NameHash("foo.eth"))
+	assert.Equal(t, NameHash("foo.eth"), NameHash("FOO.eth"))
+}
+
+func TestReverseNode(t *testing.T) {
+	// prepare
+	addr := common.HexToAddress("0xdD2FD4581271e230360230F9337D5c0430Bf44C0")
+
+	// process
+	node := ReverseNode(addr)
+
+	// verify
+	assert.Equal(t, NameHash("dd2fd4581271e230360230f9337d5c0430bf44c0.addr.reverse"), node)
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"github.com/dyng/ramen/internal/common"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+)
+
+// EventLog is a log emitted by transaction, decoded if emitter's ABI is known,
+// or guessed by signature database otherwise.
+type EventLog struct {
+	*types.Log
+	Event      *abi.Event // nil if log cannot be decoded
+	Args       []any      // arguments in the same order as Event.Inputs
+	Guessed    bool       // true if decoded by signature database
+	Candidates []string   // signatures of topic0 in signature database
+}
+
+// IsDecoded returns true if this log is decoded by ABI.
+func (l *EventLog) IsDecoded() bool {
+	return l.Event != nil
+}
+
+// Topic0 returns the first topic, which is event signature's hash for
+// non-anonymous events.
+func (l *EventLog) Topic0() (common.Hash, bool) {
+	if len(l.Topics) == 0 {
+		return common.Hash{}, false
+	}
+	return l.Topics[0], true
+}
+
+// DecodeLogs decodes logs by ABIs of their emitters if already loaded, and
+// guesses the others by signature database. Emitters are never fetched here,
+// as a transaction may touch lots of contracts.
+func (s *Service) DecodeLogs(logs []*types.Log) []*EventLog {
+	// each emitter is looked up only once
+	emitters := make(map[common.Address]*Contract)
+	for _, l := range logs {
+		if _, ok := emitters[l.Address]; !ok {
+			emitters[l.Address], _ = s.getCachedContract(l.Address)
+		}
+	}
+
+	result := make([]*EventLog, len(logs))
+	for i, l := range logs {
+		result[i] = &EventLog{Log: l}
+
+		contract := emitters[l.Address]
+		if contract != nil && contract.HasABI() {
+			event, args, err := contract.ParseLog(l)
+			if err == nil {
+				result[i].Event = event
+				result[i].Args = args
+				continue
+			}
+			log.Debug("Cannot decode log by ABI", "address", l.Address, "error", err)
+		}
+
+		// guess by topic0 at last
+		s.guessLog(result[i])
+	}
+	return result
+}
+
+func (s *Service) guessLog(el *EventLog) {
+	event, args, candidates := s.signatures.GuessLog(el.Log)
+	el.Candidates = candidates
+	if event != nil {
+		el.Event = event
+		el.Args = args
+		el.Guessed = true
+	}
+}
+
+// ParseLog parses a log into event and its arguments. Indexed arguments of
+// reference types (string, bytes, arrays and tuples) are only available as
+// their keccak256 hash.
+func (c *Contract) ParseLog(l *types.Log) (*abi.Event, []any, error) {
+	if c.abi == nil {
+		return nil, nil, errors.New("ABI is not loaded")
+	}
+
+	if len(l.Topics) == 0 {
+		return nil, nil, errors.New("anonymous event is not supported")
+	}
+
+	event, err := c.abi.EventByID(l.Topics[0])
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	args, err := parseLog(event, l)
+	if err != nil {
+		return nil, nil, err
+	}
+	return event, args, nil
+}
+
+// parseLog parses arguments of event from log.
+func parseLog(event *abi.Event, l *types.Log) ([]any, error) {
+	nonIndexed, err := event.Inputs.NonIndexed().Unpack(l.Data)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	args := make([]any, len(event.Inputs))
+	topics := l.Topics[1:]
+	for i, input := range event.Inputs {
+		if !input.Indexed {
+			args[i] = nonIndexed[0]
+			nonIndexed = nonIndexed[1:]
+			continue
+		}
+
+		if len(topics) == 0 {
+			return nil, errors.Errorf("topics of event %s are insufficient", event.Name)
+		}
+		topic := topics[0]
+		topics = topics[1:]
+
+		if IsHashedTopic(input.Type) {
+			args[i] = topic
+			continue
+		}
+
+		vals, err := abi.Arguments{{Type: input.Type}}.Unpack(topic.Bytes())
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		args[i] = vals[0]
+	}
+
+	return args, nil
+}
+
+// IsHashedTopic returns true if an indexed argument of given type is stored
+// as hash in topics.
+func IsHashedTopic(t abi.Type) bool {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+const transferEventABI = `[{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"}]`
+
+func TestParseLog(t *testing.T) {
+	// prepare
+	parsed, _ := abi.JSON(strings.NewReader(transferEventABI))
+	contract := &Contract{abi: &parsed}
+
+	from := common.HexToAddress("0xdD2FD4581271e230360230F9337D5c0430Bf44C0")
+	to := common.HexToAddress("0x8626f6940E2eb28930eFb4CeF49B2d1F2C9C1199")
+	l := &types.Log{
+		Topics: []common.Hash{
+			crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")),
+			common.BytesToHash(from.Bytes()),
+			common.BytesToHash(to.Bytes()),
+		},
+		Data: common.LeftPadBytes(big.NewInt(1000).Bytes(), 32),
+	}
+
+	// process
+	event, args, err := contract.ParseLog(l)
+
+	// verify
+	assert.NoError(t, err)
+	assert.Equal(t, "Transfer", event.Name)
+	assert.Equal(t, from, args[0])
+	assert.Equal(t, to, args[1])
+	assert.EqualValues(t, big.NewInt(1000), args[2])
+}
+
+type stubCodeEth struct {
+	calls int
+}
+
+func (s *stubCodeEth) GetCode(addr common.Address, block string) hexutil.Bytes {
+	s.calls++
+	return hexutil.Bytes{0x00}
+}
+
+func TestDecodeLogs(t *testing.T) {
+	// prepare
+	eth := &stubCodeEth{}
+	serv := newStubService(t, map[string]any{"eth": eth})
+	parsed, _ := abi.JSON(strings.NewReader(transferEventABI))
+	known := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	unknown := common.HexToAddress("0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512")
+	serv.SetCache(known, TypeContract, &Contract{Account: &Account{service: serv, address: known}, abi: &parsed}, cache.NoExpiration)
+
+	topics := []common.Hash{
+		crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")),
+		common.BytesToHash(known.Bytes()),
+		common.BytesToHash(unknown.Bytes()),
+	}
+	data := common.LeftPadBytes(big.NewInt(1000).Bytes(), 32)
+	logs := []*types.Log{
+		{Address: known, Topics: topics, Data: data},
+		{Address: unknown, Topics: topics, Data: data},
+		{Address: unknown, Topics: topics, Data: data},
+	}
+
+	// process
+	result := serv.DecodeLogs(logs)
+
+	// verify
+	assert.Zero(t, eth.calls, "emitters should not be fetched")
+	if assert.Len(t, result, 3) {
+		assert.True(t, result[0].IsDecoded())
+		assert.False(t, result[0].Guessed)
+		for _, l := range result[1:] {
+			assert.True(t, l.IsDecoded())
+			assert.True(t, l.Guessed, "logs of unknown emitters should be guessed")
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"github.com/dyng/ramen/internal/common"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/external"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// ExternalSigner signs transactions with an external signer speaking Clef's
+// JSON-RPC API (account_signTransaction), so that keys never enter this
+// process.
+type ExternalSigner struct {
+	*signerBase
+	Endpoint string
+	client   *external.ExternalSigner
+}
+
+// GetExternalSigner connects to the external signer at endpoint, and returns
+// a signer of given address. The first account of external signer is used if
+// address is empty.
+func (s *Service) GetExternalSigner(endpoint string, address string) (*ExternalSigner, error) {
+	client, err := external.NewExternalSigner(endpoint)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	accts := client.Accounts()
+	if len(accts) == 0 {
+		return nil, errors.Errorf("No account is available in external signer %s", endpoint)
+	}
+
+	account := accts[0]
+	if address != "" {
+		if !gcommon.IsHexAddress(address) {
+			return nil, errors.Errorf("Invalid address %s", address)
+		}
+		account = accounts.Account{Address: gcommon.HexToAddress(address)}
+		if !client.Contains(account) {
+			return nil, errors.Errorf("Account %s is not managed by external signer %s", address, endpoint)
+		}
+	}
+
+	signer := &ExternalSigner{
+		Endpoint: endpoint,
+		client:   client,
+	}
+	signer.signerBase = newSignerBase(s, account.Address, signer.SignTx)
+	return signer, nil
+}
+
+// Backend implements Signer
+func (s *ExternalSigner) Backend() string {
+	return BackendExternal
+}
+
+// SignTx implements Signer
+func (s *ExternalSigner) SignTx(txn *types.Transaction, chainId common.BigInt) (*types.Transaction, error) {
+	signed, err := s.client.SignTx(accounts.Account{Address: s.address}, txn, chainId)
+	return signed, errors.WithStack(err)
+}
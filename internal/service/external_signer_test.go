@@ -0,0 +1,102 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dyng/ramen/internal/config"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubSigner is a minimal external signer implementing Clef's account API.
+type stubSigner struct {
+	key *ecdsa.PrivateKey
+}
+
+type stubSignResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+func (s *stubSigner) Version() string {
+	return "6.1.0"
+}
+
+func (s *stubSigner) List() []gcommon.Address {
+	return []gcommon.Address{crypto.PubkeyToAddress(s.key.PublicKey)}
+}
+
+func (s *stubSigner) SignTransaction(args apitypes.SendTxArgs, methodSelector *string) (*stubSignResult, error) {
+	txn := args.ToTransaction()
+	signed, err := types.SignTx(txn, types.LatestSignerForChainID((*big.Int)(args.ChainID)), s.key)
+	if err != nil {
+		return nil, err
+	}
+	raw, _ := signed.MarshalBinary()
+	return &stubSignResult{Raw: raw, Tx: signed}, nil
+}
+
+func newStubSignerServer(t *testing.T, key *ecdsa.PrivateKey) *httptest.Server {
+	server := rpc.NewServer()
+	if err := server.RegisterName("account", &stubSigner{key: key}); err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestExternalSigner(t *testing.T) {
+	// prepare
+	key, _ := crypto.HexToECDSA("de9be858da4a475276426320d5e9262ecfc3ba460bfac56360bfa6c4c28b4ee0")
+	ts := newStubSignerServer(t, key)
+	serv := &Service{config: &config.Config{}}
+
+	to := gcommon.HexToAddress("0x8626f6940E2eb28930eFb4CeF49B2d1F2C9C1199")
+	chainId := big.NewInt(31337)
+	txn := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainId,
+		Nonce:     1,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(100),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(1000),
+	})
+
+	// process
+	signer, err := serv.GetExternalSigner(ts.URL, "")
+	assert.NoError(t, err)
+	signed, err := signer.SignTx(txn, chainId)
+
+	// verify
+	assert.NoError(t, err)
+	assert.Equal(t, BackendExternal, signer.Backend())
+	assert.Equal(t, "0xdD2FD4581271e230360230F9337D5c0430Bf44C0", signer.GetAddress().Hex(), "first account of external signer should be used")
+	sender, err := types.Sender(types.LatestSignerForChainID(chainId), signed)
+	assert.NoError(t, err)
+	assert.Equal(t, signer.GetAddress(), sender, "transaction should be signed by external signer")
+	assert.Equal(t, txn.Value(), signed.Value(), "signed transaction should keep its value")
+	assert.Equal(t, txn.Nonce(), signed.Nonce(), "signed transaction should keep its nonce")
+}
+
+func TestExternalSignerUnknownAccount(t *testing.T) {
+	// prepare
+	key, _ := crypto.HexToECDSA("de9be858da4a475276426320d5e9262ecfc3ba460bfac56360bfa6c4c28b4ee0")
+	ts := newStubSignerServer(t, key)
+	serv := &Service{config: &config.Config{}}
+
+	// process
+	_, err := serv.GetExternalSigner(ts.URL, "0x8626f6940E2eb28930eFb4CeF49B2d1F2C9C1199")
+
+	// verify
+	assert.Error(t, err, "account not managed by external signer should be rejected")
+}
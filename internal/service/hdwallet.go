@@ -0,0 +1,171 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// TestMnemonic is the well-known mnemonic whose accounts are funded by
+// Hardhat and Anvil devnets.
+const TestMnemonic = "test test test test test test test test test test test junk"
+
+// DefaultHDPath is the derivation path of accounts, where i is replaced by
+// index of account.
+const DefaultHDPath = "m/44'/60'/0'/0/i"
+
+// HDAccount is an account derived from a mnemonic.
+type HDAccount struct {
+	*KeySigner
+	Index int
+	Path  accounts.DerivationPath
+}
+
+// IsMnemonic returns true if given text looks like a mnemonic rather than a
+// private key.
+func IsMnemonic(text string) bool {
+	return len(strings.Fields(text)) > 1
+}
+
+// GetHDSigner returns a signer of the account at given index derived from
+// mnemonic and passphrase.
+func (s *Service) GetHDSigner(mnemonic string, passphrase string, index int) (*HDAccount, error) {
+	accts, err := s.DeriveAccounts(mnemonic, passphrase, index, 1)
+	if err != nil {
+		return nil, err
+	}
+	return accts[0], nil
+}
+
+// DeriveAccounts derives count accounts starting from given index, along the
+// configured derivation path.
+func (s *Service) DeriveAccounts(mnemonic string, passphrase string, offset int, count int) ([]*HDAccount, error) {
+	seed, err := mnemonicToSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*HDAccount, count)
+	for i := 0; i < count; i++ {
+		path, err := HDPath(s.hdPath(), offset+i)
+		if err != nil {
+			return nil, err
+		}
+
+		privKey, err := deriveKey(seed, path)
+		if err != nil {
+			return nil, err
+		}
+
+		result[i] = &HDAccount{
+			KeySigner: newKeySigner(s, privKey),
+			Index:     offset + i,
+			Path:      path,
+		}
+	}
+	return result, nil
+}
+
+func (s *Service) hdPath() string {
+	if s.config.HDPath == "" {
+		return DefaultHDPath
+	}
+	return s.config.HDPath
+}
+
+// HDPath returns the derivation path of account at given index. Component i
+// (or i' for hardened) in template is replaced by index, if there is no such
+// component, index is appended to the end.
+func HDPath(template string, index int) (accounts.DerivationPath, error) {
+	components := strings.Split(template, "/")
+	replaced := false
+	for i, c := range components {
+		switch strings.TrimSpace(c) {
+		case "i":
+			components[i] = strconv.Itoa(index)
+			replaced = true
+		case "i'":
+			components[i] = strconv.Itoa(index) + "'"
+			replaced = true
+		}
+	}
+	if !replaced {
+		components = append(components, strconv.Itoa(index))
+	}
+
+	path, err := accounts.ParseDerivationPath(strings.Join(components, "/"))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return path, nil
+}
+
+// mnemonicToSeed validates mnemonic and converts it to a BIP-39 seed.
+func mnemonicToSeed(mnemonic string, passphrase string) ([]byte, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		// do not wrap the original error, which may contain words of mnemonic
+		return nil, errors.New("Invalid mnemonic")
+	}
+	return seed, nil
+}
+
+// deriveKey derives private key from seed along path, following BIP-32.
+func deriveKey(seed []byte, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	key, chainCode := hmacSHA512([]byte("Bitcoin seed"), seed)
+
+	curveN := crypto.S256().Params().N
+	for _, index := range path {
+		data := make([]byte, 0, 37)
+		if index >= 0x80000000 {
+			// hardened child: 0x00 || parent private key || index
+			data = append(data, 0)
+			data = append(data, key...)
+		} else {
+			// normal child: parent public key || index
+			privKey, err := crypto.ToECDSA(key)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			data = append(data, crypto.CompressPubkey(&privKey.PublicKey)...)
+		}
+		data = binary.BigEndian.AppendUint32(data, index)
+
+		il, ir := hmacSHA512(chainCode, data)
+		ilNum := new(big.Int).SetBytes(il)
+		if ilNum.Cmp(curveN) >= 0 {
+			return nil, errors.Errorf("Invalid child key at index %d", index)
+		}
+		childNum := ilNum.Add(ilNum, new(big.Int).SetBytes(key))
+		childNum.Mod(childNum, curveN)
+		if childNum.Sign() == 0 {
+			return nil, errors.Errorf("Invalid child key at index %d", index)
+		}
+
+		key = childNum.FillBytes(make([]byte, 32))
+		chainCode = ir
+	}
+
+	privKey, err := crypto.ToECDSA(key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return privKey, nil
+}
+
+func hmacSHA512(key []byte, data []byte) ([]byte, []byte) {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	return sum[:32], sum[32:]
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/dyng/ramen/internal/config"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeriveAccounts(t *testing.T) {
+	// prepare
+	serv := &Service{config: &config.Config{}}
+
+	// process
+	accts, err := serv.DeriveAccounts(TestMnemonic, "", 0, 2)
+
+	// verify
+	assert.NoError(t, err)
+	assert.Equal(t, "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266", accts[0].GetAddress().Hex(), "first hardhat account should be derived")
+	assert.Equal(t, "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", hexKey(accts[0]), "private key should be derived")
+	assert.Equal(t, "0x70997970C51812dc3A010C7d01b50e0d17dc79C8", accts[1].GetAddress().Hex(), "second hardhat account should be derived")
+	assert.Equal(t, "m/44'/60'/0'/0/1", accts[1].Path.String())
+}
+
+func TestDeriveAccountsWithPassphrase(t *testing.T) {
+	// prepare
+	serv := &Service{config: &config.Config{}}
+
+	// process
+	withPass, err := serv.GetHDSigner(TestMnemonic, "secret", 0)
+
+	// verify
+	assert.NoError(t, err)
+	assert.NotEqual(t, "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266", withPass.GetAddress().Hex(), "passphrase should change derived account")
+}
+
+func TestDeriveAccountsInvalidMnemonic(t *testing.T) {
+	// prepare
+	serv := &Service{config: &config.Config{}}
+
+	// process
+	_, err := serv.DeriveAccounts("test test test test test test test test test test test test", "", 0, 1)
+
+	// verify
+	assert.Error(t, err, "mnemonic with bad checksum should be rejected")
+	assert.NotContains(t, err.Error(), "test", "error should not leak mnemonic")
+}
+
+func TestGetSignerWithMnemonic(t *testing.T) {
+	// prepare
+	serv := &Service{config: &config.Config{}}
+
+	// process
+	signer, err := serv.GetSigner(TestMnemonic)
+
+	// verify
+	assert.NoError(t, err)
+	assert.Equal(t, "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266", signer.GetAddress().Hex())
+}
+
+func TestHDPath(t *testing.T) {
+	cases := []struct {
+		template string
+		index    int
+		expected string
+	}{
+		{DefaultHDPath, 3, "m/44'/60'/0'/0/3"},
+		{"m/44'/60'/i'/0/0", 2, "m/44'/60'/2'/0/0"},
+		{"m/44'/60'/0'/0", 5, "m/44'/60'/0'/0/5"},
+	}
+
+	for _, c := range cases {
+		// process
+		path, err := HDPath(c.template, c.index)
+
+		// verify
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, path.String())
+	}
+}
+
+func hexKey(account *HDAccount) string {
+	return hex.EncodeToString(crypto.FromECDSA(account.PrivateKey))
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// scrypt parameters used to encrypt new keystores, tests may lower them to
+// save time
+var (
+	keystoreScryptN = keystore.StandardScryptN
+	keystoreScryptP = keystore.StandardScryptP
+)
+
+// KeystoreFile is an encrypted key file in keystore directory.
+type KeystoreFile struct {
+	Path    string
+	Address common.Address
+}
+
+// Name returns the file name of keystore.
+func (kf *KeystoreFile) Name() string {
+	return filepath.Base(kf.Path)
+}
+
+// ListKeystores lists keystore files in configured keystore directory, the
+// oldest first. Files which are not keystores are ignored.
+func (s *Service) ListKeystores() ([]*KeystoreFile, error) {
+	dir := s.config.KeystoreDir
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*KeystoreFile{}, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	result := make([]*KeystoreFile, 0)
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		addr, ok := readKeystoreAddress(path)
+		if !ok {
+			continue
+		}
+		result = append(result, &KeystoreFile{Path: path, Address: addr})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Name() < result[j].Name()
+	})
+
+	return result, nil
+}
+
+// UnlockKeystore decrypts given keystore with password and returns a signer
+// of the key.
+func (s *Service) UnlockKeystore(path string, password string) (*KeystoreSigner, error) {
+	keyjson, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	key, err := keystore.DecryptKey(keyjson, password)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &KeystoreSigner{
+		KeySigner: newKeySigner(s, key.PrivateKey),
+		Path:      path,
+	}, nil
+}
+
+// CreateKeystore generates a new key and saves it to keystore directory,
+// encrypted with password.
+func (s *Service) CreateKeystore(password string) (*KeystoreFile, error) {
+	privKey, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return s.storeKey(privKey, password)
+}
+
+// ExportKeystore saves private key of signer to keystore directory,
+// encrypted with password. Only signers holding a key in memory can be
+// exported.
+func (s *Service) ExportKeystore(signer Signer, password string) (*KeystoreFile, error) {
+	switch signer := signer.(type) {
+	case *KeySigner:
+		return s.storeKey(signer.PrivateKey, password)
+	case *KeystoreSigner:
+		return s.storeKey(signer.PrivateKey, password)
+	default:
+		return nil, errors.Errorf("Key of %s signer cannot be exported", signer.Backend())
+	}
+}
+
+func (s *Service) storeKey(privKey *ecdsa.PrivateKey, password string) (*KeystoreFile, error) {
+	if password == "" {
+		return nil, errors.New("Password of keystore cannot be empty")
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	key := &keystore.Key{
+		Id:         id,
+		Address:    crypto.PubkeyToAddress(privKey.PublicKey),
+		PrivateKey: privKey,
+	}
+
+	keyjson, err := keystore.EncryptKey(key, password, keystoreScryptN, keystoreScryptP)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	dir := s.config.KeystoreDir
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	path := filepath.Join(dir, keystoreFileName(key.Address))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if _, err := file.Write(keyjson); err != nil {
+		file.Close()
+		os.Remove(path)
+		return nil, errors.WithStack(err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &KeystoreFile{Path: path, Address: key.Address}, nil
+}
+
+// readKeystoreAddress reads the address field of a keystore file without
+// decrypting it.
+func readKeystoreAddress(path string) (common.Address, bool) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return common.Address{}, false
+	}
+
+	var header struct {
+		Address string `json:"address"`
+		Crypto  any    `json:"crypto"`
+	}
+	if err := json.Unmarshal(bytes, &header); err != nil || header.Crypto == nil {
+		return common.Address{}, false
+	}
+	if !gcommon.IsHexAddress(header.Address) {
+		return common.Address{}, false
+	}
+	return gcommon.HexToAddress(header.Address), true
+}
+
+// keystoreFileName returns a file name in the same format as geth, e.g.
+// UTC--2016-03-22T12-57-55.920751759Z--7ef5a6135f1fd6a02593eedc869c6d41d934aef8
+func keystoreFileName(addr common.Address) string {
+	ts := time.Now().UTC()
+	return fmt.Sprintf("UTC--%s--%s", toISO8601(ts), hex.EncodeToString(addr[:]))
+}
+
+func toISO8601(t time.Time) string {
+	return fmt.Sprintf("%04d-%02d-%02dT%02d-%02d-%02d.%09dZ",
+		t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond())
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dyng/ramen/internal/config"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeystore(t *testing.T) {
+	// prepare
+	keystoreScryptN, keystoreScryptP = keystore.LightScryptN, keystore.LightScryptP
+	dir := t.TempDir()
+	serv := &Service{config: &config.Config{KeystoreDir: filepath.Join(dir, "keystore")}}
+	signer, _ := serv.GetSigner("0xde9be858da4a475276426320d5e9262ecfc3ba460bfac56360bfa6c4c28b4ee0")
+
+	// process
+	exported, err := serv.ExportKeystore(signer, "secret")
+	assert.NoError(t, err)
+	created, err := serv.CreateKeystore("another secret")
+	assert.NoError(t, err)
+	os.WriteFile(filepath.Join(dir, "keystore", "README"), []byte("not a keystore"), 0600)
+	list, err := serv.ListKeystores()
+
+	// verify
+	assert.NoError(t, err)
+	assert.Len(t, list, 2, "non-keystore files should be ignored")
+	assert.Equal(t, exported.Address, signer.GetAddress(), "exported keystore should be of signer")
+	assert.NotEqual(t, created.Address, signer.GetAddress(), "created keystore should be of a new key")
+
+	info, _ := os.Stat(exported.Path)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "keystore should only be readable by owner")
+
+	unlocked, err := serv.UnlockKeystore(exported.Path, "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, signer.GetAddress(), unlocked.GetAddress(), "unlocked signer should have the same address")
+	assert.Equal(t, signer.PrivateKey.D, unlocked.PrivateKey.D, "unlocked signer should have the same key")
+
+	_, err = serv.UnlockKeystore(exported.Path, "wrong")
+	assert.Error(t, err, "wrong password should be rejected")
+}
+
+func TestListKeystoresWithoutDir(t *testing.T) {
+	// prepare
+	serv := &Service{config: &config.Config{KeystoreDir: filepath.Join(t.TempDir(), "missing")}}
+
+	// process
+	list, err := serv.ListKeystores()
+
+	// verify
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+}
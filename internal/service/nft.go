@@ -0,0 +1,507 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/dyng/ramen/internal/provider"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/patrickmn/go-cache"
+	"github.com/pkg/errors"
+)
+
+const nftABIJson = `[{"inputs":[],"name":"name","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},{"inputs":[{"name":"tokenId","type":"uint256"}],"name":"tokenURI","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},{"inputs":[{"name":"id","type":"uint256"}],"name":"uri","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"}]`
+
+const (
+	// StandardERC721 is the standard of non-fungible tokens
+	StandardERC721 = "ERC721"
+	// StandardERC1155 is the standard of multi tokens
+	StandardERC1155 = "ERC1155"
+
+	// NFTMetadataLimit is the maximum number of NFTs whose metadata is resolved
+	NFTMetadataLimit = 100
+	// nftMetadataConcurrency is the number of metadata downloaded concurrently
+	nftMetadataConcurrency = 8
+	// DefaultIPFSGateway is the HTTP gateway used to download metadata stored
+	// on IPFS, unless another one is configured
+	DefaultIPFSGateway = "https://ipfs.io/ipfs/"
+)
+
+var (
+	// ErrNFTsNotSupported is returned if NFT holdings cannot be listed by
+	// provider, and scanning logs of the whole chain is impractical.
+	ErrNFTsNotSupported = errors.New("NFT holdings need an Alchemy provider")
+
+	nftABI abi.ABI
+
+	transferEventID       = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	transferSingleEventID = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+	transferBatchEventID  = crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])"))
+
+	metadataClient = &http.Client{Timeout: 10 * time.Second}
+)
+
+func init() {
+	var err error
+	nftABI, err = abi.JSON(strings.NewReader(nftABIJson))
+	if err != nil {
+		log.Error("Cannot parse ABI of NFT", "error", errors.WithStack(err))
+		common.Exit("Cannot parse ABI of NFT: %v", err)
+	}
+}
+
+// NFT is an ERC-721 or ERC-1155 token held by an account.
+type NFT struct {
+	Contract   common.Address
+	Standard   string // StandardERC721 or StandardERC1155
+	TokenId    common.BigInt
+	Balance    common.BigInt
+	Collection string       // name of contract, may be empty
+	URI        string       // metadata URI, may be empty
+	Metadata   *NFTMetadata // nil if metadata cannot be resolved
+}
+
+// NFTMetadata is the metadata of an NFT, as specified in ERC-721 and ERC-1155.
+type NFTMetadata struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Attributes  []*NFTAttribute `json:"attributes"`
+}
+
+// NFTAttribute is a trait of an NFT, following OpenSea's metadata standard.
+type NFTAttribute struct {
+	TraitType string `json:"trait_type"`
+	Value     any    `json:"value"`
+}
+
+// GetNFTs returns NFTs held by given address. On Alchemy holdings are
+// computed from asset transfers, on a devnet from Transfer and TransferSingle
+// logs, otherwise ErrNFTsNotSupported is returned.
+func (s *Service) GetNFTs(owner common.Address) ([]*NFT, error) {
+	var (
+		holdings *nftHoldings
+		err      error
+	)
+	switch {
+	case s.provider.GetType() == provider.ProviderAlchemy:
+		holdings, err = s.listNFTTransfers(owner)
+	case s.GetNetwork().NetType() == TypeDevnet:
+		holdings, err = s.scanNFTTransfers(owner)
+	default:
+		return nil, ErrNFTsNotSupported
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	nfts := holdings.List()
+	s.resolveNFTMetadata(nfts)
+	return nfts, nil
+}
+
+// listNFTTransfers collects NFT transfers of owner with Alchemy's API.
+func (s *Service) listNFTTransfers(owner common.Address) (*nftHoldings, error) {
+	holdings := newNFTHoldings()
+
+	for _, incoming := range []bool{true, false} {
+		params := provider.GetAssetTransfersParams{
+			FromBlock: "0x0",
+			Category:  []string{"erc721", "erc1155"},
+			Order:     "asc",
+			MaxCount:  "0x3e8",
+		}
+		if incoming {
+			params.ToAddress = owner.Hex()
+		} else {
+			params.FromAddress = owner.Hex()
+		}
+
+		// all pages are needed, as holdings are incorrect with partial history
+		for {
+			res, err := s.provider.GetAssetTransfers(params)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, transfer := range res.Transfers {
+				if transfer.RawContract == nil {
+					continue
+				}
+				contract := gcommon.HexToAddress(transfer.RawContract.Address)
+
+				switch transfer.Category {
+				case "erc721":
+					id := transfer.ERC721TokenId
+					if id == "" {
+						id = transfer.TokenId
+					}
+					tokenId, err := hexutil.DecodeBig(trimLeadingZeros(id))
+					if err != nil {
+						log.Warn("Cannot parse token id", "contract", contract, "tokenId", id, "error", err)
+						continue
+					}
+					holdings.Add(contract, StandardERC721, tokenId, big.NewInt(1), incoming)
+				case "erc1155":
+					for _, meta := range transfer.ERC1155Metadata {
+						tokenId, err := hexutil.DecodeBig(trimLeadingZeros(meta.TokenId))
+						if err != nil {
+							log.Warn("Cannot parse token id", "contract", contract, "tokenId", meta.TokenId, "error", err)
+							continue
+						}
+						value, err := hexutil.DecodeBig(trimLeadingZeros(meta.Value))
+						if err != nil {
+							log.Warn("Cannot parse token value", "contract", contract, "value", meta.Value, "error", err)
+							continue
+						}
+						holdings.Add(contract, StandardERC1155, tokenId, value, incoming)
+					}
+				}
+			}
+
+			if res.PageKey == "" {
+				break
+			}
+			params.PageKey = res.PageKey
+		}
+	}
+
+	return holdings, nil
+}
+
+// scanNFTTransfers collects NFT transfers of owner by scanning logs of all blocks.
+func (s *Service) scanNFTTransfers(owner common.Address) (*nftHoldings, error) {
+	ownerTopic := gcommon.BytesToHash(owner.Bytes())
+	queries := []ethereum.FilterQuery{
+		{FromBlock: big.NewInt(0), Topics: [][]common.Hash{{transferEventID}, {ownerTopic}}},
+		{FromBlock: big.NewInt(0), Topics: [][]common.Hash{{transferEventID}, nil, {ownerTopic}}},
+		{FromBlock: big.NewInt(0), Topics: [][]common.Hash{{transferSingleEventID, transferBatchEventID}, nil, {ownerTopic}}},
+		{FromBlock: big.NewInt(0), Topics: [][]common.Hash{{transferSingleEventID, transferBatchEventID}, nil, nil, {ownerTopic}}},
+	}
+
+	// a transfer to oneself matches more than one query
+	type logKey struct {
+		txHash common.Hash
+		index  uint
+	}
+	seen := make(map[logKey]bool)
+	logs := make([]types.Log, 0)
+	for _, query := range queries {
+		result, err := s.provider.FilterLogs(query)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range result {
+			key := logKey{l.TxHash, l.Index}
+			if !seen[key] {
+				seen[key] = true
+				logs = append(logs, l)
+			}
+		}
+	}
+
+	sort.SliceStable(logs, func(i, j int) bool {
+		if logs[i].BlockNumber != logs[j].BlockNumber {
+			return logs[i].BlockNumber < logs[j].BlockNumber
+		}
+		return logs[i].Index < logs[j].Index
+	})
+
+	holdings := newNFTHoldings()
+	for _, l := range logs {
+		if err := holdings.AddLog(owner, l); err != nil {
+			log.Debug("Cannot parse NFT transfer", "txHash", l.TxHash, "index", l.Index, "error", err)
+		}
+	}
+
+	return holdings, nil
+}
+
+// resolveNFTMetadata fills collection names, metadata URIs and metadata of NFTs.
+func (s *Service) resolveNFTMetadata(nfts []*NFT) {
+	if len(nfts) > NFTMetadataLimit {
+		nfts = nfts[:NFTMetadataLimit]
+	}
+	if len(nfts) == 0 {
+		return
+	}
+
+	// one call of name() for each contract, and one call of tokenURI() or uri() for each token
+	contracts := make([]common.Address, 0)
+	contractIndex := make(map[common.Address]int)
+	for _, nft := range nfts {
+		if _, ok := contractIndex[nft.Contract]; !ok {
+			contractIndex[nft.Contract] = len(contracts)
+			contracts = append(contracts, nft.Contract)
+		}
+	}
+
+	calls := make([]provider.ContractCall, 0, len(contracts)+len(nfts))
+	for _, contract := range contracts {
+		calls = append(calls, provider.ContractCall{Address: contract, ABI: &nftABI, Method: "name"})
+	}
+	for _, nft := range nfts {
+		method := "tokenURI"
+		if nft.Standard == StandardERC1155 {
+			method = "uri"
+		}
+		calls = append(calls, provider.ContractCall{Address: nft.Contract, ABI: &nftABI, Method: method, Args: []any{nft.TokenId}})
+	}
+
+	vals, errs, err := s.provider.BatchCallContract(calls)
+	if err != nil {
+		log.Error("Failed to fetch metadata URI of NFTs", "error", err)
+		return
+	}
+
+	for _, nft := range nfts {
+		if i := contractIndex[nft.Contract]; errs[i] == nil {
+			nft.Collection = vals[i][0].(string)
+		}
+	}
+
+	// download metadata concurrently
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, nftMetadataConcurrency)
+	for i, nft := range nfts {
+		j := len(contracts) + i
+		if errs[j] != nil {
+			log.Debug("Failed to fetch metadata URI of NFT", "contract", nft.Contract, "tokenId", nft.TokenId, "error", errs[j])
+			continue
+		}
+		nft.URI = ExpandTokenURI(vals[j][0].(string), nft.TokenId, s.ipfsGateway())
+		if nft.URI == "" {
+			continue
+		}
+
+		wg.Add(1)
+		go func(nft *NFT) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			metadata, err := s.GetNFTMetadata(nft.URI)
+			if err != nil {
+				log.Debug("Failed to fetch metadata of NFT", "contract", nft.Contract, "tokenId", nft.TokenId, "uri", nft.URI, "error", err)
+				return
+			}
+			nft.Metadata = metadata
+		}(nft)
+	}
+	wg.Wait()
+}
+
+// GetNFTMetadata downloads and parses metadata from given URI, which may be
+// an HTTP(S) URL, an IPFS URI or a data URI.
+func (s *Service) GetNFTMetadata(uri string) (*NFTMetadata, error) {
+	key := s.nftMetadataCacheKey(uri)
+	if metadata, found := s.cache.Get(key); found {
+		return metadata.(*NFTMetadata), nil
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if strings.HasPrefix(uri, "data:") {
+		data, err = decodeDataURI(uri)
+	} else {
+		data, err = downloadMetadata(uri)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := new(NFTMetadata)
+	if err := json.Unmarshal(data, metadata); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	s.cache.Set(key, metadata, cache.DefaultExpiration)
+	return metadata, nil
+}
+
+// ExpandTokenURI substitutes the {id} placeholder of ERC-1155 URI with token
+// id, and rewrites IPFS URI to a URL of given HTTP gateway.
+func ExpandTokenURI(uri string, tokenId common.BigInt, gateway string) string {
+	uri = strings.TrimSpace(uri)
+	if strings.Contains(uri, "{id}") {
+		uri = strings.ReplaceAll(uri, "{id}", fmt.Sprintf("%064x", tokenId))
+	}
+	if strings.HasPrefix(uri, "ipfs://") {
+		path := strings.TrimPrefix(uri, "ipfs://")
+		path = strings.TrimPrefix(path, "ipfs/")
+		uri = strings.TrimSuffix(gateway, "/") + "/" + path
+	}
+	return uri
+}
+
+func (s *Service) ipfsGateway() string {
+	if s.config.IPFSGateway == "" {
+		return DefaultIPFSGateway
+	}
+	return s.config.IPFSGateway
+}
+
+func downloadMetadata(uri string) ([]byte, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.Errorf("unsupported metadata URI %s", uri)
+	}
+
+	res, err := metadataClient.Get(uri)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to download metadata, status: %s", res.Status)
+	}
+
+	// metadata should never be large
+	data, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return data, nil
+}
+
+// decodeDataURI decodes the payload of a data URI (RFC 2397).
+func decodeDataURI(uri string) ([]byte, error) {
+	header, payload, found := strings.Cut(strings.TrimPrefix(uri, "data:"), ",")
+	if !found {
+		return nil, errors.New("malformed data URI")
+	}
+
+	if strings.HasSuffix(header, ";base64") {
+		data, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return data, nil
+	}
+
+	data, err := url.PathUnescape(payload)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return []byte(data), nil
+}
+
+// nftHoldings sums up transfers of an owner to find out NFTs it holds.
+type nftHoldings struct {
+	keys  []string
+	items map[string]*NFT
+}
+
+func newNFTHoldings() *nftHoldings {
+	return &nftHoldings{
+		keys:  make([]string, 0),
+		items: make(map[string]*NFT),
+	}
+}
+
+// Add records a transfer of amount tokens into (incoming) or out of owner.
+func (h *nftHoldings) Add(contract common.Address, standard string, tokenId common.BigInt, amount common.BigInt, incoming bool) {
+	key := contract.Hex() + ":" + tokenId.String()
+	nft, ok := h.items[key]
+	if !ok {
+		nft = &NFT{
+			Contract: contract,
+			Standard: standard,
+			TokenId:  tokenId,
+			Balance:  big.NewInt(0),
+		}
+		h.items[key] = nft
+		h.keys = append(h.keys, key)
+	}
+
+	if incoming {
+		nft.Balance.Add(nft.Balance, amount)
+	} else {
+		nft.Balance.Sub(nft.Balance, amount)
+	}
+}
+
+// AddLog records a Transfer, TransferSingle or TransferBatch log. ERC-20
+// transfers are ignored, as their value is not indexed.
+func (h *nftHoldings) AddLog(owner common.Address, l types.Log) error {
+	if len(l.Topics) != 4 {
+		return nil
+	}
+
+	switch l.Topics[0] {
+	case transferEventID:
+		from := gcommon.BytesToAddress(l.Topics[1].Bytes())
+		to := gcommon.BytesToAddress(l.Topics[2].Bytes())
+		tokenId := l.Topics[3].Big()
+		h.addTransfer(owner, from, to, l.Address, StandardERC721, tokenId, big.NewInt(1))
+	case transferSingleEventID:
+		if len(l.Data) != 64 {
+			return errors.New("malformed data of TransferSingle")
+		}
+		from := gcommon.BytesToAddress(l.Topics[2].Bytes())
+		to := gcommon.BytesToAddress(l.Topics[3].Bytes())
+		tokenId := new(big.Int).SetBytes(l.Data[:32])
+		value := new(big.Int).SetBytes(l.Data[32:])
+		h.addTransfer(owner, from, to, l.Address, StandardERC1155, tokenId, value)
+	case transferBatchEventID:
+		uintArr, _ := abi.NewType("uint256[]", "", nil)
+		vals, err := abi.Arguments{{Type: uintArr}, {Type: uintArr}}.Unpack(l.Data)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		ids := vals[0].([]*big.Int)
+		values := vals[1].([]*big.Int)
+		if len(ids) != len(values) {
+			return errors.New("lengths of ids and values in TransferBatch mismatch")
+		}
+		from := gcommon.BytesToAddress(l.Topics[2].Bytes())
+		to := gcommon.BytesToAddress(l.Topics[3].Bytes())
+		for i := range ids {
+			h.addTransfer(owner, from, to, l.Address, StandardERC1155, ids[i], values[i])
+		}
+	}
+
+	return nil
+}
+
+func (h *nftHoldings) addTransfer(owner, from, to, contract common.Address, standard string, tokenId, amount common.BigInt) {
+	if to == owner {
+		h.Add(contract, standard, tokenId, amount, true)
+	}
+	if from == owner {
+		h.Add(contract, standard, tokenId, amount, false)
+	}
+}
+
+// List returns NFTs with positive balance, in the order of first transfer.
+func (h *nftHoldings) List() []*NFT {
+	result := make([]*NFT, 0)
+	for _, key := range h.keys {
+		nft := h.items[key]
+		if nft.Balance.Sign() > 0 {
+			result = append(result, nft)
+		}
+	}
+	return result
+}
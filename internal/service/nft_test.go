@@ -0,0 +1,152 @@
+package service
+
+import (
+	"encoding/base64"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNFTHoldings_AddLog(t *testing.T) {
+	// prepare
+	owner := common.HexToAddress("0xdD2FD4581271e230360230F9337D5c0430Bf44C0")
+	other := common.HexToAddress("0x8626f6940E2eb28930eFb4CeF49B2d1F2C9C1199")
+	erc721 := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	erc1155 := common.HexToAddress("0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512")
+
+	transfer := func(from, to common.Address, tokenId int64) types.Log {
+		return types.Log{
+			Address: erc721,
+			Topics: []common.Hash{
+				transferEventID,
+				common.BytesToHash(from.Bytes()),
+				common.BytesToHash(to.Bytes()),
+				common.BigToHash(big.NewInt(tokenId)),
+			},
+		}
+	}
+	transferSingle := func(from, to common.Address, id, value int64) types.Log {
+		data := append(common.BigToHash(big.NewInt(id)).Bytes(), common.BigToHash(big.NewInt(value)).Bytes()...)
+		return types.Log{
+			Address: erc1155,
+			Topics: []common.Hash{
+				transferSingleEventID,
+				common.BytesToHash(other.Bytes()),
+				common.BytesToHash(from.Bytes()),
+				common.BytesToHash(to.Bytes()),
+			},
+			Data: data,
+		}
+	}
+	erc20Transfer := types.Log{
+		Address: erc721,
+		Topics: []common.Hash{
+			transferEventID,
+			common.BytesToHash(other.Bytes()),
+			common.BytesToHash(owner.Bytes()),
+		},
+		Data: common.BigToHash(big.NewInt(100)).Bytes(),
+	}
+
+	holdings := newNFTHoldings()
+
+	// process
+	for _, l := range []types.Log{
+		transfer(common.Address{}, owner, 1),
+		transfer(common.Address{}, owner, 2),
+		transfer(owner, other, 1),
+		transferSingle(common.Address{}, owner, 7, 10),
+		transferSingle(owner, other, 7, 3),
+		erc20Transfer,
+	} {
+		assert.NoError(t, holdings.AddLog(owner, l))
+	}
+	nfts := holdings.List()
+
+	// verify
+	assert.Len(t, nfts, 2)
+	assert.Equal(t, erc721, nfts[0].Contract)
+	assert.Equal(t, StandardERC721, nfts[0].Standard)
+	assert.Equal(t, "2", nfts[0].TokenId.String())
+	assert.Equal(t, "1", nfts[0].Balance.String())
+	assert.Equal(t, erc1155, nfts[1].Contract)
+	assert.Equal(t, StandardERC1155, nfts[1].Standard)
+	assert.Equal(t, "7", nfts[1].TokenId.String())
+	assert.Equal(t, "7", nfts[1].Balance.String())
+}
+
+func TestExpandTokenURI(t *testing.T) {
+	assert.Equal(t, "https://example.com/1.json", ExpandTokenURI("https://example.com/1.json", big.NewInt(1), DefaultIPFSGateway))
+	assert.Equal(t, "https://ipfs.io/ipfs/QmHash/1", ExpandTokenURI("ipfs://QmHash/1", big.NewInt(1), DefaultIPFSGateway))
+	assert.Equal(t, "https://ipfs.io/ipfs/QmHash/1", ExpandTokenURI("ipfs://ipfs/QmHash/1", big.NewInt(1), DefaultIPFSGateway))
+	assert.Equal(t, "https://gateway.pinata.cloud/ipfs/QmHash/1", ExpandTokenURI("ipfs://QmHash/1", big.NewInt(1), "https://gateway.pinata.cloud/ipfs"))
+	assert.Equal(t,
+		"https://example.com/000000000000000000000000000000000000000000000000000000000000002a.json",
+		ExpandTokenURI("https://example.com/{id}.json", big.NewInt(42), DefaultIPFSGateway))
+}
+
+func TestDecodeDataURI(t *testing.T) {
+	// prepare
+	json := `{"name":"Foo #1","attributes":[{"trait_type":"Color","value":"Red"}]}`
+	encoded := "data:application/json;base64," + base64.StdEncoding.EncodeToString([]byte(json))
+	plain := "data:application/json;utf8," + json
+
+	// process & verify
+	data, err := decodeDataURI(encoded)
+	assert.NoError(t, err)
+	assert.Equal(t, json, string(data))
+
+	data, err = decodeDataURI(plain)
+	assert.NoError(t, err)
+	assert.Equal(t, json, string(data))
+
+	_, err = decodeDataURI("data:application/json")
+	assert.Error(t, err)
+}
+
+type stubNetVersion struct {
+	version string
+}
+
+func (s *stubNetVersion) Version() string {
+	return s.version
+}
+
+type stubLogEth struct {
+	queries int
+}
+
+func (s *stubLogEth) GetLogs(filter map[string]any) []types.Log {
+	s.queries++
+	return []types.Log{}
+}
+
+func TestGetNFTs_ScanOnDevnet(t *testing.T) {
+	// prepare
+	eth := &stubLogEth{}
+	serv := newStubService(t, map[string]any{"eth": eth})
+
+	// process
+	nfts, err := serv.GetNFTs(common.HexToAddress("0xFABB0ac9d68B0B445fB7357272Ff202C5651694a"))
+
+	// verify
+	assert.NoError(t, err)
+	assert.Empty(t, nfts)
+	assert.Equal(t, 4, eth.queries, "logs should be scanned on devnet")
+}
+
+func TestGetNFTs_NotSupported(t *testing.T) {
+	// prepare
+	eth := &stubLogEth{}
+	serv := newStubService(t, map[string]any{"eth": eth, "net": &stubNetVersion{version: "1"}})
+
+	// process
+	_, err := serv.GetNFTs(common.HexToAddress("0xFABB0ac9d68B0B445fB7357272Ff202C5651694a"))
+
+	// verify
+	assert.ErrorIs(t, err, ErrNFTsNotSupported)
+	assert.Zero(t, eth.queries, "logs of mainnet should never be scanned")
+}
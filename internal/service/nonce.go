@@ -0,0 +1,255 @@
+package service
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// TxnPending means transaction has been submitted but not mined yet.
+	TxnPending TxnStatus = "Pending"
+	// TxnMined means transaction has been included in a block.
+	TxnMined = "Mined"
+	// TxnReplaced means another transaction with the same nonce has been mined.
+	TxnReplaced = "Replaced"
+	// TxnDropped means transaction's nonce has been used but itself is not mined.
+	TxnDropped = "Dropped"
+)
+
+// TxnStatus represents status of a transaction submitted by signer.
+type TxnStatus string
+
+func (ts TxnStatus) String() string {
+	return string(ts)
+}
+
+// PendingTxn is a transaction submitted by signer and tracked by NonceManager.
+type PendingTxn struct {
+	Hash        common.Hash
+	Nonce       uint64
+	Request     *common.TxnRequest
+	Status      TxnStatus
+	BlockNumber common.BigInt
+	SubmittedAt time.Time
+}
+
+// IsPending returns true if this transaction is still waiting to be mined.
+func (t *PendingTxn) IsPending() bool {
+	return t.Status == TxnPending
+}
+
+// NonceManager allocates nonces for a signer locally, so that transactions
+// sent in rapid succession never race each other, and keeps track of the
+// transactions in flight.
+type NonceManager struct {
+	*sync.Mutex
+
+	service *Service
+	address common.Address
+	next    *uint64 // next nonce to use, nil if not synchronized with network
+	txns    []*PendingTxn
+}
+
+func NewNonceManager(service *Service, address common.Address) *NonceManager {
+	return &NonceManager{
+		Mutex:   &sync.Mutex{},
+		service: service,
+		address: address,
+		txns:    make([]*PendingTxn, 0),
+	}
+}
+
+// Next reserves and returns the next nonce. Pending nonce is fetched from
+// network without holding the lock if not synchronized yet.
+func (m *NonceManager) Next() (uint64, error) {
+	m.Lock()
+	synced := m.next != nil
+	m.Unlock()
+
+	var pending uint64
+	if !synced {
+		nonce, err := m.service.provider.GetPendingNonce(m.address)
+		if err != nil {
+			return 0, err
+		}
+		pending = nonce
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	// others may have reserved nonces meanwhile, never go backwards
+	if m.next == nil || (!synced && *m.next < pending) {
+		m.next = &pending
+	}
+
+	nonce := *m.next
+	*m.next++
+	return nonce, nil
+}
+
+// Reset drops the locally allocated nonce, next call of Next will fetch
+// pending nonce from network again.
+func (m *NonceManager) Reset() {
+	m.Lock()
+	defer m.Unlock()
+	m.next = nil
+}
+
+// Track records a submitted transaction.
+func (m *NonceManager) Track(hash common.Hash, req *common.TxnRequest) *PendingTxn {
+	m.Lock()
+	defer m.Unlock()
+
+	txn := &PendingTxn{
+		Hash:        hash,
+		Nonce:       *req.Nonce,
+		Request:     req,
+		Status:      TxnPending,
+		SubmittedAt: time.Now(),
+	}
+	m.txns = append([]*PendingTxn{txn}, m.txns...)
+	return txn
+}
+
+// Find returns tracked transaction of given hash.
+func (m *NonceManager) Find(hash common.Hash) (*PendingTxn, bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	for _, txn := range m.txns {
+		if txn.Hash == hash {
+			return txn, true
+		}
+	}
+	return nil, false
+}
+
+// Transactions returns all tracked transactions, the latest first.
+func (m *NonceManager) Transactions() []*PendingTxn {
+	m.Lock()
+	defer m.Unlock()
+
+	txns := make([]*PendingTxn, len(m.txns))
+	copy(txns, m.txns)
+	return txns
+}
+
+// Sync updates status of tracked transactions with a newly arrived block.
+// Network is queried without holding the lock, so that Next and friends are
+// never blocked by a slow provider.
+func (m *NonceManager) Sync(block *common.Block) {
+	pending := m.syncBlock(block)
+	if len(pending) == 0 {
+		return
+	}
+
+	// transactions whose nonce has been used by others
+	confirmed, err := m.service.provider.GetNonce(m.address)
+	if err != nil {
+		log.Error("Failed to fetch nonce", "address", m.address, "error", err)
+		return
+	}
+
+	mined := make(map[common.Hash]common.BigInt)
+	outdated := make([]*PendingTxn, 0)
+	for _, txn := range pending {
+		if txn.Nonce >= confirmed {
+			continue
+		}
+
+		// it may be mined in a block we missed
+		found, err := m.service.provider.GetTransactionByHash(txn.Hash)
+		if err != nil {
+			log.Error("Failed to fetch transaction", "hash", txn.Hash, "error", err)
+			continue
+		}
+		if found != nil && found.BlockNumber() != nil {
+			mined[txn.Hash] = found.BlockNumber()
+		} else {
+			outdated = append(outdated, txn)
+		}
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	for _, txn := range m.txns {
+		if number, ok := mined[txn.Hash]; ok && txn.IsPending() {
+			txn.Status = TxnMined
+			txn.BlockNumber = number
+		}
+	}
+
+	for _, txn := range outdated {
+		if !txn.IsPending() {
+			continue
+		}
+		if m.isNonceMined(txn.Nonce) {
+			txn.Status = TxnReplaced
+		} else {
+			txn.Status = TxnDropped
+		}
+	}
+
+	// our local nonce falls behind, e.g. transactions sent from another wallet
+	if m.next != nil && *m.next < confirmed {
+		m.next = &confirmed
+	}
+}
+
+// syncBlock marks transactions included in block as mined, and returns the
+// ones still pending.
+func (m *NonceManager) syncBlock(block *common.Block) []*PendingTxn {
+	m.Lock()
+	defer m.Unlock()
+
+	pending := make([]*PendingTxn, 0)
+	for _, txn := range m.txns {
+		if !txn.IsPending() {
+			continue
+		}
+		if block.Transaction(txn.Hash) != nil {
+			txn.Status = TxnMined
+			txn.BlockNumber = block.Number()
+		} else {
+			pending = append(pending, txn)
+		}
+	}
+	return pending
+}
+
+func (m *NonceManager) isNonceMined(nonce uint64) bool {
+	for _, txn := range m.txns {
+		if txn.Nonce == nonce && txn.Status == TxnMined {
+			return true
+		}
+	}
+	return false
+}
+
+// bumpFee raises a fee by slightly more than 10%, which is the minimum
+// required by most nodes to replace a pending transaction.
+func bumpFee(fee common.BigInt) common.BigInt {
+	if fee == nil {
+		return nil
+	}
+	bumped := new(big.Int).Mul(fee, big.NewInt(11))
+	bumped.Div(bumped, big.NewInt(10))
+	return bumped.Add(bumped, big.NewInt(1))
+}
+
+// maxFee returns the larger one of two fees.
+func maxFee(a common.BigInt, b common.BigInt) common.BigInt {
+	if a == nil {
+		return b
+	}
+	if b == nil || a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
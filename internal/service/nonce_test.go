@@ -0,0 +1,308 @@
+package service
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/dyng/ramen/internal/common"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBumpFee(t *testing.T) {
+	// process
+	bumped := bumpFee(big.NewInt(1000000000))
+
+	// verify
+	assert.EqualValues(t, big.NewInt(1100000001), bumped, "fee should be raised by more than 10%")
+	assert.Nil(t, bumpFee(nil), "nil fee should stay nil")
+}
+
+func TestMaxFee(t *testing.T) {
+	assert.EqualValues(t, big.NewInt(2), maxFee(big.NewInt(1), big.NewInt(2)))
+	assert.EqualValues(t, big.NewInt(2), maxFee(big.NewInt(2), big.NewInt(1)))
+	assert.EqualValues(t, big.NewInt(1), maxFee(nil, big.NewInt(1)))
+	assert.EqualValues(t, big.NewInt(1), maxFee(big.NewInt(1), nil))
+}
+
+type stubNonceEth struct {
+	pending   uint64
+	confirmed uint64
+	header    *types.Header
+	missed    map[common.Hash]*types.Transaction // mined in blocks not synced
+	sent      []*types.Transaction
+
+	// if set, eth_getTransactionCount at this block waits to be released
+	blockOn string
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *stubNonceEth) GetTransactionCount(addr common.Address, block string) hexutil.Uint64 {
+	if block == s.blockOn {
+		s.entered <- struct{}{}
+		<-s.release
+	}
+	if block == "pending" {
+		return hexutil.Uint64(s.pending)
+	}
+	return hexutil.Uint64(s.confirmed)
+}
+
+func (s *stubNonceEth) GetTransactionByHash(hash common.Hash) (map[string]any, error) {
+	txn, ok := s.missed[hash]
+	if !ok {
+		return nil, nil
+	}
+	data, err := txn.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]any)
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, err
+	}
+	res["blockNumber"] = hexutil.EncodeBig(s.header.Number)
+	res["blockHash"] = s.header.Hash()
+	return res, nil
+}
+
+func (s *stubNonceEth) GetBlockByHash(hash common.Hash, full bool) *types.Header {
+	return s.header
+}
+
+func (s *stubNonceEth) GetBlockByNumber(number string, full bool) *types.Header {
+	return s.header
+}
+
+func (s *stubNonceEth) MaxPriorityFeePerGas() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(1e9))
+}
+
+func (s *stubNonceEth) SendRawTransaction(data hexutil.Bytes) (common.Hash, error) {
+	txn := new(types.Transaction)
+	if err := txn.UnmarshalBinary(data); err != nil {
+		return common.Hash{}, err
+	}
+	s.sent = append(s.sent, txn)
+	return txn.Hash(), nil
+}
+
+func newStubNonceEth() *stubNonceEth {
+	return &stubNonceEth{
+		header: &types.Header{Number: big.NewInt(16), Difficulty: big.NewInt(0), BaseFee: big.NewInt(1e9)},
+		missed: make(map[common.Hash]*types.Transaction),
+	}
+}
+
+// trackTxn tracks a dummy transaction of given nonce.
+func trackTxn(m *NonceManager, nonce uint64) *types.Transaction {
+	txn := types.NewTx(&types.LegacyTx{Nonce: nonce, GasPrice: big.NewInt(1), Gas: 21000})
+	m.Track(txn.Hash(), &common.TxnRequest{Nonce: &nonce})
+	return txn
+}
+
+func newTestBlock(txns ...*types.Transaction) *common.Block {
+	header := &types.Header{Number: big.NewInt(17), Difficulty: big.NewInt(0)}
+	return types.NewBlockWithHeader(header).WithBody(txns, nil)
+}
+
+func TestNonceManager_Next(t *testing.T) {
+	// prepare
+	eth := newStubNonceEth()
+	eth.pending = 5
+	m := NewNonceManager(newStubService(t, map[string]any{"eth": eth}), common.Address{})
+
+	// process & verify
+	nonce, err := m.Next()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, nonce)
+	nonce, _ = m.Next()
+	assert.EqualValues(t, 6, nonce, "nonce should be allocated locally")
+
+	eth.pending = 9
+	nonce, _ = m.Next()
+	assert.EqualValues(t, 7, nonce, "pending nonce should not be fetched again")
+
+	m.Reset()
+	nonce, _ = m.Next()
+	assert.EqualValues(t, 9, nonce, "pending nonce should be fetched after reset")
+}
+
+func TestNonceManager_Track(t *testing.T) {
+	// prepare
+	m := NewNonceManager(newStubService(t, nil), common.Address{})
+
+	// process
+	first := trackTxn(m, 1)
+	second := trackTxn(m, 2)
+
+	// verify
+	txns := m.Transactions()
+	if assert.Len(t, txns, 2) {
+		assert.Equal(t, second.Hash(), txns[0].Hash, "latest should be the first")
+		assert.Equal(t, first.Hash(), txns[1].Hash)
+		assert.True(t, txns[0].IsPending())
+	}
+
+	found, ok := m.Find(first.Hash())
+	assert.True(t, ok)
+	assert.EqualValues(t, 1, found.Nonce)
+	_, ok = m.Find(common.Hash{})
+	assert.False(t, ok)
+}
+
+func TestNonceManager_Sync(t *testing.T) {
+	// prepare
+	eth := newStubNonceEth()
+	eth.pending = 1
+	eth.confirmed = 5
+	m := NewNonceManager(newStubService(t, map[string]any{"eth": eth}), common.Address{})
+	_, err := m.Next()
+	assert.NoError(t, err)
+
+	included := trackTxn(m, 1)
+	missed := trackTxn(m, 2)
+	eth.missed[missed.Hash()] = missed
+	replaced := trackTxn(m, 3)
+	replacement := types.NewTx(&types.LegacyTx{Nonce: 3, GasPrice: big.NewInt(2), Gas: 21000})
+	replacementNonce := replacement.Nonce()
+	m.Track(replacement.Hash(), &common.TxnRequest{Nonce: &replacementNonce})
+	dropped := trackTxn(m, 4)
+	pending := trackTxn(m, 5)
+
+	// process
+	m.Sync(newTestBlock(included, replacement))
+
+	// verify
+	status := func(txn *types.Transaction) TxnStatus {
+		found, _ := m.Find(txn.Hash())
+		return found.Status
+	}
+	assert.Equal(t, TxnStatus(TxnMined), status(included))
+	assert.Equal(t, TxnStatus(TxnMined), status(replacement))
+	assert.Equal(t, TxnStatus(TxnMined), status(missed), "should be found by hash")
+	found, _ := m.Find(missed.Hash())
+	assert.EqualValues(t, big.NewInt(16), found.BlockNumber)
+	assert.Equal(t, TxnStatus(TxnReplaced), status(replaced))
+	assert.Equal(t, TxnStatus(TxnDropped), status(dropped))
+	assert.Equal(t, TxnStatus(TxnPending), status(pending))
+
+	nonce, _ := m.Next()
+	assert.EqualValues(t, 5, nonce, "local nonce should catch up with confirmed one")
+}
+
+func TestNonceManager_SyncUnlocked(t *testing.T) {
+	// prepare
+	eth := newStubNonceEth()
+	eth.blockOn = "latest"
+	eth.entered = make(chan struct{})
+	eth.release = make(chan struct{})
+	m := NewNonceManager(newStubService(t, map[string]any{"eth": eth}), common.Address{})
+	trackTxn(m, 0)
+
+	// process
+	done := make(chan struct{})
+	go func() {
+		m.Sync(newTestBlock())
+		close(done)
+	}()
+	<-eth.entered
+
+	// verify
+	allocated := make(chan struct{})
+	go func() {
+		m.Next()
+		m.Transactions()
+		close(allocated)
+	}()
+	select {
+	case <-allocated:
+	case <-time.After(time.Second):
+		t.Error("nonce manager should not be locked while querying network")
+	}
+
+	close(eth.release)
+	<-done
+}
+
+func TestSpeedUpAndCancel(t *testing.T) {
+	// prepare
+	eth := newStubNonceEth()
+	eth.pending = 7
+	serv := newStubService(t, map[string]any{"eth": eth})
+	key, _ := crypto.GenerateKey()
+	signer := newKeySigner(serv, key)
+	receiver := gcommon.HexToAddress("0x8626f6940E2eb28930eFb4CeF49B2d1F2C9C1199")
+
+	txnReq, err := signer.PrepareTransfer(receiver, big.NewInt(100))
+	assert.NoError(t, err)
+	hash, err := signer.Send(txnReq)
+	assert.NoError(t, err)
+
+	// process
+	spedUp, err := signer.SpeedUp(hash)
+	assert.NoError(t, err)
+	cancelled, err := signer.Cancel(hash)
+	assert.NoError(t, err)
+
+	// verify
+	if assert.Len(t, eth.sent, 3) {
+		original, speedUp, cancel := eth.sent[0], eth.sent[1], eth.sent[2]
+		assert.Equal(t, spedUp, speedUp.Hash())
+		assert.Equal(t, cancelled, cancel.Hash())
+
+		assert.EqualValues(t, 7, original.Nonce())
+		assert.EqualValues(t, 7, speedUp.Nonce(), "replacement should reuse the nonce")
+		assert.Equal(t, receiver, *speedUp.To())
+		assert.Equal(t, big.NewInt(100), speedUp.Value())
+		assert.Equal(t, bumpFee(original.GasTipCap()), speedUp.GasTipCap())
+		assert.Equal(t, bumpFee(original.GasFeeCap()), speedUp.GasFeeCap())
+
+		assert.EqualValues(t, 7, cancel.Nonce())
+		assert.Equal(t, signer.GetAddress(), *cancel.To(), "cancellation should be sent to signer itself")
+		assert.Zero(t, cancel.Value().Sign())
+	}
+	assert.Len(t, signer.GetPendingTxns(), 3)
+
+	_, err = signer.SpeedUp(common.Hash{})
+	assert.Error(t, err, "transactions not sent by signer cannot be sped up")
+}
+
+func TestNonceManager_NextUnlocked(t *testing.T) {
+	// prepare
+	eth := newStubNonceEth()
+	eth.pending = 3
+	eth.blockOn = "pending"
+	eth.entered = make(chan struct{})
+	eth.release = make(chan struct{})
+	m := NewNonceManager(newStubService(t, map[string]any{"eth": eth}), common.Address{})
+
+	// process
+	result := make(chan uint64)
+	go func() {
+		nonce, _ := m.Next()
+		result <- nonce
+	}()
+	<-eth.entered
+
+	// verify
+	listed := make(chan struct{})
+	go func() {
+		m.Transactions()
+		close(listed)
+	}()
+	select {
+	case <-listed:
+	case <-time.After(time.Second):
+		t.Error("nonce manager should not be locked while fetching pending nonce")
+	}
+
+	close(eth.release)
+	assert.EqualValues(t, 3, <-result)
+}
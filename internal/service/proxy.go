@@ -0,0 +1,208 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	ProxyEIP1967            = "EIP-1967"
+	ProxyEIP1967Transparent = "EIP-1967 Transparent"
+	ProxyEIP1967Beacon      = "EIP-1967 Beacon"
+	ProxyEIP1822            = "EIP-1822"
+	ProxyOpenZeppelin       = "OpenZeppelin Transparent"
+)
+
+// maxProxyDepth is the maximum number of proxies followed to find the
+// implementation, in case proxies point to each other.
+const maxProxyDepth = 4
+
+const beaconABIJson = `[{"inputs":[],"name":"implementation","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"}]`
+
+var (
+	// eip1822Slot is the slot of implementation defined by EIP-1822 (UUPS).
+	eip1822Slot = crypto.Keccak256Hash([]byte("PROXIABLE"))
+
+	// zosImplementationSlot and zosAdminSlot are the slots used by legacy
+	// OpenZeppelin (zos) transparent proxies.
+	zosImplementationSlot = crypto.Keccak256Hash([]byte("org.zeppelinos.proxy.implementation"))
+	zosAdminSlot          = crypto.Keccak256Hash([]byte("org.zeppelinos.proxy.admin"))
+
+	beaconABI abi.ABI
+)
+
+func init() {
+	var err error
+	beaconABI, err = abi.JSON(strings.NewReader(beaconABIJson))
+	if err != nil {
+		log.Error("Cannot parse ABI of beacon", "error", errors.WithStack(err))
+		common.Exit("Cannot parse ABI of beacon: %v", err)
+	}
+}
+
+// Proxy describes how a proxy contract delegates calls to its implementation.
+type Proxy struct {
+	Kind           string
+	Implementation common.Address
+	Admin          common.Address // zero if proxy has no admin
+	Beacon         common.Address // zero if proxy is not a beacon proxy
+}
+
+// BeaconResolver returns the implementation address provided by a beacon.
+type BeaconResolver func(beacon common.Address) (common.Address, error)
+
+// IsProxy returns true if this contract is a known kind of proxy.
+func (c *Contract) IsProxy() bool {
+	return c.proxy != nil
+}
+
+// GetProxy returns how this contract delegates calls, may be nil if this
+// contract is not a proxy.
+func (c *Contract) GetProxy() *Proxy {
+	return c.proxy
+}
+
+// DetectProxy detects proxy by reading the standard storage slots of
+// EIP-1967, EIP-1822 and legacy OpenZeppelin proxies. It returns nil if none
+// of them holds an implementation.
+func DetectProxy(read StorageReader, resolveBeacon BeaconResolver) (*Proxy, error) {
+	slots, err := ReadProxySlots(read)
+	if err != nil {
+		return nil, err
+	}
+
+	// EIP-1967
+	proxy := &Proxy{}
+	for _, slot := range slots {
+		switch slot.Name {
+		case "implementation":
+			proxy.Implementation = slot.Address
+		case "admin":
+			proxy.Admin = slot.Address
+		case "beacon":
+			proxy.Beacon = slot.Address
+		}
+	}
+	switch {
+	case proxy.Implementation != (common.Address{}):
+		proxy.Kind = ProxyEIP1967
+		if proxy.Admin != (common.Address{}) {
+			proxy.Kind = ProxyEIP1967Transparent
+		}
+		return proxy, nil
+	case proxy.Beacon != (common.Address{}):
+		impl, err := resolveBeacon(proxy.Beacon)
+		if err != nil {
+			return nil, err
+		}
+		proxy.Kind = ProxyEIP1967Beacon
+		proxy.Implementation = impl
+		return proxy, nil
+	}
+
+	// EIP-1822
+	impl, err := readAddress(read, eip1822Slot)
+	if err != nil {
+		return nil, err
+	}
+	if impl != (common.Address{}) {
+		return &Proxy{Kind: ProxyEIP1822, Implementation: impl}, nil
+	}
+
+	// legacy OpenZeppelin
+	impl, err = readAddress(read, zosImplementationSlot)
+	if err != nil {
+		return nil, err
+	}
+	if impl != (common.Address{}) {
+		admin, err := readAddress(read, zosAdminSlot)
+		if err != nil {
+			return nil, err
+		}
+		return &Proxy{Kind: ProxyOpenZeppelin, Implementation: impl, Admin: admin}, nil
+	}
+
+	return nil, nil
+}
+
+// MergeABI merges ABI of implementation into ABI of proxy, so that functions
+// of implementation can be called through proxy. Entries of proxy take
+// precedence over those of implementation with the same name.
+func MergeABI(proxy *abi.ABI, impl *abi.ABI) *abi.ABI {
+	if impl == nil {
+		return proxy
+	}
+	if proxy == nil {
+		proxy = &abi.ABI{}
+	}
+
+	merged := abi.ABI{
+		Constructor: proxy.Constructor,
+		Fallback:    proxy.Fallback,
+		Receive:     proxy.Receive,
+		Methods:     make(map[string]abi.Method),
+		Events:      make(map[string]abi.Event),
+		Errors:      make(map[string]abi.Error),
+	}
+	for _, a := range []*abi.ABI{impl, proxy} {
+		for name, m := range a.Methods {
+			merged.Methods[name] = m
+		}
+		for name, e := range a.Events {
+			merged.Events[name] = e
+		}
+		for name, e := range a.Errors {
+			merged.Errors[name] = e
+		}
+	}
+	return &merged
+}
+
+// resolveProxy detects whether contract is a proxy, and merges ABI of its
+// implementation if so. Depth is the number of proxies already followed to
+// reach contract.
+func (s *Service) resolveProxy(contract *Contract, depth int) error {
+	proxy, err := DetectProxy(contract.storageReader(nil), s.resolveBeacon)
+	if err != nil || proxy == nil {
+		return err
+	}
+
+	log.Debug("Detected proxy contract", "address", contract.address, "kind", proxy.Kind, "implementation", proxy.Implementation)
+	contract.proxy = proxy
+	if proxy.Implementation == contract.address {
+		return nil
+	}
+
+	impl, err := s.getContract(proxy.Implementation, depth+1)
+	if err != nil {
+		return err
+	}
+	contract.abi = MergeABI(contract.abi, impl.abi)
+	return nil
+}
+
+func (s *Service) resolveBeacon(beacon common.Address) (common.Address, error) {
+	result, err := s.provider.CallContract(beacon, &beaconABI, "implementation")
+	if err != nil {
+		return common.Address{}, err
+	}
+	impl, ok := result[0].(common.Address)
+	if !ok {
+		return common.Address{}, errors.Errorf("Unexpected implementation returned by beacon %s", beacon.Hex())
+	}
+	return impl, nil
+}
+
+func readAddress(read StorageReader, slot common.Hash) (common.Address, error) {
+	raw, err := read(slot)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return gcommon.BytesToAddress(raw.Bytes()), nil
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testImpl   = gcommon.HexToAddress("0xFABB0ac9d68B0B445fB7357272Ff202C5651694a")
+	testAdmin  = gcommon.HexToAddress("0x1c85638e118b37167e9298c2268758e058DdfDA0")
+	testBeacon = gcommon.HexToAddress("0x367761085BF3C12e5DA2Df99AC6E1a824612b8fb")
+)
+
+func TestDetectProxy(t *testing.T) {
+	tests := []struct {
+		name     string
+		storage  map[common.Hash]common.Hash
+		expected *Proxy
+	}{
+		{
+			name: "eip1967",
+			storage: map[common.Hash]common.Hash{
+				EIP1967Slots[0].Slot: addressHash(testImpl),
+			},
+			expected: &Proxy{Kind: ProxyEIP1967, Implementation: testImpl},
+		},
+		{
+			name: "eip1967 transparent",
+			storage: map[common.Hash]common.Hash{
+				EIP1967Slots[0].Slot: addressHash(testImpl),
+				EIP1967Slots[1].Slot: addressHash(testAdmin),
+			},
+			expected: &Proxy{Kind: ProxyEIP1967Transparent, Implementation: testImpl, Admin: testAdmin},
+		},
+		{
+			name: "eip1967 beacon",
+			storage: map[common.Hash]common.Hash{
+				EIP1967Slots[2].Slot: addressHash(testBeacon),
+			},
+			expected: &Proxy{Kind: ProxyEIP1967Beacon, Implementation: testImpl, Beacon: testBeacon},
+		},
+		{
+			name: "eip1822",
+			storage: map[common.Hash]common.Hash{
+				gcommon.HexToHash("0xc5f16f0fcc639fa48a6947836d9850f504798523bf8c9a3a87d5876cf622bcf7"): addressHash(testImpl),
+			},
+			expected: &Proxy{Kind: ProxyEIP1822, Implementation: testImpl},
+		},
+		{
+			name: "openzeppelin legacy",
+			storage: map[common.Hash]common.Hash{
+				gcommon.HexToHash("0x7050c9e0f4ca769c69bd3a8ef740bc37934f8e2c036e5a723fd8ee048ed3f8c3"): addressHash(testImpl),
+				gcommon.HexToHash("0x10d6a54a4754c8869d6886b5f5d7fbfa5b4522237ea5c60d11bc4e7a1ff9390b"): addressHash(testAdmin),
+			},
+			expected: &Proxy{Kind: ProxyOpenZeppelin, Implementation: testImpl, Admin: testAdmin},
+		},
+		{
+			name:     "not a proxy",
+			storage:  map[common.Hash]common.Hash{},
+			expected: nil,
+		},
+	}
+
+	resolveBeacon := func(beacon common.Address) (common.Address, error) {
+		assert.Equal(t, testBeacon, beacon)
+		return testImpl, nil
+	}
+
+	for _, test := range tests {
+		// process
+		proxy, err := DetectProxy(fakeReader(test.storage), resolveBeacon)
+
+		// verify
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, proxy, test.name)
+	}
+}
+
+func TestMergeABI(t *testing.T) {
+	// prepare
+	proxyABI, err := abi.JSON(strings.NewReader(`[
+		{"type":"function","name":"upgradeTo","inputs":[{"name":"impl","type":"address"}],"outputs":[],"stateMutability":"nonpayable"},
+		{"type":"event","name":"Upgraded","inputs":[{"name":"impl","type":"address","indexed":true}]},
+		{"type":"fallback","stateMutability":"payable"}
+	]`))
+	assert.NoError(t, err)
+	implABI, err := abi.JSON(strings.NewReader(`[
+		{"type":"function","name":"upgradeTo","inputs":[],"outputs":[],"stateMutability":"nonpayable"},
+		{"type":"function","name":"totalSupply","inputs":[],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"},
+		{"type":"error","name":"Unauthorized","inputs":[]}
+	]`))
+	assert.NoError(t, err)
+
+	// process
+	merged := MergeABI(&proxyABI, &implABI)
+
+	// verify
+	assert.Len(t, merged.Methods, 2)
+	assert.Len(t, merged.Methods["upgradeTo"].Inputs, 1, "proxy takes precedence")
+	assert.Contains(t, merged.Methods, "totalSupply")
+	assert.Contains(t, merged.Events, "Upgraded")
+	assert.Contains(t, merged.Errors, "Unauthorized")
+	assert.True(t, merged.HasFallback())
+	assert.Same(t, &proxyABI, MergeABI(&proxyABI, nil))
+}
+
+// stubProxyEth serves contracts which are EIP-1967 proxies of given
+// implementations.
+type stubProxyEth struct {
+	impls map[common.Address]common.Address
+	reads int
+}
+
+func (s *stubProxyEth) GetCode(addr common.Address, block string) hexutil.Bytes {
+	return hexutil.Bytes{0x00}
+}
+
+func (s *stubProxyEth) GetStorageAt(addr common.Address, slot common.Hash, block string) hexutil.Bytes {
+	s.reads++
+	if slot == EIP1967Slots[0].Slot {
+		return addressHash(s.impls[addr]).Bytes()
+	}
+	return common.Hash{}.Bytes()
+}
+
+func TestGetContract_ProxyCycle(t *testing.T) {
+	// prepare
+	first := gcommon.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	second := gcommon.HexToAddress("0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512")
+	eth := &stubProxyEth{impls: map[common.Address]common.Address{first: second, second: first}}
+	serv := newStubService(t, map[string]any{"eth": eth})
+	serv.registry = NewABIRegistry("")
+	serv.artifacts = NewArtifactIndex("")
+
+	// process
+	contract, err := serv.GetContract(first)
+
+	// verify
+	assert.NoError(t, err)
+	if assert.True(t, contract.IsProxy()) {
+		assert.Equal(t, second, contract.GetProxy().Implementation)
+	}
+	cached, found := serv.GetCache(first, TypeContract)
+	assert.True(t, found)
+	assert.Same(t, contract, cached, "contract should be cached once resolved")
+
+	// process again
+	reads := eth.reads
+	_, err = serv.GetContract(first)
+	_, err2 := serv.GetContract(second)
+
+	// verify
+	assert.NoError(t, err)
+	assert.NoError(t, err2)
+	assert.Equal(t, reads, eth.reads, "resolved proxies should not be read again")
+}
+
+func addressHash(addr common.Address) common.Hash {
+	return gcommon.BytesToHash(addr.Bytes())
+}
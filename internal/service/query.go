@@ -0,0 +1,138 @@
+package service
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/dyng/ramen/internal/common/conv"
+	"github.com/ethereum/go-ethereum"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// QueryType is the kind of a search query.
+type QueryType int
+
+const (
+	QueryAddress QueryType = iota
+	QueryHash
+	QueryBlockNumber
+	QueryENSName
+)
+
+// SearchResult is the result of a search query, only one of its fields is set.
+type SearchResult struct {
+	Account     *Account
+	Transaction common.Transaction
+	Block       *common.Block
+}
+
+// ClassifyQuery determines what a search query refers to:
+//   - 20-byte hex: an address
+//   - 32-byte hex: a transaction hash or a block hash
+//   - decimal number: a block number
+//   - name ending with ".eth": an ENS name
+func ClassifyQuery(query string) (QueryType, error) {
+	if query == "" {
+		return 0, errors.New("query is empty")
+	}
+
+	// decimal numbers without 0x prefix are always block numbers
+	if !strings.HasPrefix(query, "0x") && !strings.HasPrefix(query, "0X") && isDigits(query) {
+		return QueryBlockNumber, nil
+	}
+
+	if IsENSName(query) {
+		return QueryENSName, nil
+	}
+
+	hex := conv.Trim0xPrefix(query)
+	if !isHexDigits(hex) {
+		return 0, errors.Errorf("%s is not an address, hash, block number or ENS name", query)
+	}
+
+	switch len(hex) {
+	case 2 * gcommon.AddressLength:
+		return QueryAddress, nil
+	case 2 * gcommon.HashLength:
+		return QueryHash, nil
+	default:
+		return 0, errors.Errorf("hex string of %d characters is neither an address (40) nor a hash (64)", len(hex))
+	}
+}
+
+// Search looks up an account, a transaction or a block by given query.
+func (s *Service) Search(query string) (*SearchResult, error) {
+	query = strings.TrimSpace(query)
+	qtype, err := ClassifyQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	switch qtype {
+	case QueryBlockNumber:
+		number, _ := new(big.Int).SetString(query, 10)
+		block, err := s.GetBlockByNumber(number)
+		if err != nil {
+			return nil, err
+		}
+		return &SearchResult{Block: block}, nil
+	case QueryHash:
+		hash := gcommon.HexToHash(query)
+
+		// try as transaction hash first
+		txn, err := s.provider.GetTransactionByHash(hash)
+		if err != nil {
+			return nil, err
+		}
+		if txn != nil {
+			return &SearchResult{Transaction: txn}, nil
+		}
+
+		// then as block hash
+		block, err := s.GetBlockByHash(hash)
+		if err != nil {
+			if errors.Is(err, ethereum.NotFound) {
+				return nil, errors.Errorf("no transaction or block found with hash %s", hash.Hex())
+			}
+			return nil, err
+		}
+		return &SearchResult{Block: block}, nil
+	case QueryENSName:
+		addr, err := s.ens.Resolve(query)
+		if err != nil {
+			return nil, err
+		}
+		return s.searchAccount(addr.Hex())
+	default:
+		return s.searchAccount(query)
+	}
+}
+
+func (s *Service) searchAccount(address string) (*SearchResult, error) {
+	account, err := s.GetAccount(address)
+	if err != nil {
+		return nil, err
+	}
+	account.UpdateBalance() // populate balance cache
+	return &SearchResult{Account: account}, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isHexDigits(s string) bool {
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
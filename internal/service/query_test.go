@@ -0,0 +1,52 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected QueryType
+	}{
+		{"0xdD2FD4581271e230360230F9337D5c0430Bf44C0", QueryAddress},
+		{"dD2FD4581271e230360230F9337D5c0430Bf44C0", QueryAddress},
+		{"0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b", QueryHash},
+		{"16000000", QueryBlockNumber},
+		{"0", QueryBlockNumber},
+		{"vitalik.eth", QueryENSName},
+		{"pay.vitalik.eth", QueryENSName},
+	}
+
+	for _, test := range tests {
+		// process
+		qtype, err := ClassifyQuery(test.query)
+
+		// verify
+		assert.NoError(t, err, test.query)
+		assert.Equal(t, test.expected, qtype, test.query)
+	}
+}
+
+func TestClassifyQuery_Invalid(t *testing.T) {
+	invalids := []string{
+		"",
+		"0x",
+		"0x1234",
+		"0xdD2FD4581271e230360230F9337D5c0430Bf44CZ",
+		"-1",
+		"hello",
+		".eth",
+		"vitalik..eth",
+	}
+
+	for _, query := range invalids {
+		// process
+		_, err := ClassifyQuery(query)
+
+		// verify
+		assert.Error(t, err, query)
+	}
+}
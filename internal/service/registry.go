@@ -0,0 +1,156 @@
+package service
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dyng/ramen/internal/common"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// ErrRegistryNotConfigured is returned when modifying or listing a registry
+// without directory.
+var ErrRegistryNotConfigured = errors.New("Directory of ABI registry is not configured")
+
+// ABIRegistry saves imported ABIs on disk, so that they survive restarts.
+// Each entry is a JSON file at `<dir>/<chain id>/<address>.json`, holding
+// either an ABI or an object containing `abi` and `storageLayout`.
+type ABIRegistry struct {
+	dir string
+}
+
+// RegistryEntry is an ABI saved in registry.
+type RegistryEntry struct {
+	ChainId string
+	Address common.Address
+	Path    string
+}
+
+// NewABIRegistry creates a registry in dir, which is disabled if dir is empty.
+func NewABIRegistry(dir string) *ABIRegistry {
+	return &ABIRegistry{dir: dir}
+}
+
+// Path returns path of the entry for given contract, no matter whether it exists.
+func (r *ABIRegistry) Path(chainId string, address common.Address) string {
+	return filepath.Join(r.dir, chainId, strings.ToLower(address.Hex())+".json")
+}
+
+// Get returns JSON of the entry for given contract, found is false if there is no such entry.
+func (r *ABIRegistry) Get(chainId string, address common.Address) (abiJson string, found bool, err error) {
+	if r.dir == "" {
+		return "", false, nil
+	}
+
+	data, err := os.ReadFile(r.Path(chainId, address))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, errors.WithStack(err)
+	}
+	return string(data), true, nil
+}
+
+// Put validates JSON and saves it as the entry for given contract,
+// overwriting the existing one.
+func (r *ABIRegistry) Put(chainId string, address common.Address, abiJson string) error {
+	if r.dir == "" {
+		return ErrRegistryNotConfigured
+	}
+	if err := ValidateABIJson(abiJson); err != nil {
+		return err
+	}
+
+	path := r.Path(chainId, address)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.WriteFile(path, []byte(abiJson), 0600); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Delete removes the entry for given contract.
+func (r *ABIRegistry) Delete(chainId string, address common.Address) error {
+	if r.dir == "" {
+		return ErrRegistryNotConfigured
+	}
+
+	err := os.Remove(r.Path(chainId, address))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errors.Errorf("No ABI of %s is registered on chain %s", address.Hex(), chainId)
+		}
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// List lists all entries in registry, ordered by chain id and address.
+// Files which are not named after an address are ignored.
+func (r *ABIRegistry) List() ([]*RegistryEntry, error) {
+	if r.dir == "" {
+		return nil, ErrRegistryNotConfigured
+	}
+
+	chains, err := os.ReadDir(r.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*RegistryEntry{}, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+
+	result := make([]*RegistryEntry, 0)
+	for _, chain := range chains {
+		if !chain.IsDir() {
+			continue
+		}
+
+		files, err := os.ReadDir(filepath.Join(r.dir, chain.Name()))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for _, file := range files {
+			name := strings.TrimSuffix(file.Name(), ".json")
+			if file.IsDir() || name == file.Name() || !gcommon.IsHexAddress(name) {
+				continue
+			}
+			result = append(result, &RegistryEntry{
+				ChainId: chain.Name(),
+				Address: gcommon.HexToAddress(name),
+				Path:    filepath.Join(r.dir, chain.Name(), file.Name()),
+			})
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].ChainId != result[j].ChainId {
+			return result[i].ChainId < result[j].ChainId
+		}
+		return bytes.Compare(result[i].Address.Bytes(), result[j].Address.Bytes()) < 0
+	})
+
+	return result, nil
+}
+
+// ValidateABIJson returns error if JSON cannot be imported as ABI.
+func ValidateABIJson(abiJson string) error {
+	return new(Contract).ImportJSON(abiJson)
+}
+
+// GetABIRegistry returns the registry of imported ABIs.
+func (s *Service) GetABIRegistry() *ABIRegistry {
+	return s.registry
+}
+
+// RegisterABI saves JSON imported for contract to registry.
+func (s *Service) RegisterABI(address common.Address, abiJson string) error {
+	return s.registry.Put(s.GetNetwork().ChainId.String(), address, abiJson)
+}
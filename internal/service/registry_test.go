@@ -0,0 +1,118 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const testRegistryABI = `[{"type":"function","name":"totalSupply","inputs":[],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"}]`
+
+func TestABIRegistry_PutAndGet(t *testing.T) {
+	// prepare
+	registry := NewABIRegistry(t.TempDir())
+	addr := gcommon.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+
+	// process
+	err := registry.Put("31337", addr, testRegistryABI)
+
+	// verify
+	assert.NoError(t, err)
+	abiJson, found, err := registry.Get("31337", addr)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, testRegistryABI, abiJson)
+
+	_, found, err = registry.Get("1", addr)
+	assert.NoError(t, err)
+	assert.False(t, found, "entries are keyed by chain id")
+}
+
+func TestABIRegistry_PutInvalid(t *testing.T) {
+	// prepare
+	registry := NewABIRegistry(t.TempDir())
+	addr := gcommon.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+
+	// process
+	err := registry.Put("1", addr, `{"name": "not an abi"}`)
+
+	// verify
+	assert.Error(t, err)
+	_, found, _ := registry.Get("1", addr)
+	assert.False(t, found)
+}
+
+func TestABIRegistry_ListAndDelete(t *testing.T) {
+	// prepare
+	registry := NewABIRegistry(t.TempDir())
+	addr1 := gcommon.HexToAddress("0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512")
+	addr2 := gcommon.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	assert.NoError(t, registry.Put("31337", addr1, testRegistryABI))
+	assert.NoError(t, registry.Put("31337", addr2, testRegistryABI))
+	assert.NoError(t, registry.Put("1", addr1, testRegistryABI))
+
+	// process
+	entries, err := registry.List()
+
+	// verify
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "1", entries[0].ChainId)
+	assert.Equal(t, addr2, entries[1].Address)
+	assert.Equal(t, addr1, entries[2].Address)
+
+	// process & verify: delete
+	assert.NoError(t, registry.Delete("31337", addr2))
+	assert.Error(t, registry.Delete("31337", addr2))
+	entries, err = registry.List()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestABIRegistry_NotConfigured(t *testing.T) {
+	// prepare
+	registry := NewABIRegistry("")
+	addr := gcommon.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+
+	// a file at the relative path must never be touched
+	wd, cwd := t.TempDir(), mustGetwd(t)
+	assert.NoError(t, os.Chdir(wd))
+	t.Cleanup(func() { os.Chdir(cwd) })
+	path := registry.Path("31337", addr)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	assert.NoError(t, os.WriteFile(path, []byte(testRegistryABI), 0600))
+
+	// process & verify
+	assert.ErrorIs(t, registry.Put("31337", addr, testRegistryABI), ErrRegistryNotConfigured)
+	assert.ErrorIs(t, registry.Delete("31337", addr), ErrRegistryNotConfigured)
+	_, err := registry.List()
+	assert.ErrorIs(t, err, ErrRegistryNotConfigured)
+	assert.FileExists(t, filepath.Join(wd, path))
+}
+
+func mustGetwd(t *testing.T) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wd
+}
+
+func TestLoadRegisteredABI_LayoutOnly(t *testing.T) {
+	// prepare
+	serv := &Service{registry: NewABIRegistry(t.TempDir())}
+	addr := gcommon.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	assert.NoError(t, serv.registry.Put("31337", addr, `{"storageLayout": `+testStorageLayout+`}`))
+	contract := &Contract{Account: &Account{address: addr}}
+
+	// process
+	registered, err := serv.loadRegisteredABI("31337", contract)
+
+	// verify
+	assert.NoError(t, err)
+	assert.False(t, registered, "ABI should still be looked up elsewhere")
+	assert.True(t, contract.HasStorageLayout())
+}
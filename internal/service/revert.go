@@ -0,0 +1,204 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/dyng/ramen/internal/common/conv"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+var (
+	// selector of `Error(string)`
+	errorSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+	// selector of `Panic(uint256)`
+	panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+
+	// panicReasons explains codes of `Panic(uint256)` raised by Solidity
+	panicReasons = map[uint64]string{
+		0x00: "generic compiler inserted panic",
+		0x01: "assertion failed",
+		0x11: "arithmetic underflow or overflow",
+		0x12: "division or modulo by zero",
+		0x21: "conversion into non-existent enum value",
+		0x22: "access to incorrectly encoded storage byte array",
+		0x31: "pop on empty array",
+		0x32: "array index out of bounds",
+		0x41: "too much memory allocated",
+		0x51: "call to zero-initialized function",
+	}
+)
+
+// RevertError is the error of a reverted call, whose revert data is decoded.
+type RevertError struct {
+	Data   []byte // raw revert data
+	Reason string // decoded revert data
+	cause  error
+}
+
+// Error implements error
+func (e *RevertError) Error() string {
+	return "execution reverted: " + e.Reason
+}
+
+// Unwrap returns the original error returned by provider.
+func (e *RevertError) Unwrap() error {
+	return e.cause
+}
+
+// DecodeRevert describes revert data, which is either `Error(string)`,
+// `Panic(uint256)` or a custom error defined in contractABI. It returns empty
+// string if data is empty.
+func DecodeRevert(data []byte, contractABI *abi.ABI) string {
+	if len(data) == 0 {
+		return ""
+	}
+	if len(data) < 4 {
+		return fmt.Sprintf("unknown error 0x%x", data)
+	}
+
+	selector := data[:4]
+	switch {
+	case bytes.Equal(selector, errorSelector):
+		if reason, err := abi.UnpackRevert(data); err == nil {
+			return reason
+		}
+	case bytes.Equal(selector, panicSelector):
+		if len(data) == 4+32 {
+			code := new(big.Int).SetBytes(data[4:])
+			explain := "unknown panic code"
+			if code.IsUint64() && panicReasons[code.Uint64()] != "" {
+				explain = panicReasons[code.Uint64()]
+			}
+			return fmt.Sprintf("Panic(0x%x): %s", code, explain)
+		}
+	default:
+		if reason, ok := decodeCustomError(data, contractABI); ok {
+			return reason
+		}
+	}
+	return fmt.Sprintf("unknown error 0x%x", data)
+}
+
+// decodeCustomError decodes revert data by errors defined in ABI, e.g.
+// `InsufficientBalance(available: 1, required: 2)`.
+func decodeCustomError(data []byte, contractABI *abi.ABI) (string, bool) {
+	if contractABI == nil {
+		return "", false
+	}
+
+	for _, e := range contractABI.Errors {
+		if !bytes.Equal(e.ID[:4], data[:4]) {
+			continue
+		}
+
+		args, err := e.Inputs.Unpack(data[4:])
+		if err != nil {
+			log.Debug("Cannot decode custom error", "error", e.Sig, "data", hexutil.Encode(data), "cause", err)
+			continue
+		}
+
+		items := make([]string, len(args))
+		for i, arg := range args {
+			input := e.Inputs[i]
+			valStr, err := conv.PackArgument(input.Type, arg)
+			if err != nil {
+				valStr = fmt.Sprint(arg)
+			}
+			if input.Name != "" {
+				valStr = input.Name + ": " + valStr
+			}
+			items[i] = valStr
+		}
+		return fmt.Sprintf("%s(%s)", e.Name, strings.Join(items, ", ")), true
+	}
+	return "", false
+}
+
+// decodeCallError converts error of a reverted call to RevertError if it
+// carries revert data, otherwise error is returned as is.
+func decodeCallError(err error, contractABI *abi.ABI) error {
+	if err == nil {
+		return nil
+	}
+
+	data, ok := revertData(err)
+	if !ok {
+		return err
+	}
+	return &RevertError{
+		Data:   data,
+		Reason: DecodeRevert(data, contractABI),
+		cause:  err,
+	}
+}
+
+// revertData extracts revert data from error returned by provider. Geth and
+// Anvil give it as a hex string, while Hardhat wraps it in an object.
+func revertData(err error) ([]byte, bool) {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return nil, false
+	}
+
+	errData := dataErr.ErrorData()
+	if obj, ok := errData.(map[string]any); ok {
+		errData = obj["data"]
+	}
+
+	hex, ok := errData.(string)
+	if !ok {
+		return nil, false
+	}
+	data, decodeErr := hexutil.Decode(hex)
+	if decodeErr != nil || len(data) == 0 {
+		return nil, false
+	}
+	return data, true
+}
+
+// GetRevertReason replays a failed transaction by eth_call at its parent
+// block, and returns the decoded revert reason. Empty string is returned if
+// the transaction does not revert in replay, e.g. it runs out of gas or
+// depends on transactions before it in the same block.
+func (s *Service) GetRevertReason(txn common.Transaction) (string, error) {
+	if txn.BlockNumber() == nil || txn.BlockNumber().Sign() == 0 {
+		return "", errors.New("Transaction is not mined yet")
+	}
+
+	var contractABI *abi.ABI
+	if txn.To() != nil {
+		contract, err := s.GetContract(*txn.To())
+		if err == nil && contract.HasABI() {
+			contractABI = contract.GetABI()
+		}
+	}
+
+	txnReq := &common.TxnRequest{
+		From:  *txn.From(),
+		To:    txn.To(),
+		Value: txn.Value(),
+		Data:  txn.Data(),
+	}
+	parent := new(big.Int).Sub(txn.BlockNumber(), big.NewInt(1))
+	_, err := s.provider.CallTransaction(txnReq, parent)
+	if err == nil {
+		return "", nil
+	}
+
+	err = decodeCallError(err, contractABI)
+	if revertErr, ok := err.(*RevertError); ok {
+		return revertErr.Reason, nil
+	}
+	if strings.Contains(err.Error(), "execution reverted") {
+		return "reverted without reason", nil
+	}
+	return "", err
+}
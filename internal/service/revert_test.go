@@ -0,0 +1,135 @@
+package service
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/patrickmn/go-cache"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const testErrorABI = `[
+	{"type":"error","name":"InsufficientBalance","inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}]},
+	{"type":"error","name":"Unauthorized","inputs":[]}
+]`
+
+const testRevertString = "0x08c379a0" +
+	"0000000000000000000000000000000000000000000000000000000000000020" +
+	"000000000000000000000000000000000000000000000000000000000000000c" +
+	"696e73756666696369656e740000000000000000000000000000000000000000"
+
+type stubRevertError struct {
+	data any
+}
+
+func (e *stubRevertError) Error() string {
+	return "execution reverted"
+}
+
+func (e *stubRevertError) ErrorCode() int {
+	return 3
+}
+
+func (e *stubRevertError) ErrorData() any {
+	return e.data
+}
+
+type stubEth struct {
+	block  string
+	revert string
+}
+
+func (s *stubEth) Call(args map[string]any, block string) (hexutil.Bytes, error) {
+	s.block = block
+	return nil, &stubRevertError{data: s.revert}
+}
+
+func TestDecodeRevert(t *testing.T) {
+	// prepare
+	contractABI, err := abi.JSON(strings.NewReader(testErrorABI))
+	assert.NoError(t, err)
+	insufficientErr, unauthorizedErr := contractABI.Errors["InsufficientBalance"], contractABI.Errors["Unauthorized"]
+	insufficient, _ := insufficientErr.Inputs.Pack(big.NewInt(1), big.NewInt(2))
+	insufficient = append(insufficientErr.ID[:4:4], insufficient...)
+
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"empty", "0x", ""},
+		{"error string", testRevertString, "insufficient"},
+		{"panic", "0x4e487b71" + "0000000000000000000000000000000000000000000000000000000000000011", "Panic(0x11): arithmetic underflow or overflow"},
+		{"unknown panic", "0x4e487b71" + "00000000000000000000000000000000000000000000000000000000000000ff", "Panic(0xff): unknown panic code"},
+		{"custom error", hexutil.Encode(insufficient), "InsufficientBalance(available: 1, required: 2)"},
+		{"custom error without arguments", hexutil.Encode(unauthorizedErr.ID[:4]), "Unauthorized()"},
+		{"unknown error", "0xdeadbeef", "unknown error 0xdeadbeef"},
+	}
+
+	for _, test := range tests {
+		// process
+		reason := DecodeRevert(hexutil.MustDecode(test.data), &contractABI)
+
+		// verify
+		assert.Equal(t, test.expected, reason, test.name)
+	}
+
+	// custom errors are unknown without ABI
+	assert.Equal(t, "unknown error "+hexutil.Encode(insufficient), DecodeRevert(insufficient, nil))
+}
+
+func TestDecodeCallError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"geth", &stubRevertError{data: testRevertString}},
+		{"hardhat", &stubRevertError{data: map[string]any{"message": "reverted", "data": testRevertString}}},
+		{"wrapped", errors.WithStack(&stubRevertError{data: testRevertString})},
+	}
+
+	for _, test := range tests {
+		// process
+		err := decodeCallError(test.err, nil)
+
+		// verify
+		var revertErr *RevertError
+		if assert.ErrorAs(t, err, &revertErr, test.name) {
+			assert.Equal(t, "insufficient", revertErr.Reason, test.name)
+			assert.Equal(t, "execution reverted: insufficient", err.Error(), test.name)
+		}
+	}
+
+	// errors without revert data are kept
+	err := errors.New("connection refused")
+	assert.Equal(t, err, decodeCallError(err, nil))
+}
+
+func TestGetRevertReason(t *testing.T) {
+	// prepare
+	contractABI, err := abi.JSON(strings.NewReader(testErrorABI))
+	assert.NoError(t, err)
+	to := gcommon.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	from := gcommon.HexToAddress("0xFABB0ac9d68B0B445fB7357272Ff202C5651694a")
+	unauthorizedErr := contractABI.Errors["Unauthorized"]
+	eth := &stubEth{revert: hexutil.Encode(unauthorizedErr.ID[:4])}
+	serv := newStubService(t, map[string]any{"eth": eth})
+	contract := &Contract{Account: &Account{service: serv, address: to, code: []byte{0x00}}, abi: &contractABI}
+	serv.SetCache(to, TypeContract, contract, cache.NoExpiration)
+	txn := common.WrapTransaction(types.NewTx(&types.LegacyTx{To: &to, Data: []byte{0x01}}), big.NewInt(100), &from, 0)
+
+	// process
+	reason, err := serv.GetRevertReason(txn)
+
+	// verify
+	assert.NoError(t, err)
+	assert.Equal(t, "Unauthorized()", reason)
+	assert.Equal(t, "0x63", eth.block, "should be replayed at parent block")
+}
@@ -47,23 +47,43 @@ func init() {
 }
 
 type Service struct {
-	config   *conf.Config
-	esclient *etherscan.EtherscanClient
-	provider *provider.Provider
-	cache    *cache.Cache
+	config     *conf.Config
+	esclient   *etherscan.EtherscanClient
+	provider   *provider.Provider
+	cache      *cache.Cache
+	ens        *ENS
+	registry   *ABIRegistry
+	artifacts  *ArtifactIndex
+	signatures *SignatureDB
 }
 
 func NewService(config *conf.Config) *Service {
 	service := Service{
-		config:   config,
-		esclient: etherscan.NewEtherscanClient(config.EtherscanEndpoint(), config.EtherscanApiKey),
-		provider: provider.NewProvider(config.Endpoint(), config.Provider),
-		cache:    cache.New(5*time.Minute, 10*time.Minute), // default cache expiration is 5 minutes
+		config:     config,
+		esclient:   etherscan.NewEtherscanClient(config.EtherscanEndpoint(), config.EtherscanApiKey),
+		provider:   provider.NewProvider(config.Endpoint(), config.Provider),
+		cache:      cache.New(5*time.Minute, 10*time.Minute), // default cache expiration is 5 minutes
+		registry:   NewABIRegistry(config.ABIDir),
+		artifacts:  NewArtifactIndex(config.ProjectDir),
+		signatures: NewSignatureDB(),
+	}
+	service.ens = NewENS(&service)
+
+	// extend builtin signatures by user's
+	if config.SignatureFile != "" {
+		if err := service.signatures.LoadFile(config.SignatureFile); err != nil {
+			log.Warn("Cannot load signatures", "path", config.SignatureFile, "error", err)
+		}
 	}
 
 	return &service
 }
 
+// GetENS returns the ENS resolver of connected network.
+func (s *Service) GetENS() *ENS {
+	return s.ens
+}
+
 // GetProvider returns underlying provider instance.
 // Usually you don't need to tackle with provider directly.
 func (s *Service) GetProvider() *provider.Provider {
@@ -95,6 +115,11 @@ func (s *Service) GetGasPrice() (common.BigInt, error) {
 	return s.provider.GetGasPrice()
 }
 
+// GetFeeData returns suggested fees for next transaction.
+func (s *Service) GetFeeData() (*common.FeeData, error) {
+	return s.provider.GetFeeData()
+}
+
 // GetEthPrice returns ETH price in USD.
 func (s *Service) GetEthPrice() (*decimal.Decimal, error) {
 	return s.esclient.EthPrice()
@@ -126,6 +151,31 @@ func (s *Service) GetAccount(address string) (*Account, error) {
 	return a, nil
 }
 
+// GetBlockByNumber returns block of given number.
+func (s *Service) GetBlockByNumber(number common.BigInt) (*common.Block, error) {
+	return s.provider.GetBlockByNumber(number)
+}
+
+// GetBlockByHash returns block of given hash.
+func (s *Service) GetBlockByHash(hash common.Hash) (*common.Block, error) {
+	return s.provider.GetBlockByHash(hash)
+}
+
+// GetLatestBlocks returns last n blocks, the latest first.
+func (s *Service) GetLatestBlocks(n int) ([]*common.Block, error) {
+	max, err := s.GetBlockHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	numberList := make([]common.BigInt, 0, n)
+	for i := 0; i < n && uint64(i) <= max; i++ {
+		numberList = append(numberList, new(big.Int).SetUint64(max-uint64(i)))
+	}
+
+	return s.provider.BatchBlockByNumber(numberList)
+}
+
 // GetLatestTransactions returns last n transactions of at most nBlock blocks.
 func (s *Service) GetLatestTransactions(n int, nBlock int) (common.Transactions, error) {
 	max, err := s.GetBlockHeight()
@@ -180,6 +230,72 @@ func (s *Service) GetTransactionsByBlock(block *common.Block) (common.Transactio
 	return txns, nil
 }
 
+// GetReceipt returns receipt of given transaction, nil if it's not mined yet.
+func (s *Service) GetReceipt(hash common.Hash) (*common.Receipt, error) {
+	if receipt, found := s.GetCachedReceipt(hash); found {
+		return receipt, nil
+	}
+
+	receipt, err := s.provider.GetTransactionReceipt(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	// receipt of mined transaction never changes
+	if receipt != nil {
+		s.cache.Set(s.receiptCacheKey(hash), receipt, cache.DefaultExpiration)
+	}
+
+	return receipt, nil
+}
+
+// GetReceipts fetches receipts of given transactions in one batch, skipping
+// those already in cache.
+func (s *Service) GetReceipts(txns common.Transactions) ([]*common.Receipt, error) {
+	result := make([]*common.Receipt, len(txns))
+
+	missing := make([]common.Hash, 0)
+	indexes := make([]int, 0)
+	for i, txn := range txns {
+		if receipt, found := s.GetCachedReceipt(txn.Hash()); found {
+			result[i] = receipt
+			continue
+		}
+		// pending transaction has no receipt
+		if txn.BlockNumber() == nil {
+			continue
+		}
+		missing = append(missing, txn.Hash())
+		indexes = append(indexes, i)
+	}
+
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	receipts, err := s.provider.BatchTransactionReceipt(missing)
+	if err != nil {
+		return result, err
+	}
+
+	for i, receipt := range receipts {
+		if receipt != nil {
+			s.cache.Set(s.receiptCacheKey(missing[i]), receipt, cache.DefaultExpiration)
+			result[indexes[i]] = receipt
+		}
+	}
+
+	return result, nil
+}
+
+// GetCachedReceipt returns receipt of given transaction only if it is in cache.
+func (s *Service) GetCachedReceipt(hash common.Hash) (*common.Receipt, bool) {
+	if receipt, found := s.cache.Get(s.receiptCacheKey(hash)); found {
+		return receipt.(*common.Receipt), true
+	}
+	return nil, false
+}
+
 // GetTransactionHistory returns transactions related to specified account.
 // This method relies on Etherscan API at chains other than local chain.
 func (s *Service) GetTransactionHistory(address common.Address) (common.Transactions, error) {
@@ -260,10 +376,14 @@ func (s *Service) transactionsByAlchemy(address common.Address) (common.Transact
 
 // GetContract returns a contract object of given address.
 func (s *Service) GetContract(address common.Address) (*Contract, error) {
+	return s.getContract(address, 0)
+}
+
+// getContract returns contract of address, which is the implementation of
+// depth proxies if depth > 0.
+func (s *Service) getContract(address common.Address, depth int) (*Contract, error) {
 	// return cached contract if exists
-	if contract, found := s.GetCache(address, TypeContract); found {
-		c := contract.(*Contract)
-		c.ClearCache()
+	if c, found := s.getCachedContract(address); found {
 		return c, nil
 	}
 
@@ -272,36 +392,71 @@ func (s *Service) GetContract(address common.Address) (*Contract, error) {
 		return nil, err
 	}
 
-	return s.ToContract(account)
+	return s.toContract(account, depth)
 }
 
-// GetSigner returns a signer which can sign transactions
-func (s *Service) GetSigner(privateKey string) (*Signer, error) {
-	privKey, err := crypto.HexToECDSA(conv.Trim0xPrefix(privateKey))
-	if err != nil {
-		return nil, errors.WithStack(err)
+// getCachedContract returns cached contract of address, whose artifact is
+// matched again if project directory has changed.
+func (s *Service) getCachedContract(address common.Address) (*Contract, bool) {
+	cached, found := s.GetCache(address, TypeContract)
+	if !found {
+		return nil, false
 	}
 
-	// only EOA can have private key
-	addr := crypto.PubkeyToAddress(privKey.PublicKey)
-	account := &Account{
-		service: s,
-		address: addr,
+	c := cached.(*Contract)
+	c.ClearCache()
+	if c.artifactVersion > 0 && s.matchArtifact(c) {
+		if err := s.resolveProxy(c, 0); err != nil {
+			log.Warn("Cannot resolve implementation of proxy", "address", address, "error", err)
+		}
+	}
+	return c, true
+}
+
+// loadRegisteredABI imports ABI of contract from registry, returns false if
+// it is not registered, or the entry holds a storage layout only.
+func (s *Service) loadRegisteredABI(chainId string, contract *Contract) (bool, error) {
+	abiJson, found, err := s.registry.Get(chainId, contract.address)
+	if err != nil || !found {
+		return false, err
+	}
+
+	if err := contract.ImportJSON(abiJson); err != nil {
+		return false, err
+	}
+	return contract.HasABI(), nil
+}
+
+// GetSigner returns a signer which can sign transactions. Either a private
+// key or a mnemonic can be given, for the latter the first derived account
+// is used.
+func (s *Service) GetSigner(privateKey string) (*KeySigner, error) {
+	if IsMnemonic(privateKey) {
+		account, err := s.GetHDSigner(privateKey, "", 0)
+		if err != nil {
+			return nil, err
+		}
+		return account.KeySigner, nil
 	}
 
-	signer := &Signer{
-		Account:    account,
-		PrivateKey: privKey,
+	privKey, err := crypto.HexToECDSA(conv.Trim0xPrefix(privateKey))
+	if err != nil {
+		// do not wrap the original error, which may contain part of the key
+		return nil, errors.New("Invalid private key")
 	}
-	return signer, nil
+
+	// only EOA can have private key
+	return newKeySigner(s, privKey), nil
 }
 
 // ToContract upgrade an account object to a contract.
 func (s *Service) ToContract(account *Account) (*Contract, error) {
+	return s.toContract(account, 0)
+}
+
+func (s *Service) toContract(account *Account, depth int) (*Contract, error) {
 	// return cached contract if exists
-	if contract, found := s.GetCache(account.address, TypeContract); found {
-		c := contract.(*Contract)
-		c.ClearCache()
+	if c, found := s.getCachedContract(account.address); found {
 		return c, nil
 	}
 
@@ -309,27 +464,51 @@ func (s *Service) ToContract(account *Account) (*Contract, error) {
 		return nil, errors.Errorf("Address %s is not a contract account", account.address.Hex())
 	}
 
-	var contract *Contract
+	contract := &Contract{
+		Account: account,
+	}
 
-	if s.GetNetwork().NetType() == TypeDevnet {
-		contract = &Contract{
-			Account: account,
-		}
-	} else {
-		source, abi, err := s.esclient.GetSourceCode(account.address)
-		if err != nil {
-			return nil, err
-		}
+	// ABI in registry takes precedence over Etherscan
+	network := s.GetNetwork()
+	registered, err := s.loadRegisteredABI(network.ChainId.String(), contract)
+	if err != nil {
+		log.Warn("Cannot load ABI from registry", "address", account.address, "error", err)
+	}
 
-		contract = &Contract{
-			Account: account,
-			abi:     abi,
-			source:  source,
+	if !registered {
+		if network.NetType() == TypeDevnet {
+			// find ABI in artifacts of local project, keeping storage layout
+			// in registry if artifact has none
+			layout := contract.layout
+			s.matchArtifact(contract)
+			if contract.layout == nil {
+				contract.layout = layout
+			}
+		} else {
+			source, abi, err := s.esclient.GetSourceCode(account.address)
+			if err != nil {
+				return nil, err
+			}
+
+			contract.abi = abi
+			contract.source = source
 		}
 	}
 
-	// populate cache
-	s.SetCache(account.address, TypeContract, contract, cache.NoExpiration)
+	// proxies pointing to each other are not followed endlessly, the
+	// contract at the end is returned with its own ABI but not cached, as it
+	// is not resolved
+	if depth >= maxProxyDepth {
+		log.Debug("Too many proxies to resolve", "address", account.address)
+		return contract, nil
+	}
 
+	// merge ABI of implementation if contract is a proxy, before populating
+	// cache so that a cached contract is never changed by resolving
+	if err := s.resolveProxy(contract, depth); err != nil {
+		log.Warn("Cannot resolve implementation of proxy", "address", account.address, "error", err)
+	}
+
+	s.SetCache(account.address, TypeContract, contract, cache.NoExpiration)
 	return contract, nil
 }
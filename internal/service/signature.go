@@ -0,0 +1,327 @@
+package service
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/pkg/errors"
+)
+
+//go:embed data/signatures.txt
+var builtinSignatures string
+
+// SignatureDB maps function selectors and event topics to their signatures,
+// so that calldata and logs can be decoded without ABI. As selectors may
+// collide, a selector can have several candidates.
+type SignatureDB struct {
+	functions map[[4]byte][]*abi.Method
+	events    map[common.Hash][]*abi.Event
+	known     map[string]bool
+}
+
+// GuessedCall is calldata decoded by signature database.
+type GuessedCall struct {
+	Method     *abi.Method
+	Args       []any
+	Candidates []string // all signatures of the selector, including Method's
+}
+
+// NewSignatureDB creates a signature database of well-known signatures.
+func NewSignatureDB() *SignatureDB {
+	db := &SignatureDB{
+		functions: make(map[[4]byte][]*abi.Method),
+		events:    make(map[common.Hash][]*abi.Event),
+		known:     make(map[string]bool),
+	}
+	if err := db.Load(strings.NewReader(builtinSignatures)); err != nil {
+		log.Error("Cannot parse builtin signatures", "error", err)
+		common.Exit("Cannot parse builtin signatures: %v", err)
+	}
+	return db
+}
+
+// LoadFile extends database by signatures in file, which is ignored if not exists.
+func (db *SignatureDB) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return errors.WithStack(err)
+	}
+	defer file.Close()
+
+	return db.Load(file)
+}
+
+// Load extends database by signatures, one per line. Each line is either
+// `function name(types)` or `event Name(types)`, where `function` can be
+// omitted. Empty lines and lines starting with `#` are ignored.
+func (db *SignatureDB) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := db.Add(line); err != nil {
+			return errors.Wrapf(err, "line %d", lineNo)
+		}
+	}
+	return errors.WithStack(scanner.Err())
+}
+
+// Add adds a signature to database, duplicates are ignored.
+func (db *SignatureDB) Add(signature string) error {
+	kind, sig := "function", strings.TrimSpace(signature)
+	if fields := strings.Fields(sig); len(fields) > 1 && (fields[0] == "function" || fields[0] == "event") {
+		kind, sig = fields[0], strings.TrimSpace(strings.TrimPrefix(sig, fields[0]))
+	}
+
+	name, inputs, err := parseSignature(sig)
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case "event":
+		event := abi.NewEvent(name, name, false, inputs)
+		if db.known["event "+event.Sig+indexedMarks(inputs)] {
+			return nil
+		}
+		db.known["event "+event.Sig+indexedMarks(inputs)] = true
+		db.events[event.ID] = append(db.events[event.ID], &event)
+	default:
+		method := abi.NewMethod(name, name, abi.Function, "", false, false, inputs, nil)
+		if db.known["function "+method.Sig] {
+			return nil
+		}
+		db.known["function "+method.Sig] = true
+		selector := [4]byte{}
+		copy(selector[:], method.ID)
+		db.functions[selector] = append(db.functions[selector], &method)
+	}
+	return nil
+}
+
+// LookupFunction returns functions of selector, which is the first 4 bytes of calldata.
+func (db *SignatureDB) LookupFunction(selector []byte) []*abi.Method {
+	if len(selector) < 4 {
+		return nil
+	}
+	key := [4]byte{}
+	copy(key[:], selector[:4])
+	return db.functions[key]
+}
+
+// LookupEvent returns events of topic, which is the first topic of log.
+func (db *SignatureDB) LookupEvent(topic common.Hash) []*abi.Event {
+	return db.events[topic]
+}
+
+// GuessCalldata decodes calldata by the first function of its selector,
+// that can decode it. It returns nil if none of them can.
+func (db *SignatureDB) GuessCalldata(data []byte) *GuessedCall {
+	methods := db.LookupFunction(data)
+
+	candidates := make([]string, len(methods))
+	for i, m := range methods {
+		candidates[i] = m.Sig
+	}
+
+	for _, m := range methods {
+		args, err := m.Inputs.Unpack(data[4:])
+		if err != nil || !sameEncoding(m.Inputs, args, data[4:]) {
+			continue
+		}
+		return &GuessedCall{Method: m, Args: args, Candidates: candidates}
+	}
+	return nil
+}
+
+// GuessLog decodes log by the first event of its topic, that can decode it.
+// It returns candidate signatures as well.
+func (db *SignatureDB) GuessLog(l *types.Log) (*abi.Event, []any, []string) {
+	if len(l.Topics) == 0 {
+		return nil, nil, nil
+	}
+
+	events := db.LookupEvent(l.Topics[0])
+	candidates := make([]string, len(events))
+	for i, e := range events {
+		candidates[i] = e.Sig + indexedMarks(e.Inputs)
+	}
+
+	for _, e := range events {
+		args, err := parseLog(e, l)
+		if err != nil {
+			continue
+		}
+		packed, err := e.Inputs.NonIndexed().Pack(nonIndexedArgs(e, args)...)
+		if err != nil || len(packed) != len(l.Data) {
+			continue
+		}
+		return e, args, candidates
+	}
+	return nil, nil, candidates
+}
+
+// GetSignatureDB returns database of function and event signatures.
+func (s *Service) GetSignatureDB() *SignatureDB {
+	return s.signatures
+}
+
+// sameEncoding returns true if args encode to data, which rejects a
+// candidate that decodes data of another layout by chance.
+func sameEncoding(inputs abi.Arguments, args []any, data []byte) bool {
+	packed, err := inputs.Pack(args...)
+	return err == nil && len(packed) == len(data)
+}
+
+func nonIndexedArgs(event *abi.Event, args []any) []any {
+	result := make([]any, 0, len(args))
+	for i, input := range event.Inputs {
+		if !input.Indexed {
+			result = append(result, args[i])
+		}
+	}
+	return result
+}
+
+// indexedMarks describes which arguments are indexed, as it is not part of
+// event signature, e.g. " [indexed: 0, 1]".
+func indexedMarks(inputs abi.Arguments) string {
+	marks := make([]string, 0)
+	for i, input := range inputs {
+		if input.Indexed {
+			marks = append(marks, fmt.Sprint(i))
+		}
+	}
+	if len(marks) == 0 {
+		return ""
+	}
+	return " [indexed: " + strings.Join(marks, ", ") + "]"
+}
+
+// parseSignature parses signature like `transfer(address,uint256)` into name
+// and arguments. Tuples are written in parentheses, e.g. `f((uint256,address)[])`,
+// and arguments of events may be followed by `indexed`.
+func parseSignature(sig string) (string, abi.Arguments, error) {
+	start := strings.Index(sig, "(")
+	if start <= 0 || !strings.HasSuffix(sig, ")") {
+		return "", nil, errors.Errorf("Invalid signature %s", sig)
+	}
+	name := strings.TrimSpace(sig[:start])
+
+	params, err := splitParams(sig[start+1 : len(sig)-1])
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "Invalid signature %s", sig)
+	}
+
+	args := make(abi.Arguments, len(params))
+	for i, param := range params {
+		indexed := false
+		if fields := strings.Fields(param); len(fields) == 2 && fields[1] == "indexed" {
+			param, indexed = fields[0], true
+		}
+
+		marshaling, err := parseParamType(param)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "Invalid signature %s", sig)
+		}
+		typ, err := abi.NewType(marshaling.Type, "", marshaling.Components)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "Invalid signature %s", sig)
+		}
+		args[i] = abi.Argument{Name: fmt.Sprintf("arg%d", i), Type: typ, Indexed: indexed}
+	}
+	return name, args, nil
+}
+
+// parseParamType converts a type in signature to the form accepted by
+// abi.NewType, where tuples are given by components.
+func parseParamType(param string) (abi.ArgumentMarshaling, error) {
+	param = strings.TrimSpace(param)
+	if !strings.HasPrefix(param, "(") {
+		if param == "" {
+			return abi.ArgumentMarshaling{}, errors.New("empty type")
+		}
+		return abi.ArgumentMarshaling{Type: param}, nil
+	}
+
+	end := indexOfClosingParen(param)
+	if end < 0 {
+		return abi.ArgumentMarshaling{}, errors.Errorf("unbalanced parentheses in %s", param)
+	}
+	params, err := splitParams(param[1:end])
+	if err != nil {
+		return abi.ArgumentMarshaling{}, err
+	}
+
+	components := make([]abi.ArgumentMarshaling, len(params))
+	for i, p := range params {
+		component, err := parseParamType(p)
+		if err != nil {
+			return abi.ArgumentMarshaling{}, err
+		}
+		component.Name = fmt.Sprintf("field%d", i)
+		components[i] = component
+	}
+	return abi.ArgumentMarshaling{Type: "tuple" + param[end+1:], Components: components}, nil
+}
+
+// splitParams splits parameters by commas which are not inside parentheses.
+func splitParams(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return []string{}, nil
+	}
+
+	params := make([]string, 0)
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, errors.New("unbalanced parentheses")
+			}
+		case ',':
+			if depth == 0 {
+				params = append(params, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, errors.New("unbalanced parentheses")
+	}
+	return append(params, s[start:]), nil
+}
+
+func indexOfClosingParen(s string) int {
+	depth := 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dyng/ramen/internal/common"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignatureDB_GuessCalldata(t *testing.T) {
+	// prepare
+	db := NewSignatureDB()
+	to := gcommon.HexToAddress("0xFABB0ac9d68B0B445fB7357272Ff202C5651694a")
+	data := hexutil.MustDecode("0xa9059cbb" +
+		"000000000000000000000000fabb0ac9d68b0b445fb7357272ff202c5651694a" +
+		"0000000000000000000000000000000000000000000000000de0b6b3a7640000")
+
+	// process
+	guessed := db.GuessCalldata(data)
+
+	// verify
+	if assert.NotNil(t, guessed) {
+		assert.Equal(t, "transfer(address,uint256)", guessed.Method.Sig)
+		assert.Equal(t, []string{"transfer(address,uint256)"}, guessed.Candidates)
+		assert.Equal(t, to, guessed.Args[0])
+		assert.Equal(t, big.NewInt(1e18), guessed.Args[1])
+	}
+	assert.Nil(t, db.GuessCalldata(hexutil.MustDecode("0xa9059cbb00")), "malformed arguments")
+	assert.Nil(t, db.GuessCalldata(hexutil.MustDecode("0xdeadbeef")), "unknown selector")
+	assert.Nil(t, db.GuessCalldata([]byte{0x01}))
+}
+
+func TestSignatureDB_Ambiguous(t *testing.T) {
+	// prepare
+	db := NewSignatureDB()
+	path := filepath.Join(t.TempDir(), "signatures.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("# colliding with burn(uint256)\ncollate_propagate_storage(bytes16)\n"), 0600))
+	assert.NoError(t, db.LoadFile(path))
+	data := hexutil.MustDecode("0x42966c68" + "0000000000000000000000000000000000000000000000000000000000000001")
+
+	// process
+	guessed := db.GuessCalldata(data)
+
+	// verify
+	if assert.NotNil(t, guessed) {
+		assert.Equal(t, "burn(uint256)", guessed.Method.Sig)
+		assert.Equal(t, []string{"burn(uint256)", "collate_propagate_storage(bytes16)"}, guessed.Candidates)
+	}
+}
+
+func TestSignatureDB_GuessLog(t *testing.T) {
+	// prepare
+	db := NewSignatureDB()
+	topic0 := gcommon.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
+	from := gcommon.HexToHash("0x000000000000000000000000fabb0ac9d68b0b445fb7357272ff202c5651694a")
+	to := gcommon.HexToHash("0x0000000000000000000000001c85638e118b37167e9298c2268758e058ddfda0")
+
+	tests := []struct {
+		name    string
+		log     *types.Log
+		indexed []bool
+	}{
+		{
+			name:    "erc20",
+			log:     &types.Log{Topics: []common.Hash{topic0, from, to}, Data: gcommon.BigToHash(big.NewInt(100)).Bytes()},
+			indexed: []bool{true, true, false},
+		},
+		{
+			name:    "erc721",
+			log:     &types.Log{Topics: []common.Hash{topic0, from, to, gcommon.BigToHash(big.NewInt(7))}},
+			indexed: []bool{true, true, true},
+		},
+	}
+
+	for _, test := range tests {
+		// process
+		event, args, candidates := db.GuessLog(test.log)
+
+		// verify
+		if assert.NotNil(t, event, test.name) {
+			assert.Equal(t, "Transfer", event.Name, test.name)
+			for i, input := range event.Inputs {
+				assert.Equal(t, test.indexed[i], input.Indexed, test.name)
+			}
+			assert.Len(t, args, 3, test.name)
+		}
+		assert.Len(t, candidates, 2, test.name)
+	}
+}
+
+func TestParseSignature(t *testing.T) {
+	// process
+	name, args, err := parseSignature("exactInput((bytes,address,uint256,uint256)[],uint8)")
+
+	// verify
+	assert.NoError(t, err)
+	assert.Equal(t, "exactInput", name)
+	assert.Equal(t, "(bytes,address,uint256,uint256)[]", args[0].Type.String())
+	assert.Equal(t, "uint8", args[1].Type.String())
+
+	for _, sig := range []string{"transfer", "transfer(address", "f((uint256)", "f(foo)", "(address)"} {
+		_, _, err := parseSignature(sig)
+		assert.Error(t, err, sig)
+	}
+}
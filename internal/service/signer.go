@@ -2,58 +2,311 @@ package service
 
 import (
 	"crypto/ecdsa"
+	"math/big"
 
 	"github.com/dyng/ramen/internal/common"
+	"github.com/dyng/ramen/internal/provider"
 	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/pkg/errors"
 )
 
-type Signer struct {
-	*Account
+const (
+	// BackendKey is a signer holding a raw private key in memory
+	BackendKey = "key"
+	// BackendKeystore is a signer holding a private key unlocked from keystore
+	BackendKeystore = "keystore"
+	// BackendExternal is a signer whose key lives in an external signer, e.g. Clef
+	BackendExternal = "external"
+)
+
+// Signer signs and sends transactions on behalf of an account. How
+// transactions are signed depends on its backend.
+type Signer interface {
+	GetAddress() common.Address
+	GetBalance() common.BigInt
+	GetBalanceForce() (common.BigInt, error)
+	UpdateBalance() bool
+
+	// Backend returns the kind of backend holding the key
+	Backend() string
+	// SignTx signs a transaction for given chain
+	SignTx(txn *types.Transaction, chainId common.BigInt) (*types.Transaction, error)
+
+	TransferTo(address common.Address, amount common.BigInt) (common.Hash, error)
+	CallContract(address common.Address, abi *abi.ABI, opts *common.CallOpts, method string, args ...any) (common.Hash, error)
+	PrepareTransfer(address common.Address, amount common.BigInt) (*common.TxnRequest, error)
+	PrepareCall(address common.Address, abi *abi.ABI, opts *common.CallOpts, method string, args ...any) (*common.TxnRequest, error)
+	PrepareTokenTransfer(token *Token, to common.Address, amount common.BigInt) (*common.TxnRequest, error)
+	Send(txnReq *common.TxnRequest) (common.Hash, error)
+	SpeedUp(hash common.Hash) (common.Hash, error)
+	Cancel(hash common.Hash) (common.Hash, error)
+	GetPendingTxns() []*PendingTxn
+	SyncPendingTxns(block *common.Block)
+}
+
+// KeySigner signs transactions with a private key in memory.
+type KeySigner struct {
+	*signerBase
 	PrivateKey *ecdsa.PrivateKey
 }
 
-func (s *Signer) TransferTo(address common.Address, amount common.BigInt) (common.Hash, error) {
-	gasPrice, err := s.service.provider.GetGasPrice()
+func newKeySigner(service *Service, privKey *ecdsa.PrivateKey) *KeySigner {
+	s := &KeySigner{PrivateKey: privKey}
+	s.signerBase = newSignerBase(service, crypto.PubkeyToAddress(privKey.PublicKey), s.SignTx)
+	return s
+}
+
+// Backend implements Signer
+func (s *KeySigner) Backend() string {
+	return BackendKey
+}
+
+// SignTx implements Signer
+func (s *KeySigner) SignTx(txn *types.Transaction, chainId common.BigInt) (*types.Transaction, error) {
+	signed, err := types.SignTx(txn, types.LatestSignerForChainID(chainId), s.PrivateKey)
+	return signed, errors.WithStack(err)
+}
+
+// KeystoreSigner signs transactions with a private key unlocked from keystore.
+type KeystoreSigner struct {
+	*KeySigner
+	Path string
+}
+
+// Backend implements Signer
+func (s *KeystoreSigner) Backend() string {
+	return BackendKeystore
+}
+
+// signerBase implements operations shared by all backends, e.g. preparing
+// requests and managing nonces. Transactions are signed by the given function
+// of backend.
+type signerBase struct {
+	*Account
+	nonces *NonceManager
+	sign   provider.SignFunc
+}
+
+func newSignerBase(service *Service, address common.Address, sign provider.SignFunc) *signerBase {
+	return &signerBase{
+		Account: &Account{
+			service: service,
+			address: address,
+		},
+		nonces: NewNonceManager(service, address),
+		sign:   sign,
+	}
+}
+
+// nonceSharer is a signer whose nonce state can be handed over to another
+// signer of the same account, e.g. when the account signs in again.
+type nonceSharer interface {
+	nonceManager() *NonceManager
+	setNonceManager(nonces *NonceManager)
+}
+
+func (s *signerBase) nonceManager() *NonceManager {
+	return s.nonces
+}
+
+func (s *signerBase) setNonceManager(nonces *NonceManager) {
+	s.nonces = nonces
+}
+
+// TransferTo sends ethers to given address.
+func (s *signerBase) TransferTo(address common.Address, amount common.BigInt) (common.Hash, error) {
+	txnReq, err := s.PrepareTransfer(address, amount)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return s.Send(txnReq)
+}
+
+// CallContract sends a transaction calling given method of contract.
+func (s *signerBase) CallContract(address common.Address, abi *abi.ABI, opts *common.CallOpts, method string, args ...any) (common.Hash, error) {
+	txnReq, err := s.PrepareCall(address, abi, opts, method, args...)
 	if err != nil {
 		return common.Hash{}, err
 	}
+	return s.Send(txnReq)
+}
+
+// PrepareTransfer builds a request of sending ethers, without signing it.
+func (s *signerBase) PrepareTransfer(address common.Address, amount common.BigInt) (*common.TxnRequest, error) {
+	fee, err := s.service.provider.GetFeeData()
+	if err != nil {
+		return nil, err
+	}
 
 	txnReq := &common.TxnRequest{
-		PrivateKey: s.PrivateKey,
-		To:         &address,
-		Value:      amount,
-		GasLimit:   params.TxGas,
-		GasPrice:   gasPrice,
+		From:     s.address,
+		To:       &address,
+		Value:    amount,
+		GasLimit: params.TxGas,
 	}
+	txnReq.SetFee(fee)
 
-	return s.service.provider.SendTransaction(txnReq)
+	return txnReq, nil
 }
 
-func (s *Signer) CallContract(address common.Address, abi *abi.ABI, method string, args ...any) (common.Hash, error) {
-	gasPrice, err := s.service.provider.GetGasPrice()
+// PrepareCall builds a request of calling given method of contract, without
+// signing it. Gas limit is estimated unless overridden by opts.
+func (s *signerBase) PrepareCall(address common.Address, abi *abi.ABI, opts *common.CallOpts, method string, args ...any) (*common.TxnRequest, error) {
+	fee, err := s.service.provider.GetFeeData()
 	if err != nil {
-		return common.Hash{}, err
+		return nil, err
 	}
 
 	input, err := abi.Pack(method, args...)
 	if err != nil {
-		return common.Hash{}, errors.WithStack(err)
+		return nil, errors.WithStack(err)
+	}
+
+	txnReq := &common.TxnRequest{
+		From:  s.address,
+		To:    &address,
+		Value: big.NewInt(0),
+		Data:  input,
 	}
+	txnReq.SetFee(fee)
+	if err := txnReq.Override(opts); err != nil {
+		return nil, err
+	}
+
+	if txnReq.GasLimit == 0 {
+		gasLimit, err := s.service.provider.EstimateGas(address, s.address, txnReq.Value, input)
+		if err != nil {
+			return nil, decodeCallError(err, abi)
+		}
+		txnReq.GasLimit = gasLimit
+	}
+
+	return txnReq, nil
+}
 
-	gasLimit, err := s.service.provider.EstimateGas(address, s.address, input)
+// PrepareTokenTransfer builds a request of sending ERC-20 tokens, after
+// checking that signer holds enough tokens.
+func (s *signerBase) PrepareTokenTransfer(token *Token, to common.Address, amount common.BigInt) (*common.TxnRequest, error) {
+	balance, err := s.service.GetTokenBalance(token.Address, s.address)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Cmp(amount) < 0 {
+		held := &TokenBalance{Token: token, Balance: balance}
+		return nil, errors.Errorf("insufficient balance of %s, only %s held", token.Symbol, held.Amount())
+	}
+
+	return s.PrepareCall(token.Address, &ERC20ABI, nil, "transfer", to, amount)
+}
+
+// Send allocates a nonce for the request, then signs and sends it.
+func (s *signerBase) Send(txnReq *common.TxnRequest) (common.Hash, error) {
+	nonce, err := s.nonces.Next()
 	if err != nil {
 		return common.Hash{}, err
 	}
+	txnReq.Nonce = &nonce
 
-	txnReq := &common.TxnRequest{
-		PrivateKey: s.PrivateKey,
-		To:         &address,
-		GasLimit:   gasLimit,
-		GasPrice:   gasPrice,
-		Data:       input,
+	hash, err := s.service.provider.SendTransaction(txnReq, s.sign)
+	if err != nil {
+		// the reserved nonce may not be used, resynchronize next time
+		s.nonces.Reset()
+		return hash, err
+	}
+
+	s.nonces.Track(hash, txnReq)
+	return hash, nil
+}
+
+// SpeedUp replaces a pending transaction with the same one but higher fee.
+func (s *signerBase) SpeedUp(hash common.Hash) (common.Hash, error) {
+	txn, err := s.findPending(hash)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	txnReq := *txn.Request
+	if err := s.bumpFee(&txnReq); err != nil {
+		return common.Hash{}, err
+	}
+
+	return s.replace(&txnReq)
+}
+
+// Cancel replaces a pending transaction with a zero-value transfer to
+// signer itself, using the same nonce and higher fee.
+func (s *signerBase) Cancel(hash common.Hash) (common.Hash, error) {
+	txn, err := s.findPending(hash)
+	if err != nil {
+		return common.Hash{}, err
 	}
 
-	return s.service.provider.SendTransaction(txnReq)
+	self := s.address
+	txnReq := common.TxnRequest{
+		From:      s.address,
+		Nonce:     txn.Request.Nonce,
+		To:        &self,
+		Value:     big.NewInt(0),
+		GasLimit:  params.TxGas,
+		GasPrice:  txn.Request.GasPrice,
+		GasFeeCap: txn.Request.GasFeeCap,
+		GasTipCap: txn.Request.GasTipCap,
+	}
+	if err := s.bumpFee(&txnReq); err != nil {
+		return common.Hash{}, err
+	}
+
+	return s.replace(&txnReq)
+}
+
+// GetPendingTxns returns transactions submitted by this signer, the latest first.
+func (s *signerBase) GetPendingTxns() []*PendingTxn {
+	return s.nonces.Transactions()
+}
+
+// SyncPendingTxns updates status of submitted transactions with a new block.
+func (s *signerBase) SyncPendingTxns(block *common.Block) {
+	s.nonces.Sync(block)
+}
+
+// replace sends a request reusing the nonce of a pending transaction.
+func (s *signerBase) replace(txnReq *common.TxnRequest) (common.Hash, error) {
+	hash, err := s.service.provider.SendTransaction(txnReq, s.sign)
+	if err != nil {
+		return hash, err
+	}
+
+	s.nonces.Track(hash, txnReq)
+	return hash, nil
+}
+
+func (s *signerBase) findPending(hash common.Hash) (*PendingTxn, error) {
+	txn, ok := s.nonces.Find(hash)
+	if !ok {
+		return nil, errors.Errorf("Transaction %s is not sent by signer %s", hash.Hex(), s.address.Hex())
+	}
+	if !txn.IsPending() {
+		return nil, errors.Errorf("Transaction %s is already %s", hash.Hex(), txn.Status)
+	}
+	return txn, nil
+}
+
+// bumpFee raises fee of a request for replacement, respecting the current
+// suggested fee if it is even higher.
+func (s *signerBase) bumpFee(txnReq *common.TxnRequest) error {
+	fee, err := s.service.provider.GetFeeData()
+	if err != nil {
+		return err
+	}
+
+	if txnReq.IsDynamicFee() {
+		txnReq.GasTipCap = maxFee(bumpFee(txnReq.GasTipCap), fee.GasTipCap)
+		txnReq.GasFeeCap = maxFee(bumpFee(txnReq.GasFeeCap), fee.GasFeeCap)
+	} else {
+		txnReq.GasPrice = maxFee(bumpFee(txnReq.GasPrice), fee.GasPrice)
+	}
+	return nil
 }
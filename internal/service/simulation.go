@@ -0,0 +1,183 @@
+package service
+
+import (
+	"math/big"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/dyng/ramen/internal/provider"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+// Simulation is the result of executing a transaction request without
+// submitting it.
+type Simulation struct {
+	Err            error       // why it fails, nil if succeeded
+	Method         *abi.Method // nil if calldata cannot be decoded
+	Outputs        []any       // outputs of Method, nil if failed
+	GasUsed        uint64      // estimated gas, or gas limit if cannot be estimated
+	BalanceChanges []*BalanceChange
+	StateDiff      *common.StateDiff // nil if provider does not support debug_traceCall
+}
+
+// Succeeded returns true if the simulated transaction does not fail.
+func (s *Simulation) Succeeded() bool {
+	return s.Err == nil
+}
+
+// BalanceChange is the change of an account's balance made by a transaction.
+type BalanceChange struct {
+	Address common.Address
+	Before  common.BigInt
+	After   common.BigInt
+	Exact   bool // false if estimated with max fee, as state diff is unknown
+}
+
+// Delta returns how much balance increases, negative if it decreases.
+func (c *BalanceChange) Delta() common.BigInt {
+	return new(big.Int).Sub(c.After, c.Before)
+}
+
+// Simulate executes a transaction request by eth_call at pending state, and
+// finds out balance changes of its sender and receiver. State diff is traced
+// by debug_traceCall if provider supports it. Everything is queried on the
+// pending state, so that transactions queued by sender are taken into
+// account, unless provider cannot trace on it, in which case everything is
+// queried on the latest block instead. Calldata and revert data are decoded
+// by contractABI if given.
+func (s *Service) Simulate(txnReq *common.TxnRequest, contractABI *abi.ABI) (*Simulation, error) {
+	sim := &Simulation{GasUsed: txnReq.GasLimit}
+
+	// trace at first, to find out which state all the queries should be on
+	block := provider.PendingBlock
+	diff, err := s.provider.TraceCallStateDiff(txnReq, block)
+	if errors.Is(err, provider.ErrPendingNotSupported) {
+		block = nil
+		diff, err = s.provider.TraceCallStateDiff(txnReq, block)
+	}
+	if err != nil && !errors.Is(err, ErrTraceNotSupported) {
+		log.Warn("Cannot trace state diff of simulation", "error", err)
+	}
+
+	output, err := s.provider.CallTransaction(txnReq, block)
+	if err != nil {
+		// failures of execution are reported by node as rpc errors
+		var rpcErr rpc.Error
+		if !errors.As(err, &rpcErr) {
+			return nil, err
+		}
+		sim.Err = decodeCallError(err, contractABI)
+	}
+
+	if contractABI != nil && len(txnReq.Data) >= 4 {
+		if m, err := contractABI.MethodById(txnReq.Data[:4]); err == nil {
+			sim.Method = m
+			if sim.Succeeded() {
+				outputs, err := m.Outputs.Unpack(output)
+				if err != nil {
+					log.Warn("Cannot decode output of simulation", "method", m.Name, "error", err)
+				} else {
+					sim.Outputs = outputs
+				}
+			}
+		}
+	}
+
+	if sim.Succeeded() && txnReq.To != nil {
+		gasUsed, err := s.provider.EstimateTransactionGas(txnReq, block)
+		if err != nil {
+			log.Warn("Cannot estimate gas of simulation", "error", err)
+		} else {
+			sim.GasUsed = gasUsed
+		}
+	}
+
+	if sim.Succeeded() {
+		sim.StateDiff = diff
+	}
+
+	changes, err := s.balanceChanges(txnReq, sim, block)
+	if err != nil {
+		return nil, err
+	}
+	sim.BalanceChanges = changes
+
+	return sim, nil
+}
+
+// balanceChanges returns balance changes of sender and receiver. They are
+// taken from state diff if traced, otherwise estimated by value and max fee.
+func (s *Service) balanceChanges(txnReq *common.TxnRequest, sim *Simulation, block common.BigInt) ([]*BalanceChange, error) {
+	addrs := []common.Address{txnReq.From}
+	if txnReq.To != nil && *txnReq.To != txnReq.From {
+		addrs = append(addrs, *txnReq.To)
+	}
+
+	value := txnReq.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	changes := make([]*BalanceChange, len(addrs))
+	for i, addr := range addrs {
+		if change, ok := balanceChangeInDiff(sim.StateDiff, addr); ok {
+			changes[i] = change
+			continue
+		}
+
+		before, err := s.provider.GetBalanceAt(addr, block)
+		if err != nil {
+			return nil, err
+		}
+		after := new(big.Int).Set(before)
+		if sim.Succeeded() {
+			if addr == txnReq.From {
+				after.Sub(after, value)
+			} else {
+				after.Add(after, value)
+			}
+		}
+		if addr == txnReq.From {
+			// the fee is paid even if transaction fails
+			fee := new(big.Int).Mul(maxGasPrice(txnReq), new(big.Int).SetUint64(sim.GasUsed))
+			after.Sub(after, fee)
+		}
+		changes[i] = &BalanceChange{Address: addr, Before: before, After: after}
+	}
+	return changes, nil
+}
+
+// balanceChangeInDiff finds balance change of address in state diff, where
+// an account is absent in post state if its balance is not changed.
+func balanceChangeInDiff(diff *common.StateDiff, addr common.Address) (*BalanceChange, bool) {
+	if diff == nil {
+		return nil, false
+	}
+	pre, ok := diff.Pre[addr]
+	if !ok || pre.Balance == nil {
+		return nil, false
+	}
+
+	change := &BalanceChange{
+		Address: addr,
+		Before:  pre.Balance.ToInt(),
+		After:   pre.Balance.ToInt(),
+		Exact:   true,
+	}
+	if post, ok := diff.Post[addr]; ok && post.Balance != nil {
+		change.After = post.Balance.ToInt()
+	}
+	return change, true
+}
+
+func maxGasPrice(txnReq *common.TxnRequest) common.BigInt {
+	if txnReq.IsDynamicFee() {
+		return txnReq.GasFeeCap
+	}
+	if txnReq.GasPrice != nil {
+		return txnReq.GasPrice
+	}
+	return new(big.Int)
+}
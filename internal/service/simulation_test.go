@@ -0,0 +1,163 @@
+package service
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubSimEth struct {
+	output hexutil.Bytes
+	revert string // revert data, call succeeds if empty
+	block  string
+
+	estimateBlock string
+	balanceBlock  string
+}
+
+func (s *stubSimEth) Call(args map[string]any, block string) (hexutil.Bytes, error) {
+	s.block = block
+	if s.revert != "" {
+		return nil, &stubRevertError{data: s.revert}
+	}
+	return s.output, nil
+}
+
+func (s *stubSimEth) EstimateGas(args map[string]any, block string) hexutil.Uint64 {
+	s.estimateBlock = block
+	return 30000
+}
+
+func (s *stubSimEth) GetBalance(addr common.Address, block string) *hexutil.Big {
+	s.balanceBlock = block
+	return (*hexutil.Big)(big.NewInt(1e18))
+}
+
+type stubSimDebug struct {
+	diff  map[string]any
+	block string
+	err   error
+
+	pendingErr error // returned if traced on the pending state
+}
+
+func (s *stubSimDebug) TraceCall(args map[string]any, block string, config map[string]any) (map[string]any, error) {
+	s.block = block
+	if block == "pending" && s.pendingErr != nil {
+		return nil, s.pendingErr
+	}
+	return s.diff, s.err
+}
+
+func TestSimulate_StateDiff(t *testing.T) {
+	// prepare
+	contractABI, err := abi.JSON(strings.NewReader(testTokenABI))
+	assert.NoError(t, err)
+	token := gcommon.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	sender := gcommon.HexToAddress("0xFABB0ac9d68B0B445fB7357272Ff202C5651694a")
+	input, _ := contractABI.Pack("transfer", sender, big.NewInt(100))
+	output, _ := contractABI.Methods["transfer"].Outputs.Pack(true)
+
+	slot := gcommon.BigToHash(big.NewInt(1)).Hex()
+
+	eth := &stubSimEth{output: output}
+	debug := &stubSimDebug{diff: map[string]any{
+		"pre": map[string]any{
+			sender.Hex(): map[string]any{"balance": hexutil.EncodeBig(big.NewInt(9e18)), "nonce": 1},
+			token.Hex():  map[string]any{"storage": map[string]any{slot: gcommon.BigToHash(big.NewInt(100)).Hex()}},
+		},
+		"post": map[string]any{
+			sender.Hex(): map[string]any{"balance": hexutil.EncodeBig(big.NewInt(9e18 - 3e13)), "nonce": 2},
+			token.Hex():  map[string]any{"storage": map[string]any{slot: gcommon.Hash{}.Hex()}},
+		},
+	}}
+	serv := newStubService(t, map[string]any{"eth": eth, "debug": debug})
+	txnReq := &common.TxnRequest{From: sender, To: &token, Value: big.NewInt(0), Data: input, GasLimit: 50000, GasPrice: big.NewInt(1e9)}
+
+	// process
+	sim, err := serv.Simulate(txnReq, &contractABI)
+
+	// verify
+	assert.NoError(t, err)
+	assert.True(t, sim.Succeeded())
+	assert.Equal(t, "pending", eth.block)
+	assert.Equal(t, "pending", debug.block, "state diff should be traced on the same state as eth_call")
+	assert.Equal(t, "pending", eth.estimateBlock)
+	assert.Equal(t, "pending", eth.balanceBlock)
+	assert.Equal(t, "transfer", sim.Method.Name)
+	assert.Equal(t, []any{true}, sim.Outputs)
+	assert.Equal(t, uint64(30000), sim.GasUsed)
+	assert.NotNil(t, sim.StateDiff)
+	if assert.Len(t, sim.BalanceChanges, 2) {
+		assert.True(t, sim.BalanceChanges[0].Exact)
+		assert.Equal(t, big.NewInt(-3e13), sim.BalanceChanges[0].Delta())
+		// receiver's balance is not in state diff
+		assert.False(t, sim.BalanceChanges[1].Exact)
+		assert.Zero(t, sim.BalanceChanges[1].Delta().Sign())
+	}
+}
+
+func TestSimulate_Reverted(t *testing.T) {
+	// prepare
+	to := gcommon.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	sender := gcommon.HexToAddress("0xFABB0ac9d68B0B445fB7357272Ff202C5651694a")
+	eth := &stubSimEth{revert: testRevertString}
+	serv := newStubService(t, map[string]any{"eth": eth})
+	txnReq := &common.TxnRequest{From: sender, To: &to, Value: big.NewInt(1e17), GasLimit: 50000, GasPrice: big.NewInt(1e9)}
+
+	// process
+	sim, err := serv.Simulate(txnReq, nil)
+
+	// verify
+	assert.NoError(t, err)
+	assert.False(t, sim.Succeeded())
+	assert.Equal(t, "execution reverted: insufficient", sim.Err.Error())
+	assert.Nil(t, sim.StateDiff)
+	assert.Equal(t, uint64(50000), sim.GasUsed, "gas limit is used if not estimated")
+	if assert.Len(t, sim.BalanceChanges, 2) {
+		// only fee is paid by sender, as value is not transferred
+		assert.Equal(t, big.NewInt(-5e13), sim.BalanceChanges[0].Delta())
+		assert.Zero(t, sim.BalanceChanges[1].Delta().Sign())
+	}
+}
+
+func TestSimulate_PendingNotTraceable(t *testing.T) {
+	// prepare
+	to := gcommon.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	sender := gcommon.HexToAddress("0xFABB0ac9d68B0B445fB7357272Ff202C5651694a")
+	eth := &stubSimEth{}
+	debug := &stubSimDebug{
+		diff: map[string]any{
+			"pre":  map[string]any{sender.Hex(): map[string]any{"balance": hexutil.EncodeBig(big.NewInt(9e18))}},
+			"post": map[string]any{sender.Hex(): map[string]any{"balance": hexutil.EncodeBig(big.NewInt(9e18 - 1e17 - 21e12))}},
+		},
+		pendingErr: errors.New("tracing on top of pending is not supported"),
+	}
+	serv := newStubService(t, map[string]any{"eth": eth, "debug": debug})
+	txnReq := &common.TxnRequest{From: sender, To: &to, Value: big.NewInt(1e17), GasLimit: 21000, GasPrice: big.NewInt(1e9)}
+
+	// process
+	sim, err := serv.Simulate(txnReq, nil)
+
+	// verify
+	assert.NoError(t, err)
+	assert.True(t, sim.Succeeded())
+	assert.NotNil(t, sim.StateDiff)
+	assert.Equal(t, "latest", debug.block, "state diff should be traced on the latest block instead")
+	assert.Equal(t, "latest", eth.block, "everything should be queried on the same state as tracing")
+	assert.Equal(t, "latest", eth.estimateBlock)
+	assert.Equal(t, "latest", eth.balanceBlock)
+	if assert.Len(t, sim.BalanceChanges, 2) {
+		assert.True(t, sim.BalanceChanges[0].Exact)
+		assert.Equal(t, big.NewInt(-1e17-21e12), sim.BalanceChanges[0].Delta())
+		assert.False(t, sim.BalanceChanges[1].Exact)
+		assert.Equal(t, big.NewInt(1e17), sim.BalanceChanges[1].Delta())
+	}
+}
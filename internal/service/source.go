@@ -0,0 +1,144 @@
+package service
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// defaultSourceName is the name of source file if contract is verified as a
+// single file.
+const defaultSourceName = "Contract.sol"
+
+const (
+	ABIFunction = "function"
+	ABIEvent    = "event"
+	ABIError    = "error"
+)
+
+// SourceFile is a file of verified source code of contract.
+type SourceFile struct {
+	Name    string
+	Content string
+}
+
+// ABIEntry is a function, event or error defined in ABI.
+type ABIEntry struct {
+	Kind       string
+	Name       string
+	Signature  string // e.g. transfer(address,uint256)
+	Selector   string // 4-byte selector of function and error, topic of event
+	Mutability string // only for function
+}
+
+// GetSourceFiles splits source of this contract into files, which is in one
+// file if the contract is verified as a single file.
+func (c *Contract) GetSourceFiles() []SourceFile {
+	return ParseSourceFiles(c.source)
+}
+
+// GetABIEntries lists functions, events and errors of this contract's ABI.
+func (c *Contract) GetABIEntries() []ABIEntry {
+	if c.abi == nil {
+		return []ABIEntry{}
+	}
+	return ListABIEntries(c.abi)
+}
+
+// ParseSourceFiles parses source code returned by Etherscan. A multi-file
+// source is either a JSON object of files, or a solc standard JSON input
+// wrapped in an extra pair of braces.
+func ParseSourceFiles(source string) []SourceFile {
+	trimmed := strings.TrimSpace(source)
+	if trimmed == "" {
+		return []SourceFile{}
+	}
+
+	if strings.HasPrefix(trimmed, "{{") && strings.HasSuffix(trimmed, "}}") {
+		var input struct {
+			Sources map[string]sourceFileJSON `json:"sources"`
+		}
+		if err := json.Unmarshal([]byte(trimmed[1:len(trimmed)-1]), &input); err == nil {
+			return sortedSourceFiles(input.Sources)
+		}
+	} else if strings.HasPrefix(trimmed, "{") {
+		var files map[string]sourceFileJSON
+		if err := json.Unmarshal([]byte(trimmed), &files); err == nil {
+			return sortedSourceFiles(files)
+		}
+	}
+
+	return []SourceFile{{Name: defaultSourceName, Content: source}}
+}
+
+type sourceFileJSON struct {
+	Content string `json:"content"`
+}
+
+func sortedSourceFiles(files map[string]sourceFileJSON) []SourceFile {
+	result := make([]SourceFile, 0, len(files))
+	for name, file := range files {
+		result = append(result, SourceFile{Name: name, Content: file.Content})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// ListABIEntries lists functions, events and errors of ABI, each kind sorted
+// by name.
+func ListABIEntries(contractABI *abi.ABI) []ABIEntry {
+	entries := make([]ABIEntry, 0)
+
+	functions := make([]ABIEntry, 0, len(contractABI.Methods))
+	for _, m := range contractABI.Methods {
+		functions = append(functions, ABIEntry{
+			Kind:       ABIFunction,
+			Name:       m.Name,
+			Signature:  m.Sig,
+			Selector:   hexutil.Encode(m.ID),
+			Mutability: m.StateMutability,
+		})
+	}
+	entries = append(entries, sortedABIEntries(functions)...)
+
+	events := make([]ABIEntry, 0, len(contractABI.Events))
+	for _, e := range contractABI.Events {
+		events = append(events, ABIEntry{
+			Kind:      ABIEvent,
+			Name:      e.Name,
+			Signature: e.Sig,
+			Selector:  e.ID.Hex(),
+		})
+	}
+	entries = append(entries, sortedABIEntries(events)...)
+
+	errs := make([]ABIEntry, 0, len(contractABI.Errors))
+	for _, e := range contractABI.Errors {
+		errs = append(errs, ABIEntry{
+			Kind:      ABIError,
+			Name:      e.Name,
+			Signature: e.Sig,
+			Selector:  hexutil.Encode(e.ID[:4]),
+		})
+	}
+	entries = append(entries, sortedABIEntries(errs)...)
+
+	return entries
+}
+
+func sortedABIEntries(entries []ABIEntry) []ABIEntry {
+	// entries come from maps, so ties are broken by signature to keep the
+	// order deterministic
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Name != entries[j].Name {
+			return entries[i].Name < entries[j].Name
+		}
+		return entries[i].Signature < entries[j].Signature
+	})
+	return entries
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSourceFiles_SingleFile(t *testing.T) {
+	// process
+	files := ParseSourceFiles("pragma solidity ^0.8.0;\ncontract A {}")
+
+	// verify
+	assert.Len(t, files, 1)
+	assert.Equal(t, "Contract.sol", files[0].Name)
+	assert.Equal(t, "pragma solidity ^0.8.0;\ncontract A {}", files[0].Content)
+}
+
+func TestParseSourceFiles_MultiFile(t *testing.T) {
+	// prepare
+	source := `{"contracts/B.sol": {"content": "contract B {}"}, "contracts/A.sol": {"content": "contract A {}"}}`
+
+	// process
+	files := ParseSourceFiles(source)
+
+	// verify
+	assert.Len(t, files, 2)
+	assert.Equal(t, "contracts/A.sol", files[0].Name)
+	assert.Equal(t, "contract A {}", files[0].Content)
+	assert.Equal(t, "contracts/B.sol", files[1].Name)
+}
+
+func TestParseSourceFiles_StandardJSON(t *testing.T) {
+	// prepare
+	source := `{{"language": "Solidity", "sources": {"A.sol": {"content": "contract A {}"}}, "settings": {}}}`
+
+	// process
+	files := ParseSourceFiles(source)
+
+	// verify
+	assert.Len(t, files, 1)
+	assert.Equal(t, "A.sol", files[0].Name)
+	assert.Equal(t, "contract A {}", files[0].Content)
+}
+
+func TestListABIEntries(t *testing.T) {
+	// prepare
+	abiJson := `[
+		{"type": "function", "name": "transfer", "stateMutability": "nonpayable", "inputs": [{"name": "to", "type": "address"}, {"name": "amount", "type": "uint256"}], "outputs": [{"name": "", "type": "bool"}]},
+		{"type": "function", "name": "balanceOf", "stateMutability": "view", "inputs": [{"name": "owner", "type": "address"}], "outputs": [{"name": "", "type": "uint256"}]},
+		{"type": "event", "name": "Transfer", "inputs": [{"name": "from", "type": "address", "indexed": true}, {"name": "to", "type": "address", "indexed": true}, {"name": "value", "type": "uint256", "indexed": false}]},
+		{"type": "error", "name": "InsufficientBalance", "inputs": [{"name": "available", "type": "uint256"}]}
+	]`
+	parsed, err := abi.JSON(strings.NewReader(abiJson))
+	assert.NoError(t, err)
+
+	// process
+	entries := ListABIEntries(&parsed)
+
+	// verify
+	assert.Len(t, entries, 4)
+	assert.Equal(t, ABIEntry{Kind: ABIFunction, Name: "balanceOf", Signature: "balanceOf(address)", Selector: "0x70a08231", Mutability: "view"}, entries[0])
+	assert.Equal(t, ABIEntry{Kind: ABIFunction, Name: "transfer", Signature: "transfer(address,uint256)", Selector: "0xa9059cbb", Mutability: "nonpayable"}, entries[1])
+	assert.Equal(t, ABIEvent, entries[2].Kind)
+	assert.Equal(t, "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef", entries[2].Selector)
+	assert.Equal(t, ABIError, entries[3].Kind)
+	assert.Equal(t, "InsufficientBalance(uint256)", entries[3].Signature)
+	assert.Len(t, entries[3].Selector, 10)
+}
+
+func TestListABIEntries_Overloaded(t *testing.T) {
+	// prepare
+	abiJson := `[
+		{"type": "function", "name": "safeTransferFrom", "stateMutability": "nonpayable", "inputs": [{"name": "from", "type": "address"}, {"name": "to", "type": "address"}, {"name": "tokenId", "type": "uint256"}, {"name": "data", "type": "bytes"}], "outputs": []},
+		{"type": "function", "name": "safeTransferFrom", "stateMutability": "nonpayable", "inputs": [{"name": "from", "type": "address"}, {"name": "to", "type": "address"}, {"name": "tokenId", "type": "uint256"}], "outputs": []}
+	]`
+	parsed, err := abi.JSON(strings.NewReader(abiJson))
+	assert.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		// process
+		entries := ListABIEntries(&parsed)
+
+		// verify
+		if assert.Len(t, entries, 2) {
+			assert.True(t, entries[0].Name < entries[1].Name, "overloaded functions should be in a stable order")
+		}
+	}
+}
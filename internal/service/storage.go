@@ -0,0 +1,509 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/dyng/ramen/internal/common/conv"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+const (
+	EncodingInplace      = "inplace"
+	EncodingMapping      = "mapping"
+	EncodingDynamicArray = "dynamic_array"
+	EncodingBytes        = "bytes"
+
+	// maxStorageBytes is the maximum length of string or bytes read from storage
+	maxStorageBytes = 4096
+)
+
+var (
+	// EIP1967Slots are the storage slots of proxy defined by EIP-1967, each
+	// holds an address.
+	EIP1967Slots = []ProxySlot{
+		{Name: "implementation", Slot: eip1967Slot("eip1967.proxy.implementation")},
+		{Name: "admin", Slot: eip1967Slot("eip1967.proxy.admin")},
+		{Name: "beacon", Slot: eip1967Slot("eip1967.proxy.beacon")},
+	}
+
+	staticArrayPattern = regexp.MustCompile(`\[(\d+)\]$`)
+)
+
+// StorageReader reads the value of a storage slot.
+type StorageReader func(slot common.Hash) (common.Hash, error)
+
+// StorageLayout is the storage layout of contract generated by solc, with
+// `--storage-layout` option or `storageLayout` output selection.
+type StorageLayout struct {
+	Storage []*StorageVariable      `json:"storage"`
+	Types   map[string]*StorageType `json:"types"`
+}
+
+// StorageVariable is a state variable, or a member of struct.
+type StorageVariable struct {
+	Label  string `json:"label"`
+	Offset int    `json:"offset"`
+	Slot   string `json:"slot"`
+	Type   string `json:"type"`
+}
+
+// StorageType describes how a type is encoded in storage.
+type StorageType struct {
+	Encoding      string             `json:"encoding"`
+	Label         string             `json:"label"`
+	NumberOfBytes string             `json:"numberOfBytes"`
+	Key           string             `json:"key,omitempty"`
+	Value         string             `json:"value,omitempty"`
+	Base          string             `json:"base,omitempty"`
+	Members       []*StorageVariable `json:"members,omitempty"`
+}
+
+// StorageLocation is where a variable or an element of it is stored.
+type StorageLocation struct {
+	Path   string
+	Slot   common.Hash
+	Offset int
+	Type   *StorageType
+}
+
+// StorageValue is a decoded value read from storage.
+type StorageValue struct {
+	*StorageLocation
+	Raw   common.Hash
+	Value string
+}
+
+// ProxySlot is a well-known storage slot of proxy contract.
+type ProxySlot struct {
+	Name    string
+	Slot    common.Hash
+	Address common.Address
+}
+
+// HasStorageLayout returns true if storage layout of this contract is known.
+func (c *Contract) HasStorageLayout() bool {
+	return c.layout != nil
+}
+
+// GetStorageLayout returns storage layout of this contract, may be nil if it is unknown.
+func (c *Contract) GetStorageLayout() *StorageLayout {
+	return c.layout
+}
+
+// ReadStorage reads a storage slot of this contract at given block, or latest
+// block if blockNumber is nil.
+func (c *Contract) ReadStorage(slot common.Hash, blockNumber common.BigInt) (common.Hash, error) {
+	return c.service.provider.GetStorageAt(c.address, slot, blockNumber)
+}
+
+// ReadVariable reads a state variable by expression, see StorageLayout.Resolve.
+func (c *Contract) ReadVariable(expr string, blockNumber common.BigInt) (*StorageValue, error) {
+	if c.layout == nil {
+		return nil, errors.New("Storage layout is unknown")
+	}
+
+	loc, err := c.layout.Resolve(expr)
+	if err != nil {
+		return nil, err
+	}
+	return c.layout.Read(loc, c.storageReader(blockNumber))
+}
+
+// GetProxySlots returns EIP-1967 slots holding an address, which is empty if
+// this contract is not an EIP-1967 proxy.
+func (c *Contract) GetProxySlots(blockNumber common.BigInt) ([]ProxySlot, error) {
+	return ReadProxySlots(c.storageReader(blockNumber))
+}
+
+func (c *Contract) storageReader(blockNumber common.BigInt) StorageReader {
+	return func(slot common.Hash) (common.Hash, error) {
+		return c.ReadStorage(slot, blockNumber)
+	}
+}
+
+// ParseStorageLayout parses storage layout in JSON.
+func ParseStorageLayout(layoutJson []byte) (*StorageLayout, error) {
+	var layout StorageLayout
+	if err := json.Unmarshal(layoutJson, &layout); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if layout.Storage == nil || layout.Types == nil {
+		return nil, errors.New("Storage layout should contain 'storage' and 'types'")
+	}
+	return &layout, nil
+}
+
+// Size returns number of bytes the type occupies.
+func (t *StorageType) Size() int {
+	size, _ := strconv.Atoi(t.NumberOfBytes)
+	return size
+}
+
+// IsValueType returns true if the type is stored in a single slot and can be
+// decoded without keys, e.g. uint256 or address.
+func (t *StorageType) IsValueType() bool {
+	return t.Encoding == EncodingInplace && t.Base == "" && len(t.Members) == 0
+}
+
+// Resolve finds the location of a variable, expression could access elements
+// of mapping or array by key, and members of struct by name, e.g.
+// balances[0x...], owners[1] and config.fee.
+func (l *StorageLayout) Resolve(expr string) (*StorageLocation, error) {
+	expr = strings.TrimSpace(expr)
+	name, rest := splitAccessor(expr)
+
+	var loc *StorageLocation
+	for _, v := range l.Storage {
+		if v.Label == name {
+			var err error
+			loc, err = l.locate(name, v, big.NewInt(0))
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	if loc == nil {
+		return nil, errors.Errorf("Variable %s is not found in storage layout", name)
+	}
+
+	for rest != "" {
+		var err error
+		switch rest[0] {
+		case '.':
+			var member string
+			member, rest = splitAccessor(rest[1:])
+			loc, err = l.resolveMember(loc, member)
+		case '[':
+			end := indexOfClosingBracket(rest)
+			if end < 0 {
+				return nil, errors.Errorf("Missing ']' in %s", expr)
+			}
+			key := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			loc, err = l.resolveElement(loc, key)
+		default:
+			return nil, errors.Errorf("Unexpected '%s' in %s", rest, expr)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return loc, nil
+}
+
+// Read reads and decodes the value at given location.
+func (l *StorageLayout) Read(loc *StorageLocation, read StorageReader) (*StorageValue, error) {
+	raw, err := read(loc.Slot)
+	if err != nil {
+		return nil, err
+	}
+
+	val := &StorageValue{StorageLocation: loc, Raw: raw}
+	t := loc.Type
+	switch t.Encoding {
+	case EncodingMapping:
+		val.Value = "(mapping, access by key)"
+	case EncodingDynamicArray:
+		val.Value = fmt.Sprintf("(array of length %s)", raw.Big())
+	case EncodingBytes:
+		data, truncated, err := readBytes(loc.Slot, raw, read)
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(t.Label, "string") {
+			val.Value = strconv.Quote(string(data))
+		} else {
+			val.Value = hexutil.Encode(data)
+		}
+		if truncated {
+			val.Value += "..."
+		}
+	default:
+		if len(t.Members) > 0 {
+			val.Value = "(struct, access members by name)"
+		} else if t.Base != "" {
+			val.Value = "(array, access by index)"
+		} else {
+			val.Value = decodeStorageValue(t, raw, loc.Offset)
+		}
+	}
+	return val, nil
+}
+
+func (l *StorageLayout) locate(path string, v *StorageVariable, base common.BigInt) (*StorageLocation, error) {
+	slot, ok := new(big.Int).SetString(v.Slot, 10)
+	if !ok {
+		return nil, errors.Errorf("Invalid slot %s of %s", v.Slot, path)
+	}
+	t, err := l.typeOf(v.Type)
+	if err != nil {
+		return nil, err
+	}
+	return &StorageLocation{
+		Path:   path,
+		Slot:   gcommon.BigToHash(slot.Add(slot, base)),
+		Offset: v.Offset,
+		Type:   t,
+	}, nil
+}
+
+func (l *StorageLayout) resolveMember(loc *StorageLocation, member string) (*StorageLocation, error) {
+	for _, m := range loc.Type.Members {
+		if m.Label == member {
+			return l.locate(loc.Path+"."+member, m, loc.Slot.Big())
+		}
+	}
+	return nil, errors.Errorf("%s has no member %s", loc.Path, member)
+}
+
+func (l *StorageLayout) resolveElement(loc *StorageLocation, key string) (*StorageLocation, error) {
+	path := fmt.Sprintf("%s[%s]", loc.Path, key)
+	t := loc.Type
+	switch {
+	case t.Encoding == EncodingMapping:
+		keyType, err := l.typeOf(t.Key)
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := encodeMappingKey(keyType, key)
+		if err != nil {
+			return nil, err
+		}
+		valueType, err := l.typeOf(t.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &StorageLocation{
+			Path: path,
+			Slot: crypto.Keccak256Hash(encoded, loc.Slot.Bytes()),
+			Type: valueType,
+		}, nil
+	case t.Encoding == EncodingDynamicArray || (t.Encoding == EncodingInplace && t.Base != ""):
+		index, ok := new(big.Int).SetString(key, 0)
+		if !ok || index.Sign() < 0 {
+			return nil, errors.Errorf("Invalid index %s of %s", key, loc.Path)
+		}
+		elemType, err := l.typeOf(t.Base)
+		if err != nil {
+			return nil, err
+		}
+
+		start := loc.Slot
+		if t.Encoding == EncodingDynamicArray {
+			start = crypto.Keccak256Hash(loc.Slot.Bytes())
+		} else if m := staticArrayPattern.FindStringSubmatch(t.Label); m != nil {
+			length, _ := new(big.Int).SetString(m[1], 10)
+			if index.Cmp(length) >= 0 {
+				return nil, errors.Errorf("Index %s is out of range of %s", key, loc.Path)
+			}
+		}
+
+		slot, offset := elementSlot(start, index, elemType.Size())
+		return &StorageLocation{
+			Path:   path,
+			Slot:   slot,
+			Offset: offset,
+			Type:   elemType,
+		}, nil
+	default:
+		return nil, errors.Errorf("%s is neither a mapping nor an array", loc.Path)
+	}
+}
+
+func (l *StorageLayout) typeOf(name string) (*StorageType, error) {
+	t, ok := l.Types[name]
+	if !ok {
+		return nil, errors.Errorf("Type %s is not found in storage layout", name)
+	}
+	return t, nil
+}
+
+// elementSlot returns slot and offset of an array element. Elements no larger
+// than 16 bytes are packed into one slot.
+func elementSlot(start common.Hash, index common.BigInt, size int) (common.Hash, int) {
+	slot := new(big.Int).Set(start.Big())
+	if size <= 16 {
+		perSlot := big.NewInt(int64(32 / size))
+		q, r := new(big.Int).QuoRem(index, perSlot, new(big.Int))
+		slot.Add(slot, q)
+		return gcommon.BigToHash(slot), int(r.Int64()) * size
+	}
+
+	slots := big.NewInt(int64((size + 31) / 32))
+	slot.Add(slot, new(big.Int).Mul(index, slots))
+	return gcommon.BigToHash(slot), 0
+}
+
+// encodeMappingKey encodes a key of mapping for hashing. Value types are
+// padded to 32 bytes, while string and bytes are used as they are.
+func encodeMappingKey(keyType *StorageType, key string) ([]byte, error) {
+	if keyType.Encoding == EncodingBytes {
+		if strings.HasPrefix(keyType.Label, "string") {
+			if unquoted, err := strconv.Unquote(key); err == nil {
+				key = unquoted
+			}
+			return []byte(key), nil
+		}
+		b, err := conv.HexToBytes(key)
+		return b, errors.WithStack(err)
+	}
+
+	t, err := abi.NewType(abiTypeOf(keyType.Label), "", nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	val, err := conv.UnpackArgument(t, key)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := abi.Arguments{{Type: t}}.Pack(val)
+	return encoded, errors.WithStack(err)
+}
+
+// abiTypeOf converts type label in storage layout to ABI type, e.g. contract
+// and enum types.
+func abiTypeOf(label string) string {
+	switch {
+	case strings.HasPrefix(label, "contract "), strings.HasPrefix(label, "address"):
+		return "address"
+	case strings.HasPrefix(label, "enum "):
+		return "uint8"
+	default:
+		return label
+	}
+}
+
+// decodeStorageValue decodes a value type packed in slot at given offset.
+func decodeStorageValue(t *StorageType, raw common.Hash, offset int) string {
+	size := t.Size()
+	if size <= 0 || offset+size > 32 {
+		return raw.Hex()
+	}
+	b := raw[32-offset-size : 32-offset]
+
+	label := abiTypeOf(t.Label)
+	switch {
+	case label == "bool":
+		return strconv.FormatBool(b[len(b)-1] != 0)
+	case label == "address":
+		return gcommon.BytesToAddress(b).Hex()
+	case strings.HasPrefix(label, "uint"):
+		return new(big.Int).SetBytes(b).String()
+	case strings.HasPrefix(label, "int"):
+		v := new(big.Int).SetBytes(b)
+		if b[0]&0x80 != 0 {
+			v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(size*8)))
+		}
+		return v.String()
+	default:
+		return hexutil.Encode(b)
+	}
+}
+
+// readBytes reads a string or bytes. Short value (less than 32 bytes) is
+// stored in slot with length*2 in lowest byte, otherwise slot holds
+// length*2+1 and data is stored from keccak256(slot).
+func readBytes(slot common.Hash, raw common.Hash, read StorageReader) ([]byte, bool, error) {
+	if raw[31]&1 == 0 {
+		// a malformed slot may claim more bytes than it holds
+		length := int(raw[31] / 2)
+		if length > 31 {
+			length = 31
+		}
+		return raw[:length], false, nil
+	}
+
+	lengthNum := new(big.Int).Rsh(raw.Big(), 1)
+	var length int64
+	truncated := false
+	if !lengthNum.IsInt64() || lengthNum.Int64() > maxStorageBytes {
+		length = maxStorageBytes
+		truncated = true
+	} else {
+		length = lengthNum.Int64()
+	}
+
+	data := make([]byte, 0, length)
+	start := crypto.Keccak256Hash(slot.Bytes()).Big()
+	for i := int64(0); int64(len(data)) < length; i++ {
+		word, err := read(gcommon.BigToHash(new(big.Int).Add(start, big.NewInt(i))))
+		if err != nil {
+			return nil, false, err
+		}
+		data = append(data, word.Bytes()...)
+	}
+	return data[:length], truncated, nil
+}
+
+// ReadProxySlots reads EIP-1967 slots, and returns those holding an address.
+func ReadProxySlots(read StorageReader) ([]ProxySlot, error) {
+	result := make([]ProxySlot, 0)
+	for _, ps := range EIP1967Slots {
+		raw, err := read(ps.Slot)
+		if err != nil {
+			return nil, err
+		}
+		addr := gcommon.BytesToAddress(raw.Bytes())
+		if addr != (common.Address{}) {
+			result = append(result, ProxySlot{Name: ps.Name, Slot: ps.Slot, Address: addr})
+		}
+	}
+	return result, nil
+}
+
+// ParseSlot parses a slot number in decimal or hex (0x prefixed).
+func ParseSlot(s string) (common.Hash, error) {
+	slot, ok := new(big.Int).SetString(strings.TrimSpace(s), 0)
+	if !ok || slot.Sign() < 0 || slot.BitLen() > 256 {
+		return common.Hash{}, errors.Errorf("Invalid slot %s", s)
+	}
+	return gcommon.BigToHash(slot), nil
+}
+
+// eip1967Slot returns keccak256(name) - 1.
+func eip1967Slot(name string) common.Hash {
+	h := crypto.Keccak256Hash([]byte(name)).Big()
+	return gcommon.BigToHash(h.Sub(h, big.NewInt(1)))
+}
+
+// splitAccessor splits the leading identifier from an expression.
+func splitAccessor(expr string) (string, string) {
+	i := strings.IndexAny(expr, ".[")
+	if i < 0 {
+		return strings.TrimSpace(expr), ""
+	}
+	return strings.TrimSpace(expr[:i]), expr[i:]
+}
+
+// indexOfClosingBracket returns index of ']' closing the leading '[', ignoring
+// brackets in quoted string.
+func indexOfClosingBracket(s string) int {
+	quoted := false
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if quoted {
+				i++
+			}
+		case '"':
+			quoted = !quoted
+		case ']':
+			if !quoted {
+				return i
+			}
+		}
+	}
+	return -1
+}
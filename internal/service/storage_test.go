@@ -0,0 +1,184 @@
+package service
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/dyng/ramen/internal/common"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+const testStorageLayout = `{
+	"storage": [
+		{"label": "owner", "offset": 0, "slot": "0", "type": "t_address"},
+		{"label": "paused", "offset": 20, "slot": "0", "type": "t_bool"},
+		{"label": "delta", "offset": 0, "slot": "1", "type": "t_int128"},
+		{"label": "total", "offset": 16, "slot": "1", "type": "t_uint128"},
+		{"label": "name", "offset": 0, "slot": "2", "type": "t_string_storage"},
+		{"label": "balances", "offset": 0, "slot": "3", "type": "t_mapping(t_address,t_uint256)"},
+		{"label": "values", "offset": 0, "slot": "4", "type": "t_array(t_uint64)dyn_storage"},
+		{"label": "config", "offset": 0, "slot": "5", "type": "t_struct(Config)1_storage"},
+		{"label": "configs", "offset": 0, "slot": "7", "type": "t_mapping(t_string_memory_ptr,t_struct(Config)1_storage)"}
+	],
+	"types": {
+		"t_address": {"encoding": "inplace", "label": "address", "numberOfBytes": "20"},
+		"t_bool": {"encoding": "inplace", "label": "bool", "numberOfBytes": "1"},
+		"t_int128": {"encoding": "inplace", "label": "int128", "numberOfBytes": "16"},
+		"t_uint128": {"encoding": "inplace", "label": "uint128", "numberOfBytes": "16"},
+		"t_uint64": {"encoding": "inplace", "label": "uint64", "numberOfBytes": "8"},
+		"t_uint256": {"encoding": "inplace", "label": "uint256", "numberOfBytes": "32"},
+		"t_string_storage": {"encoding": "bytes", "label": "string", "numberOfBytes": "32"},
+		"t_string_memory_ptr": {"encoding": "bytes", "label": "string", "numberOfBytes": "32"},
+		"t_mapping(t_address,t_uint256)": {"encoding": "mapping", "key": "t_address", "label": "mapping(address => uint256)", "numberOfBytes": "32", "value": "t_uint256"},
+		"t_mapping(t_string_memory_ptr,t_struct(Config)1_storage)": {"encoding": "mapping", "key": "t_string_memory_ptr", "label": "mapping(string => struct C.Config)", "numberOfBytes": "32", "value": "t_struct(Config)1_storage"},
+		"t_array(t_uint64)dyn_storage": {"encoding": "dynamic_array", "base": "t_uint64", "label": "uint64[]", "numberOfBytes": "32"},
+		"t_struct(Config)1_storage": {"encoding": "inplace", "label": "struct C.Config", "numberOfBytes": "64", "members": [
+			{"label": "fee", "offset": 0, "slot": "0", "type": "t_uint256"},
+			{"label": "receiver", "offset": 0, "slot": "1", "type": "t_address"}
+		]}
+	}
+}`
+
+func TestStorageLayout_ReadValueTypes(t *testing.T) {
+	// prepare
+	layout, err := ParseStorageLayout([]byte(testStorageLayout))
+	assert.NoError(t, err)
+	owner := gcommon.HexToAddress("0xFABB0ac9d68B0B445fB7357272Ff202C5651694a")
+	storage := map[common.Hash]common.Hash{
+		slotOf(0): gcommon.HexToHash("0x0000000000000000000000" + "01" + strings.TrimPrefix(strings.ToLower(owner.Hex()), "0x")),
+		slotOf(1): gcommon.HexToHash("0x000000000000000000000000000000ff" + "ffffffffffffffffffffffffffffff9c"),
+		slotOf(2): gcommon.BytesToHash(append([]byte("ramen"), make([]byte, 27)...)),
+	}
+	storage[slotOf(2)] = withLastByte(storage[slotOf(2)], 10)
+
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{"owner", owner.Hex()},
+		{"paused", "true"},
+		{"delta", "-100"},
+		{"total", "255"},
+		{"name", `"ramen"`},
+	}
+
+	for _, test := range tests {
+		// process
+		loc, err := layout.Resolve(test.expr)
+		assert.NoError(t, err, test.expr)
+		val, err := layout.Read(loc, fakeReader(storage))
+
+		// verify
+		assert.NoError(t, err, test.expr)
+		assert.Equal(t, test.expected, val.Value, test.expr)
+	}
+}
+
+func TestReadBytes_Malformed(t *testing.T) {
+	// prepare
+	slot := slotOf(2)
+	short := withLastByte(gcommon.BytesToHash([]byte("ramen")), 0xfe)
+	long := gcommon.HexToHash("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+	reads := 0
+	read := func(slot common.Hash) (common.Hash, error) {
+		reads++
+		return common.Hash{}, nil
+	}
+
+	// process
+	shortData, shortTruncated, shortErr := readBytes(slot, short, read)
+	longData, longTruncated, longErr := readBytes(slot, long, read)
+
+	// verify
+	assert.NoError(t, shortErr)
+	assert.Len(t, shortData, 31, "short value should not exceed the slot")
+	assert.False(t, shortTruncated)
+	assert.NoError(t, longErr)
+	assert.Len(t, longData, maxStorageBytes, "length overflowing int64 should be capped")
+	assert.True(t, longTruncated)
+	assert.Equal(t, maxStorageBytes/32, reads)
+}
+
+func TestStorageLayout_ResolveByKey(t *testing.T) {
+	// prepare
+	layout, err := ParseStorageLayout([]byte(testStorageLayout))
+	assert.NoError(t, err)
+	holder := gcommon.HexToAddress("0xFABB0ac9d68B0B445fB7357272Ff202C5651694a")
+
+	// process & verify: mapping of address
+	loc, err := layout.Resolve("balances[" + holder.Hex() + "]")
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.Keccak256Hash(gcommon.LeftPadBytes(holder.Bytes(), 32), slotOf(3).Bytes()), loc.Slot)
+	assert.Equal(t, "uint256", loc.Type.Label)
+
+	// process & verify: dynamic array packs 4 uint64 in a slot
+	loc, err = layout.Resolve("values[5]")
+	assert.NoError(t, err)
+	start := crypto.Keccak256Hash(slotOf(4).Bytes()).Big()
+	assert.Equal(t, gcommon.BigToHash(start.Add(start, big.NewInt(1))), loc.Slot)
+	assert.Equal(t, 8, loc.Offset)
+
+	// process & verify: member of struct
+	loc, err = layout.Resolve("config.receiver")
+	assert.NoError(t, err)
+	assert.Equal(t, slotOf(6), loc.Slot)
+
+	// process & verify: member of struct in mapping of string
+	loc, err = layout.Resolve(`configs["a]b"].receiver`)
+	assert.NoError(t, err)
+	base := crypto.Keccak256Hash([]byte("a]b"), slotOf(7).Bytes()).Big()
+	assert.Equal(t, gcommon.BigToHash(base.Add(base, big.NewInt(1))), loc.Slot)
+	assert.Equal(t, `configs["a]b"].receiver`, loc.Path)
+}
+
+func TestStorageLayout_ResolveInvalid(t *testing.T) {
+	// prepare
+	layout, err := ParseStorageLayout([]byte(testStorageLayout))
+	assert.NoError(t, err)
+
+	for _, expr := range []string{"unknown", "owner[1]", "config.unknown", "balances[0x1234]", "values[-1]", "balances[0x1"} {
+		// process
+		_, err := layout.Resolve(expr)
+
+		// verify
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestReadProxySlots(t *testing.T) {
+	// prepare
+	impl := gcommon.HexToAddress("0xFABB0ac9d68B0B445fB7357272Ff202C5651694a")
+	implSlot := gcommon.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+	storage := map[common.Hash]common.Hash{
+		implSlot: gcommon.BytesToHash(impl.Bytes()),
+	}
+
+	// process
+	slots, err := ReadProxySlots(fakeReader(storage))
+
+	// verify
+	assert.NoError(t, err)
+	assert.Len(t, slots, 1)
+	assert.Equal(t, "implementation", slots[0].Name)
+	assert.Equal(t, implSlot, slots[0].Slot)
+	assert.Equal(t, impl, slots[0].Address)
+	assert.Equal(t, gcommon.HexToHash("0xb53127684a568b3173ae13b9f8a6016e243e63b6e8ee1178d6a717850b5d6103"), EIP1967Slots[1].Slot)
+}
+
+func slotOf(n int64) common.Hash {
+	return gcommon.BigToHash(big.NewInt(n))
+}
+
+func withLastByte(h common.Hash, b byte) common.Hash {
+	h[31] = b
+	return h
+}
+
+func fakeReader(storage map[common.Hash]common.Hash) StorageReader {
+	return func(slot common.Hash) (common.Hash, error) {
+		return storage[slot], nil
+	}
+}
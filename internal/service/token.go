@@ -0,0 +1,335 @@
+package service
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/dyng/ramen/internal/provider"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/patrickmn/go-cache"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+const erc20ABIJson = `[{"constant":true,"inputs":[],"name":"name","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},{"constant":true,"inputs":[],"name":"symbol","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},{"constant":true,"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"stateMutability":"view","type":"function"},{"constant":true,"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"constant":false,"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"}]`
+
+const (
+	// PegETH means token's price is the same as ETH
+	PegETH = "ETH"
+	// PegUSD means token is a stablecoin pegged to USD
+	PegUSD = "USD"
+)
+
+// ERC20ABI is the ABI of standard ERC-20 methods.
+var ERC20ABI abi.ABI
+
+// defaultTokens are well-known tokens whose balances are queried when
+// provider cannot list tokens of an account, keyed by chain id.
+var defaultTokens = map[string][]string{
+	"1": {
+		"0xdAC17F958D2ee523a2206206994597C13D831ec7", // USDT
+		"0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", // USDC
+		"0x6B175474E89094C44Da98b954EedeAC495271d0F", // DAI
+		"0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", // WETH
+		"0x2260FAC5E5542a773Aa44fBCfeDf7C193bc2C599", // WBTC
+		"0x514910771AF9Ca656af840dff83E8264EcF986CA", // LINK
+		"0x1f9840a85d5aF5bf1D1762F925BDADdC4201F984", // UNI
+	},
+}
+
+// tokenPegs are tokens whose price can be derived without a price oracle,
+// keyed by chain id.
+var tokenPegs = map[string]map[common.Address]string{
+	"1": {
+		gcommon.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7"): PegUSD,
+		gcommon.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"): PegUSD,
+		gcommon.HexToAddress("0x6B175474E89094C44Da98b954EedeAC495271d0F"): PegUSD,
+		gcommon.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"): PegETH,
+	},
+}
+
+func init() {
+	var err error
+	ERC20ABI, err = abi.JSON(strings.NewReader(erc20ABIJson))
+	if err != nil {
+		log.Error("Cannot parse ABI of ERC-20", "error", errors.WithStack(err))
+		common.Exit("Cannot parse ABI of ERC-20: %v", err)
+	}
+}
+
+// Token is an ERC-20 token.
+type Token struct {
+	Address  common.Address
+	Name     string
+	Symbol   string
+	Decimals uint8
+	// DecimalsKnown is false if decimals() of token cannot be fetched, in
+	// which case Decimals should not be used
+	DecimalsKnown bool
+	Peg           string // PegETH, PegUSD or empty if price is unknown
+}
+
+// TokenBalance is the amount of a token held by an account.
+type TokenBalance struct {
+	Token   *Token
+	Balance common.BigInt
+}
+
+// Amount returns the balance adjusted by token's decimals, or the raw balance
+// if decimals is unknown.
+func (tb *TokenBalance) Amount() decimal.Decimal {
+	if !tb.Token.DecimalsKnown {
+		return decimal.NewFromBigInt(tb.Balance, 0)
+	}
+	return decimal.NewFromBigInt(tb.Balance, -int32(tb.Token.Decimals))
+}
+
+// Value returns the value of balance in USD, or nil if price or decimals of
+// token is unknown.
+func (tb *TokenBalance) Value(ethPrice *decimal.Decimal) *decimal.Decimal {
+	if !tb.Token.DecimalsKnown {
+		return nil
+	}
+
+	var value decimal.Decimal
+	switch tb.Token.Peg {
+	case PegUSD:
+		value = tb.Amount()
+	case PegETH:
+		if ethPrice == nil {
+			return nil
+		}
+		value = tb.Amount().Mul(*ethPrice)
+	default:
+		return nil
+	}
+	return &value
+}
+
+// GetTokenBalances returns non-zero balances of ERC-20 tokens held by given
+// address. On Alchemy all tokens are listed, otherwise only tokens in the
+// configured token list are queried.
+func (s *Service) GetTokenBalances(address common.Address) ([]*TokenBalance, error) {
+	var (
+		tokenAddrs []common.Address
+		balances   []common.BigInt
+		err        error
+	)
+	if s.provider.GetType() == provider.ProviderAlchemy {
+		tokenAddrs, balances, err = s.listTokenBalances(address)
+	} else {
+		tokenAddrs, balances, err = s.queryTokenBalances(address, s.tokenList())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.GetTokens(tokenAddrs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*TokenBalance, 0)
+	for i, token := range tokens {
+		if balances[i] == nil || balances[i].Sign() == 0 {
+			continue
+		}
+		result = append(result, &TokenBalance{
+			Token:   token,
+			Balance: balances[i],
+		})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Token.Symbol < result[j].Token.Symbol
+	})
+
+	return result, nil
+}
+
+// GetTokenBalance returns balance of given token held by given address.
+func (s *Service) GetTokenBalance(token common.Address, address common.Address) (common.BigInt, error) {
+	vals, err := s.provider.CallContract(token, &ERC20ABI, "balanceOf", address)
+	if err != nil {
+		return nil, err
+	}
+	return vals[0].(common.BigInt), nil
+}
+
+// GetToken returns metadata of given token.
+func (s *Service) GetToken(address common.Address) (*Token, error) {
+	tokens, err := s.GetTokens([]common.Address{address})
+	if err != nil {
+		return nil, err
+	}
+	return tokens[0], nil
+}
+
+// GetTokens returns metadata of given tokens, which are fetched in one batch
+// and cached. Tokens whose metadata cannot be fully fetched are not cached,
+// so that they are retried next time.
+func (s *Service) GetTokens(addrs []common.Address) ([]*Token, error) {
+	result := make([]*Token, len(addrs))
+
+	missing := make([]int, 0)
+	calls := make([]provider.ContractCall, 0)
+	for i, addr := range addrs {
+		if token, found := s.cache.Get(s.tokenCacheKey(addr)); found {
+			result[i] = token.(*Token)
+			continue
+		}
+
+		missing = append(missing, i)
+		for _, method := range []string{"name", "symbol", "decimals"} {
+			calls = append(calls, provider.ContractCall{
+				Address: addr,
+				ABI:     &ERC20ABI,
+				Method:  method,
+			})
+		}
+	}
+
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	vals, errs, err := s.provider.BatchCallContract(calls)
+	if err != nil {
+		return nil, err
+	}
+
+	pegs := tokenPegs[s.GetNetwork().ChainId.String()]
+	for j, i := range missing {
+		addr := addrs[i]
+		token := &Token{
+			Address: addr,
+			Name:    "Unknown",
+			Symbol:  "???",
+			Peg:     pegs[addr],
+		}
+		if errs[3*j] == nil {
+			token.Name = vals[3*j][0].(string)
+		}
+		if errs[3*j+1] == nil {
+			token.Symbol = vals[3*j+1][0].(string)
+		}
+		if errs[3*j+2] == nil {
+			token.Decimals = vals[3*j+2][0].(uint8)
+			token.DecimalsKnown = true
+		} else {
+			log.Warn("Failed to fetch decimals of token", "address", addr, "error", errs[3*j+2])
+		}
+
+		result[i] = token
+		if errs[3*j] == nil && errs[3*j+1] == nil && errs[3*j+2] == nil {
+			s.cache.Set(s.tokenCacheKey(addr), token, cache.NoExpiration)
+		}
+	}
+
+	return result, nil
+}
+
+// listTokenBalances lists balances of all tokens held by address with Alchemy's API.
+func (s *Service) listTokenBalances(address common.Address) ([]common.Address, []common.BigInt, error) {
+	tokenAddrs := make([]common.Address, 0)
+	balances := make([]common.BigInt, 0)
+
+	pageKey := ""
+	for {
+		res, err := s.provider.GetTokenBalances(address.Hex(), pageKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		if res == nil {
+			// no result at all, e.g. account has never held any token
+			break
+		}
+
+		for _, tb := range res.TokenBalances {
+			if tb.Error != nil {
+				log.Warn("Failed to fetch token balance", "token", tb.ContractAddress, "error", *tb.Error)
+				continue
+			}
+			bal, err := hexutil.DecodeBig(trimLeadingZeros(tb.TokenBalance))
+			if err != nil {
+				log.Warn("Cannot parse token balance", "token", tb.ContractAddress, "balance", tb.TokenBalance, "error", err)
+				continue
+			}
+			tokenAddrs = append(tokenAddrs, gcommon.HexToAddress(tb.ContractAddress))
+			balances = append(balances, bal)
+		}
+
+		if res.PageKey == "" {
+			break
+		}
+		pageKey = res.PageKey
+	}
+
+	return tokenAddrs, balances, nil
+}
+
+// queryTokenBalances queries balances of given tokens by batched balanceOf calls.
+func (s *Service) queryTokenBalances(address common.Address, tokenAddrs []common.Address) ([]common.Address, []common.BigInt, error) {
+	if len(tokenAddrs) == 0 {
+		return tokenAddrs, []common.BigInt{}, nil
+	}
+
+	calls := make([]provider.ContractCall, len(tokenAddrs))
+	for i, token := range tokenAddrs {
+		calls[i] = provider.ContractCall{
+			Address: token,
+			ABI:     &ERC20ABI,
+			Method:  "balanceOf",
+			Args:    []any{address},
+		}
+	}
+
+	vals, errs, err := s.provider.BatchCallContract(calls)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	balances := make([]common.BigInt, len(tokenAddrs))
+	for i := range tokenAddrs {
+		if errs[i] != nil {
+			log.Warn("Failed to fetch token balance", "token", tokenAddrs[i], "error", errs[i])
+			continue
+		}
+		balances[i] = vals[i][0].(common.BigInt)
+	}
+
+	return tokenAddrs, balances, nil
+}
+
+// tokenList returns tokens to query on current network, including
+// well-known tokens and tokens in config.
+func (s *Service) tokenList() []common.Address {
+	chainId := s.GetNetwork().ChainId.String()
+
+	seen := make(map[common.Address]bool)
+	result := make([]common.Address, 0)
+	for _, list := range [][]string{defaultTokens[chainId], s.config.Tokens[chainId]} {
+		for _, hex := range list {
+			addr := gcommon.HexToAddress(hex)
+			if !seen[addr] {
+				seen[addr] = true
+				result = append(result, addr)
+			}
+		}
+	}
+	return result
+}
+
+// trimLeadingZeros removes leading zeros of a 0x-prefixed hex string, which
+// hexutil.DecodeBig refuses to parse.
+func trimLeadingZeros(hex string) string {
+	trimmed := strings.TrimLeft(strings.TrimPrefix(hex, "0x"), "0")
+	if trimmed == "" {
+		trimmed = "0"
+	}
+	return "0x" + trimmed
+}
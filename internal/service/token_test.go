@@ -0,0 +1,125 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBalance_Amount(t *testing.T) {
+	// prepare
+	tb := &TokenBalance{
+		Token:   &Token{Symbol: "USDC", Decimals: 6, DecimalsKnown: true},
+		Balance: big.NewInt(1234567),
+	}
+
+	// process & verify
+	assert.Equal(t, "1.234567", tb.Amount().String())
+
+	// raw balance is shown if decimals is unknown
+	tb.Token = &Token{Symbol: "FOO"}
+	assert.Equal(t, "1234567", tb.Amount().String())
+	assert.Nil(t, tb.Value(nil))
+}
+
+func TestTokenBalance_Value(t *testing.T) {
+	// prepare
+	ethPrice := decimal.NewFromInt(2000)
+	weth := &TokenBalance{
+		Token:   &Token{Symbol: "WETH", Decimals: 18, DecimalsKnown: true, Peg: PegETH},
+		Balance: new(big.Int).Mul(big.NewInt(15), big.NewInt(1e17)),
+	}
+	usdt := &TokenBalance{
+		Token:   &Token{Symbol: "USDT", Decimals: 6, DecimalsKnown: true, Peg: PegUSD},
+		Balance: big.NewInt(5000000),
+	}
+	unknown := &TokenBalance{
+		Token:   &Token{Symbol: "FOO", Decimals: 18, DecimalsKnown: true},
+		Balance: big.NewInt(1),
+	}
+
+	// process & verify
+	assert.Equal(t, "3000", weth.Value(&ethPrice).String())
+	assert.Nil(t, weth.Value(nil))
+	assert.Equal(t, "5", usdt.Value(nil).String())
+	assert.Nil(t, unknown.Value(&ethPrice))
+}
+
+type stubTokenEth struct {
+	failures int // number of failing calls of decimals()
+}
+
+func (s *stubTokenEth) Call(args map[string]any, block string) (hexutil.Bytes, error) {
+	input := hexutil.MustDecode(args["data"].(string))
+	method, err := ERC20ABI.MethodById(input)
+	if err != nil {
+		return nil, err
+	}
+
+	switch method.Name {
+	case "name":
+		return method.Outputs.Pack("Foo Token")
+	case "symbol":
+		return method.Outputs.Pack("FOO")
+	case "decimals":
+		if s.failures > 0 {
+			s.failures--
+			return nil, errors.New("request timed out")
+		}
+		return method.Outputs.Pack(uint8(6))
+	}
+	return nil, errors.Errorf("unexpected method %s", method.Name)
+}
+
+func TestGetTokens_Retry(t *testing.T) {
+	// prepare
+	eth := &stubTokenEth{failures: 1}
+	serv := newStubService(t, map[string]any{"eth": eth})
+	addr := gcommon.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+
+	// process
+	token, err := serv.GetToken(addr)
+
+	// verify
+	assert.NoError(t, err)
+	assert.Equal(t, "FOO", token.Symbol)
+	assert.False(t, token.DecimalsKnown, "decimals should be unknown if decimals() fails")
+
+	// process
+	token, err = serv.GetToken(addr)
+
+	// verify
+	assert.NoError(t, err)
+	assert.True(t, token.DecimalsKnown, "token should be fetched again if not fully known")
+	assert.EqualValues(t, 6, token.Decimals)
+}
+
+type stubAlchemy struct{}
+
+func (s *stubAlchemy) GetTokenBalances(address string, kind string) map[string]any {
+	return nil
+}
+
+func TestListTokenBalances_NullResult(t *testing.T) {
+	// prepare
+	serv := newStubService(t, map[string]any{"alchemy": &stubAlchemy{}})
+
+	// process
+	tokens, balances, err := serv.listTokenBalances(gcommon.HexToAddress("0xFABB0ac9d68B0B445fB7357272Ff202C5651694a"))
+
+	// verify
+	assert.NoError(t, err)
+	assert.Empty(t, tokens)
+	assert.Empty(t, balances)
+}
+
+func TestTrimLeadingZeros(t *testing.T) {
+	assert.Equal(t, "0x1a", trimLeadingZeros("0x000000000000001a"))
+	assert.Equal(t, "0x0", trimLeadingZeros("0x0000"))
+	assert.Equal(t, "0x0", trimLeadingZeros("0x"))
+}
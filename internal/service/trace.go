@@ -0,0 +1,103 @@
+package service
+
+import (
+	"github.com/dyng/ramen/internal/common"
+	"github.com/dyng/ramen/internal/provider"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ErrTraceNotSupported is returned if provider does not support tracing
+// transactions, e.g. debug namespace is disabled.
+var ErrTraceNotSupported = provider.ErrMethodNotFound
+
+// Trace is a call made during execution of a transaction, whose input and
+// output are decoded by ABI of callee, or guessed by signature database.
+type Trace struct {
+	*common.CallFrame
+	Method  *abi.Method // nil if input cannot be decoded
+	Args    []any       // arguments in the same order as Method.Inputs
+	Outputs []any       // nil if output cannot be decoded
+	Guessed bool        // true if decoded by signature database
+	Reason  string      // revert reason, empty if not reverted or unknown
+	Calls   []*Trace
+}
+
+// TraceTransaction returns the tree of calls made by a mined transaction.
+// ErrTraceNotSupported is returned if provider does not support it.
+func (s *Service) TraceTransaction(hash common.Hash) (*Trace, error) {
+	frame, err := s.provider.TraceTransaction(hash)
+	if err != nil {
+		return nil, err
+	}
+	return s.decodeTrace(frame), nil
+}
+
+// decodeTrace decodes a call frame along with all its subcalls.
+func (s *Service) decodeTrace(frame *common.CallFrame) *Trace {
+	trace := &Trace{
+		CallFrame: frame,
+		Calls:     make([]*Trace, len(frame.Calls)),
+	}
+
+	contractABI := s.decodeTraceCall(trace)
+	if frame.Failed() {
+		trace.Reason = DecodeRevert(frame.Output, contractABI)
+		if trace.Reason == "" {
+			trace.Reason = frame.RevertReason
+		}
+	}
+
+	for i, call := range frame.Calls {
+		trace.Calls[i] = s.decodeTrace(call)
+	}
+	return trace
+}
+
+// decodeTraceCall decodes input and output of a call, and returns ABI of
+// callee if known.
+func (s *Service) decodeTraceCall(trace *Trace) *abi.ABI {
+	if trace.IsCreate() || trace.To == nil || len(trace.Input) < 4 || isPrecompile(*trace.To) {
+		return nil
+	}
+
+	var contractABI *abi.ABI
+	contract, err := s.GetContract(*trace.To)
+	if err != nil {
+		log.Debug("Cannot fetch callee of trace", "address", *trace.To, "error", err)
+	} else if contract.HasABI() {
+		contractABI = contract.GetABI()
+		method, args, err := contract.ParseCalldata(trace.Input)
+		if err == nil {
+			trace.Method = method
+			trace.Args = args
+			if !trace.Failed() {
+				outputs, err := method.Outputs.Unpack(trace.Output)
+				if err == nil {
+					trace.Outputs = outputs
+				}
+			}
+			return contractABI
+		}
+		log.Debug("Cannot decode trace by ABI", "address", *trace.To, "error", err)
+	}
+
+	// guess by selector at last
+	if guessed := s.signatures.GuessCalldata(trace.Input); guessed != nil {
+		trace.Method = guessed.Method
+		trace.Args = guessed.Args
+		trace.Guessed = true
+	}
+	return contractABI
+}
+
+// isPrecompile returns true if address is of a precompiled contract, such
+// as ecrecover at 0x01.
+func isPrecompile(address common.Address) bool {
+	for _, b := range address[:len(address)-1] {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/dyng/ramen/internal/provider"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	gcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+const testTokenABI = `[
+	{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}],"stateMutability":"nonpayable"}
+]`
+
+type stubNet struct{}
+
+func (s *stubNet) Version() string {
+	return "31337"
+}
+
+type stubDebug struct {
+	frame *common.CallFrame
+}
+
+func (s *stubDebug) TraceTransaction(hash common.Hash, config map[string]any) *common.CallFrame {
+	return s.frame
+}
+
+// newStubService creates a service connected to a node serving given apis
+// along with net_version.
+func newStubService(t *testing.T, apis map[string]any) *Service {
+	server := rpc.NewServer()
+	if err := server.RegisterName("net", &stubNet{}); err != nil {
+		t.Fatal(err)
+	}
+	for name, api := range apis {
+		if err := server.RegisterName(name, api); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+
+	return &Service{
+		provider:   provider.NewProvider(ts.URL, provider.ProviderLocal),
+		cache:      cache.New(time.Minute, time.Minute),
+		signatures: NewSignatureDB(),
+	}
+}
+
+func TestTraceTransaction(t *testing.T) {
+	// prepare
+	token := gcommon.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	other := gcommon.HexToAddress("0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512")
+	sender := gcommon.HexToAddress("0xFABB0ac9d68B0B445fB7357272Ff202C5651694a")
+	contractABI, err := abi.JSON(strings.NewReader(testTokenABI))
+	assert.NoError(t, err)
+
+	input, _ := contractABI.Pack("transfer", other, big.NewInt(100))
+	output, _ := contractABI.Methods["transfer"].Outputs.Pack(true)
+	revert := hexutil.MustDecode("0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"000000000000000000000000000000000000000000000000000000000000000c" +
+		"696e73756666696369656e740000000000000000000000000000000000000000")
+	frame := &common.CallFrame{
+		Type:   "CALL",
+		From:   sender,
+		To:     &token,
+		Input:  input,
+		Output: output,
+		Calls: []*common.CallFrame{
+			{
+				Type:  "STATICCALL",
+				From:  token,
+				To:    &other,
+				Input: hexutil.MustDecode("0x70a08231000000000000000000000000fabb0ac9d68b0b445fb7357272ff202c5651694a"),
+			},
+			{
+				Type:   "CALL",
+				From:   token,
+				To:     &other,
+				Input:  hexutil.MustDecode("0xdeadbeef"),
+				Output: revert,
+				Error:  "execution reverted",
+			},
+		},
+	}
+	serv := newStubService(t, map[string]any{"debug": &stubDebug{frame: frame}})
+	contract := &Contract{Account: &Account{service: serv, address: token, code: []byte{0x00}}, abi: &contractABI}
+	serv.SetCache(token, TypeContract, contract, cache.NoExpiration)
+
+	// process
+	trace, err := serv.TraceTransaction(gcommon.Hash{})
+
+	// verify
+	assert.NoError(t, err)
+	if assert.NotNil(t, trace.Method) {
+		assert.Equal(t, "transfer", trace.Method.Name)
+		assert.False(t, trace.Guessed)
+		assert.Equal(t, []any{other, big.NewInt(100)}, trace.Args)
+		assert.Equal(t, []any{true}, trace.Outputs)
+	}
+	if assert.Len(t, trace.Calls, 2) {
+		// callee without ABI is guessed by selector
+		assert.True(t, trace.Calls[0].Guessed)
+		assert.Equal(t, "balanceOf(address)", trace.Calls[0].Method.Sig)
+
+		assert.Nil(t, trace.Calls[1].Method)
+		assert.True(t, trace.Calls[1].Failed())
+		assert.Equal(t, "insufficient", trace.Calls[1].Reason)
+	}
+}
+
+func TestTraceTransaction_NotSupported(t *testing.T) {
+	// prepare
+	serv := newStubService(t, nil)
+
+	// process
+	_, err := serv.TraceTransaction(gcommon.Hash{})
+
+	// verify
+	assert.ErrorIs(t, err, ErrTraceNotSupported)
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"math/big"
 	"strings"
+
+	"github.com/dyng/ramen/internal/common"
 )
 
 const (
@@ -17,9 +19,15 @@ const (
 )
 
 type Network struct {
-	Name    string   `json:"name"`
-	Title   string   `json:"title"`
-	ChainId *big.Int `json:"chainId"`
+	Name    string     `json:"name"`
+	Title   string     `json:"title"`
+	ChainId *big.Int   `json:"chainId"`
+	ENS     *ENSConfig `json:"ens,omitempty"`
+}
+
+// ENSConfig is the ENS deployment of a network.
+type ENSConfig struct {
+	Registry common.Address `json:"registry"`
 }
 
 // NetType returns type of this network.
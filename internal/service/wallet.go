@@ -0,0 +1,117 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/dyng/ramen/internal/common"
+)
+
+// WalletAccount is a signed in account with a label, e.g. deployer.
+type WalletAccount struct {
+	Label  string
+	Signer Signer
+}
+
+// Wallet holds signed in accounts, one of which is active and used to send
+// transactions.
+type Wallet struct {
+	*sync.RWMutex
+	accounts []*WalletAccount
+	active   int
+}
+
+func NewWallet() *Wallet {
+	return &Wallet{
+		RWMutex:  &sync.RWMutex{},
+		accounts: make([]*WalletAccount, 0),
+		active:   -1,
+	}
+}
+
+// Add adds a signer to wallet and makes it active. If the account is already
+// in wallet, its signer is replaced, as well as its label when given, while
+// nonces and pending transactions of the previous signer are kept.
+func (w *Wallet) Add(label string, signer Signer) *WalletAccount {
+	w.Lock()
+	defer w.Unlock()
+
+	if i := w.indexOf(signer.GetAddress()); i >= 0 {
+		account := w.accounts[i]
+		prev, ok1 := account.Signer.(nonceSharer)
+		next, ok2 := signer.(nonceSharer)
+		if ok1 && ok2 {
+			next.setNonceManager(prev.nonceManager())
+		}
+		account.Signer = signer
+		if label != "" {
+			account.Label = label
+		}
+		w.active = i
+		return account
+	}
+
+	if label == "" {
+		label = fmt.Sprintf("Account %d", len(w.accounts)+1)
+	}
+	account := &WalletAccount{Label: label, Signer: signer}
+	w.accounts = append(w.accounts, account)
+	w.active = len(w.accounts) - 1
+	return account
+}
+
+// SetActive makes the account at given index active.
+func (w *Wallet) SetActive(index int) bool {
+	w.Lock()
+	defer w.Unlock()
+
+	if index < 0 || index >= len(w.accounts) {
+		return false
+	}
+	w.active = index
+	return true
+}
+
+// Active returns the active account, or nil if wallet is empty.
+func (w *Wallet) Active() *WalletAccount {
+	w.RLock()
+	defer w.RUnlock()
+
+	if w.active < 0 {
+		return nil
+	}
+	return w.accounts[w.active]
+}
+
+// ActiveIndex returns index of the active account, or -1 if wallet is empty.
+func (w *Wallet) ActiveIndex() int {
+	w.RLock()
+	defer w.RUnlock()
+	return w.active
+}
+
+// Accounts returns all accounts in wallet, in the order they are added.
+func (w *Wallet) Accounts() []*WalletAccount {
+	w.RLock()
+	defer w.RUnlock()
+
+	result := make([]*WalletAccount, len(w.accounts))
+	copy(result, w.accounts)
+	return result
+}
+
+// Size returns the number of accounts in wallet.
+func (w *Wallet) Size() int {
+	w.RLock()
+	defer w.RUnlock()
+	return len(w.accounts)
+}
+
+func (w *Wallet) indexOf(address common.Address) int {
+	for i, account := range w.accounts {
+		if account.Signer.GetAddress() == address {
+			return i
+		}
+	}
+	return -1
+}
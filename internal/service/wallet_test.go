@@ -0,0 +1,65 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/dyng/ramen/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWallet(t *testing.T) {
+	// prepare
+	serv := &Service{config: &config.Config{}}
+	accts, _ := serv.DeriveAccounts(TestMnemonic, "", 0, 3)
+	wallet := NewWallet()
+
+	// process
+	wallet.Add("deployer", accts[0].KeySigner)
+	wallet.Add("", accts[1].KeySigner)
+	wallet.Add("admin", accts[2].KeySigner)
+
+	// verify
+	assert.Equal(t, 3, wallet.Size())
+	assert.Equal(t, "admin", wallet.Active().Label, "the latest added account should be active")
+	assert.Equal(t, "Account 2", wallet.Accounts()[1].Label, "default label should be given")
+
+	// switch
+	assert.True(t, wallet.SetActive(1))
+	assert.False(t, wallet.SetActive(3))
+	assert.Equal(t, accts[1].GetAddress(), wallet.Active().Signer.GetAddress())
+}
+
+func TestWalletAddExisting(t *testing.T) {
+	// prepare
+	serv := &Service{config: &config.Config{}}
+	accts, _ := serv.DeriveAccounts(TestMnemonic, "", 0, 2)
+	wallet := NewWallet()
+	wallet.Add("deployer", accts[0].KeySigner)
+	wallet.Add("user", accts[1].KeySigner)
+
+	// process
+	wallet.Add("", accts[0].KeySigner)
+
+	// verify
+	assert.Equal(t, 2, wallet.Size(), "the same account should not be added twice")
+	assert.Equal(t, "deployer", wallet.Active().Label, "label should be kept and account should be active")
+}
+
+func TestWalletAddExisting_KeepNonces(t *testing.T) {
+	// prepare
+	serv := &Service{config: &config.Config{}}
+	first, _ := serv.GetHDSigner(TestMnemonic, "", 0)
+	second, _ := serv.GetHDSigner(TestMnemonic, "", 0)
+	wallet := NewWallet()
+	wallet.Add("deployer", first.KeySigner)
+	txn := trackTxn(first.nonces, 5)
+
+	// process
+	wallet.Add("", second.KeySigner)
+
+	// verify
+	pending := wallet.Active().Signer.GetPendingTxns()
+	if assert.Len(t, pending, 1, "pending transactions should survive signing in again") {
+		assert.Equal(t, txn.Hash(), pending[0].Hash)
+	}
+}
@@ -18,7 +18,12 @@ type Account struct {
 	app *App
 
 	accountInfo     *AccountInfo
+	tabs            *Tabs
 	transactionList *TransactionList
+	tokenList       *TokenList
+	nftList         *NFTList
+	contractViewer  *ContractViewer
+	storageViewer   *StorageViewer
 	methodCall      *MethodCallDialog
 	importABI       *ImportABIDialog
 	account         *serv.Account
@@ -31,6 +36,7 @@ type AccountInfo struct {
 	address     *util.Section
 	accountType *util.Section
 	balance     *util.Section
+	proxy       *util.Section
 }
 
 func NewAccount(app *App) *Account {
@@ -59,6 +65,7 @@ func (a *Account) SetAccount(account *serv.Account) {
 	a.transactionList.SetBaseAccount(&base)
 
 	// populate contract field if account is a contract
+	a.contract = nil
 	if account.IsContract() {
 		contract, err := account.AsContract()
 		if err == nil {
@@ -70,6 +77,9 @@ func (a *Account) SetAccount(account *serv.Account) {
 		}
 	}
 
+	a.contractViewer.SetContract(a.contract)
+	a.storageViewer.SetContract(a.contract)
+
 	// refresh
 	a.refresh()
 }
@@ -84,12 +94,14 @@ func (a *Account) initLayout() {
 		address:     util.NewSectionWithStyle("Address", util.NAValue, s),
 		accountType: util.NewSectionWithStyle("Type", util.NAValue, s),
 		balance:     util.NewSectionWithStyle("Balance", util.NAValue, s),
+		proxy:       util.NewSectionWithStyle("Implementation", util.NAValue, s),
 	}
 
 	info := tview.NewTable()
 	accountInfo.accountType.AddToTable(info, 0, 0)
 	accountInfo.address.AddToTable(info, 1, 0)
 	accountInfo.balance.AddToTable(info, 2, 0)
+	accountInfo.proxy.AddToTable(info, 3, 0)
 
 	accountInfo.SetDirection(tview.FlexColumn)
 	accountInfo.AddItem(accountInfo.avatar, style.AvatarSize*2+1, 0, false)
@@ -110,6 +122,35 @@ func (a *Account) initLayout() {
 	transactions.SetBorderColor(s.BorderColor2)
 	a.transactionList = transactions
 
+	// Tokens
+	tokens := NewTokenList(a.app)
+	tokens.SetTitleColor(s.TitleColor2)
+	tokens.SetBorderColor(s.BorderColor2)
+	a.tokenList = tokens
+
+	// NFTs
+	nfts := NewNFTList(a.app)
+	nfts.SetTitleColor(s.TitleColor2)
+	nfts.SetBorderColor(s.BorderColor2)
+	a.nftList = nfts
+
+	// Contract
+	contractViewer := NewContractViewer(a.app)
+	a.contractViewer = contractViewer
+
+	// Storage
+	storageViewer := NewStorageViewer(a.app)
+	a.storageViewer = storageViewer
+
+	// Tabs
+	tabs := NewTabs(a.app)
+	tabs.AddTab("Transactions", transactions)
+	tabs.AddTab("Tokens", tokens)
+	tabs.AddTab("NFTs", nfts)
+	tabs.AddTab("Contract", contractViewer)
+	tabs.AddTab("Storage", storageViewer)
+	a.tabs = tabs
+
 	// Root
 	flex := tview.NewFlex()
 	flex.SetBorder(true)
@@ -118,7 +159,7 @@ func (a *Account) initLayout() {
 	flex.SetTitleColor(s.TitleColor)
 	flex.SetDirection(tview.FlexRow)
 	flex.AddItem(accountInfo, 0, 2, false)
-	flex.AddItem(transactions, 0, 8, true)
+	flex.AddItem(tabs, 0, 8, true)
 	a.Flex = flex
 }
 
@@ -146,7 +187,19 @@ func (a *Account) KeyMaps() util.KeyMaps {
 		},
 	})
 
-	return keymaps
+	// KeyI: import ABI or storage layout of a contract
+	keymaps = append(keymaps, util.KeyMap{
+		Key:         util.KeyI,
+		Shortcut:    "i",
+		Description: "Import ABI",
+		Handler: func(*tcell.EventKey) {
+			if a.account.IsContract() {
+				a.ShowImportABIDialog()
+			}
+		},
+	})
+
+	return keymaps.Add(a.tabs.KeyMaps())
 }
 
 func (a *Account) ShowMethodCallDialog() {
@@ -176,6 +229,10 @@ func (a *Account) onNewBlock(block *common.Block) {
 	// update current account
 	a.account.UpdateBalance()
 
+	// fetch receipts to show status of transactions
+	a.transactionList.PrefetchReceipts(txns)
+	a.transactionList.PrefetchNames(txns)
+
 	a.app.QueueUpdateDraw(func() {
 		a.refreshBalance()
 		a.transactionList.FilterAndPrependTransactions(txns)
@@ -185,7 +242,13 @@ func (a *Account) onNewBlock(block *common.Block) {
 func (a *Account) refresh() {
 	addr := a.account.GetAddress()
 	a.accountInfo.address.SetText(addr.Hex())
-	a.accountInfo.accountType.SetText(StyledAccountType(a.account.GetType()))
+	a.loadNameAsync()
+	accountType := StyledAccountType(a.account.GetType())
+	if a.contract != nil && a.contract.GetName() != "" {
+		accountType += " " + StyledContractName(a.contract.GetName())
+	}
+	a.accountInfo.accountType.SetText(accountType)
+	a.accountInfo.proxy.SetText(StyledProxy(a.contract))
 
 	// avatar
 	a.accountInfo.avatar.SetAddress(addr)
@@ -196,6 +259,34 @@ func (a *Account) refresh() {
 
 	// update transaction history asynchronously
 	a.transactionList.LoadAsync(a.account.GetTransactions)
+
+	// update token balances asynchronously
+	a.tokenList.LoadAsync(func() ([]*serv.TokenBalance, error) {
+		return a.app.service.GetTokenBalances(addr)
+	})
+
+	// update NFTs asynchronously
+	a.nftList.LoadAsync(func() ([]*serv.NFT, error) {
+		return a.app.service.GetNFTs(addr)
+	})
+}
+
+func (a *Account) loadNameAsync() {
+	addr := a.account.GetAddress()
+	go func() {
+		name, err := a.app.service.GetENS().LookupAddress(addr)
+		if err != nil {
+			log.Error("Failed to look up ENS name", "address", addr, "error", err)
+			return
+		}
+
+		a.app.QueueUpdateDraw(func() {
+			// account may have changed during loading
+			if a.account.GetAddress() == addr {
+				a.accountInfo.address.SetText(StyledAddressWithName(addr.Hex(), name))
+			}
+		})
+	}()
 }
 
 func (a *Account) refreshBalance() {
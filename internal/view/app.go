@@ -101,6 +101,11 @@ func (a *App) firstSync() error {
 		}
 	})
 
+	// load latest blocks
+	a.root.blocks.LoadAsync(func() ([]*common.Block, error) {
+		return a.service.GetLatestBlocks(BlockListInitSize)
+	})
+
 	// start syncer
 	if err := a.syncer.Start(); err != nil {
 		log.Error("Failed to start syncer", "error", err)
@@ -0,0 +1,152 @@
+package view
+
+import (
+	"fmt"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/dyng/ramen/internal/view/format"
+	"github.com/dyng/ramen/internal/view/style"
+	"github.com/dyng/ramen/internal/view/util"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+type BlockDetail struct {
+	*tview.Flex
+	app *App
+
+	block           *common.Block
+	number          *util.Section
+	hash            *util.Section
+	parentHash      *util.Section
+	timestamp       *util.Section
+	miner           *util.Section
+	txnCount        *util.Section
+	gasUsed         *util.Section
+	gasLimit        *util.Section
+	baseFee         *util.Section
+	transactionList *TransactionList
+}
+
+func NewBlockDetail(app *App) *BlockDetail {
+	bd := &BlockDetail{
+		Flex: tview.NewFlex(),
+		app:  app,
+	}
+
+	// setup layout
+	bd.initLayout()
+
+	// setup keymap
+	bd.initKeymap()
+
+	return bd
+}
+
+func (b *BlockDetail) initLayout() {
+	s := b.app.config.Style()
+
+	b.SetDirection(tview.FlexRow)
+	b.SetBorder(true)
+	b.SetTitle(style.BoldPadding("Block Detail"))
+	b.SetTitleColor(s.TitleColor)
+	b.SetBorderColor(s.BorderColor)
+
+	table := tview.NewTable()
+	row := 0
+
+	b.number = util.NewSectionWithStyle("Number", util.EmptyValue, s)
+	b.number.AddToTable(table, Inc(&row), 0)
+
+	b.hash = util.NewSectionWithStyle("Hash", util.EmptyValue, s)
+	b.hash.AddToTable(table, Inc(&row), 0)
+
+	b.parentHash = util.NewSectionWithStyle("ParentHash", util.EmptyValue, s)
+	b.parentHash.AddToTable(table, Inc(&row), 0)
+
+	b.timestamp = util.NewSectionWithStyle("Timestamp", util.EmptyValue, s)
+	b.timestamp.AddToTable(table, Inc(&row), 0)
+
+	b.miner = util.NewSectionWithStyle("Miner", util.EmptyValue, s)
+	b.miner.AddToTable(table, Inc(&row), 0)
+
+	b.txnCount = util.NewSectionWithStyle("Txns", util.EmptyValue, s)
+	b.txnCount.AddToTable(table, Inc(&row), 0)
+
+	b.gasUsed = util.NewSectionWithStyle("GasUsed", util.EmptyValue, s)
+	b.gasUsed.AddToTable(table, Inc(&row), 0)
+
+	b.gasLimit = util.NewSectionWithStyle("GasLimit", util.EmptyValue, s)
+	b.gasLimit.AddToTable(table, Inc(&row), 0)
+
+	b.baseFee = util.NewSectionWithStyle("BaseFee", util.EmptyValue, s)
+	b.baseFee.AddToTable(table, Inc(&row), 0)
+
+	// Transactions
+	transactions := NewTransactionList(b.app, false)
+	transactions.SetTitleColor(s.TitleColor2)
+	transactions.SetBorderColor(s.BorderColor2)
+	b.transactionList = transactions
+
+	// add to layout
+	b.AddItem(table, row, 0, false)
+	b.AddItem(transactions, 0, 1, true)
+}
+
+func (b *BlockDetail) initKeymap() {
+	InitKeymap(b, b.app)
+}
+
+// KeyMaps implements bodyPage
+func (b *BlockDetail) KeyMaps() util.KeyMaps {
+	keymaps := make(util.KeyMaps, 0)
+
+	// KeyN: jump to miner's account page
+	keymaps = append(keymaps, util.KeyMap{
+		Key:         util.KeyN,
+		Shortcut:    "n",
+		Description: "To Miner",
+		Handler: func(*tcell.EventKey) {
+			b.ViewMiner()
+		},
+	})
+
+	return keymaps.Add(b.transactionList.KeyMaps())
+}
+
+func (b *BlockDetail) SetBlock(block *common.Block) {
+	b.block = block
+	b.refresh()
+}
+
+// ViewMiner jumps to the miner's account page
+func (b *BlockDetail) ViewMiner() {
+	address := b.block.Coinbase().Hex()
+	account, err := b.app.service.GetAccount(address)
+	if err != nil {
+		log.Error("Failed to fetch account of given address", "address", address, "error", err)
+		b.app.root.NotifyError(format.FineErrorMessage(
+			"Failed to fetch account of address %s", address, err))
+	} else {
+		b.app.root.ShowAccountPage(account)
+	}
+}
+
+func (b *BlockDetail) refresh() {
+	block := b.block
+	b.number.SetText(block.Number().String())
+	b.hash.SetText(block.Hash().Hex())
+	b.parentHash.SetText(block.ParentHash().Hex())
+	b.timestamp.SetText(fmt.Sprintf("%s (%s)", format.ToDatetime(block.Time()), format.ToAge(block.Time())))
+	b.miner.SetText(block.Coinbase().Hex())
+	b.txnCount.SetText(fmt.Sprint(block.Transactions().Len()))
+	b.gasUsed.SetText(StyledGasUsage(block))
+	b.gasLimit.SetText(fmt.Sprint(block.GasLimit()))
+	b.baseFee.SetText(StyledBaseFee(block))
+
+	// load transactions of this block
+	b.transactionList.LoadAsync(func() (common.Transactions, error) {
+		return b.app.service.GetTransactionsByBlock(block)
+	})
+}
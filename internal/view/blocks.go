@@ -0,0 +1,217 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/dyng/ramen/internal/service"
+	"github.com/dyng/ramen/internal/view/format"
+	"github.com/dyng/ramen/internal/view/style"
+	"github.com/dyng/ramen/internal/view/util"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	// BlockListLimit is the length limit of block list
+	BlockListLimit = 100
+	// BlockListInitSize is the number of blocks loaded at startup
+	BlockListInitSize = 20
+)
+
+type BlockList struct {
+	*tview.Table
+	app    *App
+	loader *util.Loader
+
+	blocks []*common.Block
+}
+
+func NewBlockList(app *App) *BlockList {
+	b := &BlockList{
+		Table:  tview.NewTable(),
+		app:    app,
+		loader: util.NewLoader(app.Application),
+		blocks: []*common.Block{},
+	}
+
+	// setup layout
+	b.initLayout()
+
+	// setup keymap
+	b.initKeymap()
+
+	// subscribe to new blocks
+	app.eventBus.Subscribe(service.TopicNewBlock, b.onNewBlock)
+
+	return b
+}
+
+func (b *BlockList) initLayout() {
+	s := b.app.config.Style()
+
+	b.SetBorder(true)
+	b.SetTitle(style.BoldPadding("Blocks"))
+	b.SetTitleColor(s.TitleColor)
+	b.SetBorderColor(s.BorderColor)
+
+	headers := []string{"number", "age", "txns", "gas used", "gas limit", "base fee", "miner"}
+	for i, header := range headers {
+		b.SetCell(0, i,
+			tview.NewTableCell(strings.ToUpper(header)).
+				SetExpansion(1).
+				SetAlign(tview.AlignLeft).
+				SetStyle(s.TableHeaderStyle).
+				SetSelectable(false))
+	}
+	b.SetSelectable(true, false)
+	b.SetFixed(1, 1)
+	b.SetSelectedFunc(b.handleSelected)
+
+	// loader
+	b.loader.SetTitleColor(s.PrgBarTitleColor)
+	b.loader.SetBorderColor(s.PrgBarBorderColor)
+	b.loader.SetCellColor(s.PrgBarCellColor)
+}
+
+func (b *BlockList) initKeymap() {
+	InitKeymap(b, b.app)
+}
+
+// KeyMaps implements bodyPage
+func (b *BlockList) KeyMaps() util.KeyMaps {
+	keymaps := make(util.KeyMaps, 0)
+
+	// KeyN: jump to miner's account page
+	keymaps = append(keymaps, util.KeyMap{
+		Key:         util.KeyN,
+		Shortcut:    "n",
+		Description: "To Miner",
+		Handler: func(*tcell.EventKey) {
+			b.ViewMiner()
+		},
+	})
+
+	return keymaps
+}
+
+// PrependBlocks prepends blocks to existing blocks
+func (b *BlockList) PrependBlocks(blocks []*common.Block) {
+	prepended := append(blocks, b.blocks...)
+	b.SetBlocks(prepended)
+}
+
+// SetBlocks sets a block list
+func (b *BlockList) SetBlocks(blocks []*common.Block) {
+	if len(blocks) > BlockListLimit {
+		blocks = blocks[:BlockListLimit]
+	}
+	b.blocks = blocks
+	b.refresh()
+}
+
+// LoadAsync loads blocks asynchronously
+func (b *BlockList) LoadAsync(loader func() ([]*common.Block, error)) {
+	// clear current content
+	b.Clear()
+
+	// start loading animation
+	b.loader.Start()
+	b.loader.Display(true)
+
+	go func() {
+		blocks, err := loader()
+		b.app.QueueUpdateDraw(func() {
+			// stop loading animation
+			b.loader.Stop()
+			b.loader.Display(false)
+
+			if err == nil {
+				if blocks != nil {
+					b.SetBlocks(blocks)
+				}
+			} else {
+				log.Error("Failed to load blocks", "error", err)
+				b.app.root.NotifyError(format.FineErrorMessage("Error occurs when loading blocks.", err))
+			}
+		})
+	}()
+}
+
+// ViewMiner jumps to the miner's account page
+func (b *BlockList) ViewMiner() {
+	current := b.selection()
+	if current == nil {
+		return
+	}
+
+	address := current.Coinbase().Hex()
+	account, err := b.app.service.GetAccount(address)
+	if err != nil {
+		log.Error("Failed to fetch account of given address", "address", address, "error", err)
+		b.app.root.NotifyError(format.FineErrorMessage(
+			"Failed to fetch account of address %s", address, err))
+	} else {
+		b.app.root.ShowAccountPage(account)
+	}
+}
+
+func (b *BlockList) Clear() {
+	for i := b.GetRowCount() - 1; i > 0; i-- {
+		b.RemoveRow(i)
+	}
+}
+
+func (b *BlockList) refresh() {
+	// clear previous content at first
+	b.Clear()
+
+	for i, block := range b.blocks {
+		row := i + 1
+
+		j := 0
+		b.SetCell(row, Inc(&j), tview.NewTableCell(block.Number().String()))
+		b.SetCell(row, Inc(&j), tview.NewTableCell(format.ToAge(block.Time())))
+		b.SetCell(row, Inc(&j), tview.NewTableCell(fmt.Sprint(block.Transactions().Len())))
+		b.SetCell(row, Inc(&j), tview.NewTableCell(StyledGasUsage(block)))
+		b.SetCell(row, Inc(&j), tview.NewTableCell(fmt.Sprint(block.GasLimit())))
+		b.SetCell(row, Inc(&j), tview.NewTableCell(StyledBaseFee(block)))
+		b.SetCell(row, Inc(&j), tview.NewTableCell(format.TruncateText(block.Coinbase().Hex(), 20)))
+	}
+}
+
+// handleSelected shows detail of selected block
+func (b *BlockList) handleSelected(row int, column int) {
+	if row > 0 && row <= len(b.blocks) {
+		b.app.root.ShowBlockPage(b.blocks[row-1])
+	}
+}
+
+func (b *BlockList) selection() *common.Block {
+	row, _ := b.GetSelection()
+	if row > 0 && row <= len(b.blocks) {
+		return b.blocks[row-1]
+	} else {
+		return nil
+	}
+}
+
+func (b *BlockList) onNewBlock(block *common.Block) {
+	b.app.QueueUpdateDraw(func() {
+		b.PrependBlocks([]*common.Block{block})
+	})
+}
+
+// SetRect implements tview.SetRect
+func (b *BlockList) SetRect(x, y, width, height int) {
+	b.Table.SetRect(x, y, width, height)
+	b.loader.SetCentral(x, y, width, height)
+}
+
+// Draw implements tview.Draw
+func (b *BlockList) Draw(screen tcell.Screen) {
+	b.Table.Draw(screen)
+	b.loader.Draw(screen)
+}
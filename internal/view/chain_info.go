@@ -90,6 +90,11 @@ func (ci *ChainInfo) SetEthPrice(price decimal.Decimal) {
 	ci.prevPrice = &price
 }
 
+// GetEthPrice returns the latest known ether price, nil if unknown.
+func (ci *ChainInfo) GetEthPrice() *decimal.Decimal {
+	return ci.prevPrice
+}
+
 func (ci *ChainInfo) onNewBlock(block *common.Block) {
 	ci.app.QueueUpdateDraw(func() {
 		ci.SetHeight(block.Number().Uint64())
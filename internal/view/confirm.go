@@ -0,0 +1,128 @@
+package view
+
+import (
+	"github.com/dyng/ramen/internal/view/style"
+	"github.com/dyng/ramen/internal/view/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	// confirmDialogMinHeight is the minimum height of the confirm dialog.
+	confirmDialogMinHeight = 16
+	// confirmDialogMinWidth is the minimum width of the confirm dialog.
+	confirmDialogMinWidth = 50
+)
+
+// ConfirmDialog asks user to confirm an action before it is taken, e.g.
+// signing a transaction.
+type ConfirmDialog struct {
+	*tview.TextView
+	app       *App
+	display   bool
+	lastFocus tview.Primitive
+
+	onConfirm func()
+}
+
+func NewConfirmDialog(app *App) *ConfirmDialog {
+	d := &ConfirmDialog{
+		app:     app,
+		display: false,
+	}
+
+	// setup layout
+	d.initLayout()
+
+	// setup keymap
+	d.initKeymap()
+
+	return d
+}
+
+func (d *ConfirmDialog) initLayout() {
+	s := d.app.config.Style()
+
+	tv := tview.NewTextView()
+	tv.SetBorder(true)
+	tv.SetBorderColor(s.DialogBorderColor)
+	tv.SetTitleColor(s.TitleColor)
+	tv.SetDynamicColors(true)
+	tv.SetWrap(true)
+	d.TextView = tv
+}
+
+func (d *ConfirmDialog) initKeymap() {
+	InitKeymap(d, d.app)
+}
+
+// KeyMaps implements KeymapPrimitive
+func (d *ConfirmDialog) KeyMaps() util.KeyMaps {
+	keymaps := make(util.KeyMaps, 0)
+	keymaps = append(keymaps, util.NewSimpleKey(tcell.KeyEsc, d.Hide))
+	keymaps = append(keymaps, util.NewSimpleKey(util.KeyN, d.Hide))
+	keymaps = append(keymaps, util.NewSimpleKey(tcell.KeyEnter, d.confirm))
+	keymaps = append(keymaps, util.NewSimpleKey(util.KeyY, d.confirm))
+	return keymaps
+}
+
+// SetContent sets the description of action and the callback to run when confirmed
+func (d *ConfirmDialog) SetContent(title string, text string, onConfirm func()) {
+	d.SetTitle(style.BoldPadding(title))
+	d.SetText(text + "\n\n[dimgray]Press <enter> or <y> to confirm, <esc> or <n> to cancel.[-]")
+	d.ScrollToBeginning()
+	d.onConfirm = onConfirm
+}
+
+func (d *ConfirmDialog) confirm() {
+	d.Hide()
+	if d.onConfirm != nil {
+		d.onConfirm()
+	}
+}
+
+func (d *ConfirmDialog) Show() {
+	if !d.display {
+		// save last focused element
+		d.lastFocus = d.app.GetFocus()
+
+		d.Display(true)
+		d.app.SetFocus(d)
+	}
+}
+
+func (d *ConfirmDialog) Hide() {
+	if d.display {
+		d.Display(false)
+		d.app.SetFocus(d.lastFocus)
+	}
+}
+
+func (d *ConfirmDialog) Display(display bool) {
+	d.display = display
+}
+
+func (d *ConfirmDialog) IsDisplay() bool {
+	return d.display
+}
+
+// Draw implements tview.Primitive
+func (d *ConfirmDialog) Draw(screen tcell.Screen) {
+	if d.display {
+		d.TextView.Draw(screen)
+	}
+}
+
+func (d *ConfirmDialog) SetCentral(x int, y int, width int, height int) {
+	dialogWidth := width - width/3
+	dialogHeight := height / 2
+	if dialogHeight < confirmDialogMinHeight {
+		dialogHeight = confirmDialogMinHeight
+	}
+	if dialogWidth < confirmDialogMinWidth {
+		dialogWidth = confirmDialogMinWidth
+	}
+	dialogX := x + ((width - dialogWidth) / 2)
+	dialogY := y + ((height - dialogHeight) / 2)
+	d.TextView.SetRect(dialogX, dialogY, dialogWidth, dialogHeight)
+}
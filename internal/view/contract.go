@@ -0,0 +1,209 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dyng/ramen/internal/service"
+	"github.com/dyng/ramen/internal/view/format"
+	"github.com/dyng/ramen/internal/view/style"
+	"github.com/dyng/ramen/internal/view/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	// pageSource is the page showing a source file
+	pageSource = "source"
+	// pageABI is the page listing functions, events and errors of ABI
+	pageABI = "abi"
+
+	// abiFileName is the name of entry for ABI in file list
+	abiFileName = "<ABI>"
+)
+
+// ContractViewer shows verified source code of a contract split into files,
+// and its ABI. Files are listed on the left, and the selected one is shown on
+// the right.
+type ContractViewer struct {
+	*tview.Flex
+	app *App
+
+	files   *tview.Table
+	content *tview.Pages
+	source  *tview.TextView
+	abi     *tview.Table
+
+	sources []service.SourceFile
+	entries []service.ABIEntry
+}
+
+func NewContractViewer(app *App) *ContractViewer {
+	v := &ContractViewer{
+		app: app,
+	}
+
+	// setup layout
+	v.initLayout()
+
+	return v
+}
+
+func (v *ContractViewer) initLayout() {
+	s := v.app.config.Style()
+
+	// file list
+	files := tview.NewTable()
+	files.SetBorder(true)
+	files.SetBorderColor(s.BorderColor2)
+	files.SetTitle(style.Padding("Files"))
+	files.SetTitleColor(s.TitleColor2)
+	files.SetSelectable(true, false)
+	files.SetSelectionChangedFunc(func(row, column int) {
+		v.showFile(row)
+	})
+	files.SetSelectedFunc(func(row, column int) {
+		v.app.SetFocus(v.content)
+	})
+	files.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if util.AsKey(event) == tcell.KeyTAB {
+			v.app.SetFocus(v.content)
+			return nil
+		}
+		return event
+	})
+	v.files = files
+
+	// source code
+	source := tview.NewTextView()
+	source.SetBorder(true)
+	source.SetBorderColor(s.BorderColor2)
+	source.SetTitleColor(s.TitleColor2)
+	source.SetDynamicColors(true)
+	source.SetWrap(false)
+	v.source = source
+
+	// abi
+	abiTable := tview.NewTable()
+	abiTable.SetBorder(true)
+	abiTable.SetBorderColor(s.BorderColor2)
+	abiTable.SetTitle(style.Padding("ABI"))
+	abiTable.SetTitleColor(s.TitleColor2)
+	abiTable.SetSelectable(true, false)
+	abiTable.SetFixed(1, 0)
+	headers := []string{"kind", "name", "signature", "selector", "mutability"}
+	for i, header := range headers {
+		abiTable.SetCell(0, i,
+			tview.NewTableCell(strings.ToUpper(header)).
+				SetExpansion(1).
+				SetAlign(tview.AlignLeft).
+				SetStyle(s.TableHeaderStyle).
+				SetSelectable(false))
+	}
+	v.abi = abiTable
+
+	content := tview.NewPages()
+	content.AddPage(pageSource, source, true, true)
+	content.AddPage(pageABI, abiTable, true, false)
+	content.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		key := util.AsKey(event)
+		if key == tcell.KeyTAB || key == tcell.KeyEsc {
+			v.app.SetFocus(v.files)
+			return nil
+		}
+		return event
+	})
+	v.content = content
+
+	flex := tview.NewFlex().SetDirection(tview.FlexColumn)
+	flex.AddItem(files, 0, 2, true)
+	flex.AddItem(content, 0, 8, false)
+	v.Flex = flex
+}
+
+// SetContract shows source code and ABI of contract, nil means current
+// account is not a contract.
+func (v *ContractViewer) SetContract(contract *service.Contract) {
+	v.sources = []service.SourceFile{}
+	v.entries = []service.ABIEntry{}
+	if contract != nil {
+		v.sources = contract.GetSourceFiles()
+		v.entries = contract.GetABIEntries()
+	}
+	v.refresh(contract)
+}
+
+func (v *ContractViewer) refresh(contract *service.Contract) {
+	v.files.Clear()
+	v.refreshABI()
+
+	row := 0
+	for _, file := range v.sources {
+		v.files.SetCell(Inc(&row), 0, tview.NewTableCell(tview.Escape(file.Name)).SetExpansion(1))
+	}
+	if len(v.entries) > 0 {
+		v.files.SetCell(Inc(&row), 0, tview.NewTableCell(abiFileName).SetExpansion(1))
+	}
+
+	if row > 0 {
+		v.files.Select(0, 0)
+		v.showFile(0)
+		return
+	}
+
+	// nothing to show
+	v.content.SwitchToPage(pageSource)
+	v.source.SetTitle("")
+	if contract == nil {
+		v.source.SetText("[dimgray]Not a contract account[-]")
+	} else {
+		v.source.SetText("[dimgray]Source code is not verified, press 'i' to import ABI[-]")
+	}
+}
+
+func (v *ContractViewer) refreshABI() {
+	// clear previous content at first
+	for i := v.abi.GetRowCount() - 1; i > 0; i-- {
+		v.abi.RemoveRow(i)
+	}
+
+	for i, entry := range v.entries {
+		row := i + 1
+		j := 0
+		v.abi.SetCell(row, Inc(&j), tview.NewTableCell(styledABIKind(entry.Kind)))
+		v.abi.SetCell(row, Inc(&j), tview.NewTableCell(entry.Name))
+		v.abi.SetCell(row, Inc(&j), tview.NewTableCell(tview.Escape(entry.Signature)))
+		v.abi.SetCell(row, Inc(&j), tview.NewTableCell(entry.Selector))
+		v.abi.SetCell(row, Inc(&j), tview.NewTableCell(entry.Mutability))
+	}
+	v.abi.ScrollToBeginning()
+}
+
+// showFile shows the source file at given row of file list, or ABI if the
+// row is after all files.
+func (v *ContractViewer) showFile(row int) {
+	if row < 0 {
+		return
+	}
+
+	if row < len(v.sources) {
+		file := v.sources[row]
+		v.source.SetTitle(style.Padding(tview.Escape(file.Name)))
+		v.source.SetText(format.HighlightSolidity(file.Content))
+		v.source.ScrollToBeginning()
+		v.content.SwitchToPage(pageSource)
+	} else if len(v.entries) > 0 {
+		v.content.SwitchToPage(pageABI)
+	}
+}
+
+func styledABIKind(kind string) string {
+	switch kind {
+	case service.ABIFunction:
+		return fmt.Sprintf("[dodgerblue]%s[-]", kind)
+	case service.ABIEvent:
+		return fmt.Sprintf("[lightgreen]%s[-]", kind)
+	default:
+		return fmt.Sprintf("[crimson]%s[-]", kind)
+	}
+}
@@ -0,0 +1,161 @@
+package view
+
+import (
+	"fmt"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/dyng/ramen/internal/common/conv"
+	"github.com/dyng/ramen/internal/service"
+	"github.com/dyng/ramen/internal/view/format"
+	"github.com/dyng/ramen/internal/view/style"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/rivo/tview"
+)
+
+type EventLogList struct {
+	*tview.Table
+	app *App
+
+	logs    []*service.EventLog
+	rowLogs []int // index of log for each row
+}
+
+func NewEventLogList(app *App) *EventLogList {
+	l := &EventLogList{
+		Table: tview.NewTable(),
+		app:   app,
+	}
+
+	// setup layout
+	l.initLayout()
+
+	return l
+}
+
+func (l *EventLogList) initLayout() {
+	s := l.app.config.Style()
+
+	l.SetBorder(true)
+	l.SetBorderColor(s.BorderColor2)
+	l.SetTitle(style.BoldPadding("Logs"))
+	l.SetTitleColor(s.TitleColor2)
+	l.SetSelectable(true, false)
+}
+
+// SetLogs sets decoded logs to display
+func (l *EventLogList) SetLogs(logs []*service.EventLog) {
+	l.logs = logs
+	l.refresh()
+}
+
+// ViewEmitter jumps to the account page of selected log's emitter
+func (l *EventLogList) ViewEmitter() {
+	current := l.selection()
+	if current == nil {
+		return
+	}
+
+	address := current.Address.Hex()
+	account, err := l.app.service.GetAccount(address)
+	if err != nil {
+		log.Error("Failed to fetch account of given address", "address", address, "error", err)
+		l.app.root.NotifyError(format.FineErrorMessage(
+			"Failed to fetch account of address %s", address, err))
+	} else {
+		l.app.root.ShowAccountPage(account)
+	}
+}
+
+func (l *EventLogList) Clear() {
+	l.Table.Clear()
+	l.rowLogs = nil
+}
+
+func (l *EventLogList) refresh() {
+	s := l.app.config.Style()
+
+	// clear previous content at first
+	l.Clear()
+
+	// show log count
+	l.SetTitle(style.BoldPadding(fmt.Sprintf("Logs[[coral]%d[-]]", len(l.logs))))
+
+	row := 0
+	addRow := func(i int, index string, key string, value string) {
+		l.SetCell(row, 0, tview.NewTableCell(index))
+		l.SetCell(row, 1, tview.NewTableCell(key).SetTextColor(s.SectionColor2))
+		l.SetCell(row, 2, tview.NewTableCell(value).SetExpansion(1))
+		l.rowLogs = append(l.rowLogs, i)
+		row++
+	}
+
+	for i, el := range l.logs {
+		index := fmt.Sprintf("[dimgray]#%d[-]", el.Index)
+		emitter := format.TruncateText(el.Address.Hex(), 42)
+
+		if !el.IsDecoded() {
+			addRow(i, index, "[crimson]unknown[-]", emitter)
+			for _, candidate := range el.Candidates {
+				addRow(i, "", "candidate", tview.Escape(candidate))
+			}
+			if topic0, ok := el.Topic0(); ok {
+				addRow(i, "", "topic0", topic0.Hex())
+			}
+			for j, topic := range el.Topics[1:] {
+				addRow(i, "", fmt.Sprintf("topic%d", j+1), topic.Hex())
+			}
+			addRow(i, "", "data", format.BytesToString(el.Data, 64))
+			continue
+		}
+
+		if el.Guessed {
+			addRow(i, index, "[dodgerblue::b]event[-:-:-]", fmt.Sprintf("[::b]%s[::-] %s [dimgray](guessed)[-]", el.Event.Name, emitter))
+			if len(el.Candidates) > 1 {
+				for _, candidate := range el.Candidates {
+					addRow(i, "", "candidate", tview.Escape(candidate))
+				}
+			}
+		} else {
+			addRow(i, index, "[dodgerblue::b]event[-:-:-]", fmt.Sprintf("[::b]%s[::-] %s", el.Event.Name, emitter))
+		}
+		for j, input := range el.Event.Inputs {
+			addRow(i, "", styledEventArgName(input), formatEventArg(input, el.Args[j]))
+		}
+	}
+}
+
+func (l *EventLogList) selection() *service.EventLog {
+	row, _ := l.GetSelection()
+	if row >= 0 && row < len(l.rowLogs) {
+		return l.logs[l.rowLogs[row]]
+	} else {
+		return nil
+	}
+}
+
+func styledEventArgName(input abi.Argument) string {
+	name := input.Name
+	if name == "" {
+		name = "<unknown>"
+	}
+	if input.Indexed {
+		name += " [dimgray](indexed)[-]"
+	}
+	return name
+}
+
+func formatEventArg(input abi.Argument, val any) string {
+	if input.Indexed && service.IsHashedTopic(input.Type) {
+		if hash, ok := val.(common.Hash); ok {
+			return fmt.Sprintf("%s [dimgray](keccak256)[-]", hash.Hex())
+		}
+	}
+
+	valStr, err := conv.PackArgument(input.Type, val)
+	if err != nil {
+		log.Error("Failed to pack argument", "value", val, "type", input.Type, "error", err)
+		return "ERROR"
+	}
+	return tview.Escape(valStr)
+}
@@ -0,0 +1,38 @@
+package format
+
+import (
+	"fmt"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/shopspring/decimal"
+)
+
+// Gwei formats a value in wei as Gwei without losing precision.
+func Gwei(wei common.BigInt) string {
+	if wei == nil {
+		return "0"
+	}
+	return decimal.NewFromBigInt(wei, -9).String()
+}
+
+// Ether formats a value in wei as Ether without losing precision.
+func Ether(wei common.BigInt) string {
+	if wei == nil {
+		return "0"
+	}
+	return decimal.NewFromBigInt(wei, -18).String()
+}
+
+// FeeBreakdown formats fee data into a one-line summary.
+func FeeBreakdown(fee *common.FeeData) string {
+	if fee == nil {
+		return "n/a"
+	}
+
+	if fee.IsDynamic() {
+		return fmt.Sprintf("Base %s + Tip %s Gwei (Max %s Gwei)",
+			Gwei(fee.BaseFee), Gwei(fee.GasTipCap), Gwei(fee.GasFeeCap))
+	} else {
+		return fmt.Sprintf("%s Gwei (Legacy)", Gwei(fee.GasPrice))
+	}
+}
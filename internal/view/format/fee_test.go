@@ -0,0 +1,23 @@
+package format
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeBreakdown(t *testing.T) {
+	dynamic := &common.FeeData{
+		BaseFee:   big.NewInt(875000000),
+		GasTipCap: big.NewInt(1000000000),
+		GasFeeCap: big.NewInt(2750000000),
+	}
+	assert.Equal(t, "Base 0.875 + Tip 1 Gwei (Max 2.75 Gwei)", FeeBreakdown(dynamic))
+
+	legacy := &common.FeeData{
+		GasPrice: big.NewInt(20000000000),
+	}
+	assert.Equal(t, "20 Gwei (Legacy)", FeeBreakdown(legacy))
+}
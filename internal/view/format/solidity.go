@@ -0,0 +1,138 @@
+package format
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	solKeywordColor = "orchid"
+	solTypeColor    = "deepskyblue"
+	solStringColor  = "lightgreen"
+	solNumberColor  = "sandybrown"
+	solCommentColor = "dimgray"
+)
+
+var (
+	solidityKeywords = map[string]bool{
+		"pragma": true, "solidity": true, "import": true, "as": true, "from": true,
+		"contract": true, "interface": true, "library": true, "abstract": true, "is": true, "using": true,
+		"function": true, "modifier": true, "event": true, "error": true, "struct": true, "enum": true,
+		"constructor": true, "fallback": true, "receive": true, "returns": true, "return": true,
+		"if": true, "else": true, "for": true, "while": true, "do": true, "break": true, "continue": true,
+		"new": true, "delete": true, "emit": true, "revert": true, "require": true, "assert": true,
+		"public": true, "private": true, "internal": true, "external": true,
+		"view": true, "pure": true, "payable": true, "constant": true, "immutable": true,
+		"override": true, "virtual": true, "memory": true, "storage": true, "calldata": true,
+		"indexed": true, "anonymous": true, "unchecked": true, "try": true, "catch": true, "assembly": true,
+		"true": true, "false": true, "this": true, "super": true, "type": true,
+	}
+
+	solidityTypePattern = regexp.MustCompile(`^(address|bool|string|bytes([1-9]|[12][0-9]|3[0-2])?|u?int(8|16|24|32|40|48|56|64|72|80|88|96|104|112|120|128|136|144|152|160|168|176|184|192|200|208|216|224|232|240|248|256)?|u?fixed[0-9x]*|mapping)$`)
+)
+
+// HighlightSolidity adds color tags to Solidity source code, brackets in code
+// are escaped so that it can be shown by a TextView with dynamic colors.
+func HighlightSolidity(code string) string {
+	src := []rune(code)
+	var sb strings.Builder
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			end := indexRune(src, i, '\n')
+			writeColored(&sb, solCommentColor, string(src[i:end]))
+			i = end
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := indexString(src, i+2, "*/")
+			writeColored(&sb, solCommentColor, string(src[i:end]))
+			i = end
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(src) && src[end] != c && src[end] != '\n' {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = minInt(end+1, len(src))
+			writeColored(&sb, solStringColor, string(src[i:end]))
+			i = end
+		case isIdentStart(c):
+			end := i + 1
+			for end < len(src) && isIdentPart(src[end]) {
+				end++
+			}
+			word := string(src[i:end])
+			if solidityKeywords[word] {
+				writeColored(&sb, solKeywordColor, word)
+			} else if solidityTypePattern.MatchString(word) {
+				writeColored(&sb, solTypeColor, word)
+			} else {
+				sb.WriteString(word)
+			}
+			i = end
+		case unicode.IsDigit(c):
+			end := i + 1
+			for end < len(src) && (isIdentPart(src[end]) || src[end] == '.') {
+				end++
+			}
+			writeColored(&sb, solNumberColor, string(src[i:end]))
+			i = end
+		default:
+			writePlain(&sb, string(c))
+			i++
+		}
+	}
+
+	return sb.String()
+}
+
+// writeColored writes text in given color. Any '[' in text is followed by the
+// color tag again, which keeps it from being parsed as part of a tag.
+func writeColored(sb *strings.Builder, color string, text string) {
+	tag := "[" + color + "]"
+	sb.WriteString(tag)
+	sb.WriteString(strings.ReplaceAll(text, "[", "["+tag))
+	sb.WriteString("[-]")
+}
+
+func writePlain(sb *strings.Builder, text string) {
+	sb.WriteString(strings.ReplaceAll(text, "[", "[[-]"))
+}
+
+// indexRune returns index of the first r since start, or length of src if not found.
+func indexRune(src []rune, start int, r rune) int {
+	for i := start; i < len(src); i++ {
+		if src[i] == r {
+			return i
+		}
+	}
+	return len(src)
+}
+
+// indexString returns index right after the first s since start, or length of
+// src if not found.
+func indexString(src []rune, start int, s string) int {
+	if i := strings.Index(string(src[start:]), s); i >= 0 {
+		return start + len([]rune(string(src[start:])[:i])) + len([]rune(s))
+	}
+	return len(src)
+}
+
+func isIdentStart(c rune) bool {
+	return c == '_' || c == '$' || unicode.IsLetter(c)
+}
+
+func isIdentPart(c rune) bool {
+	return isIdentStart(c) || unicode.IsDigit(c)
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
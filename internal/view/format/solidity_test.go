@@ -0,0 +1,23 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHighlightSolidity(t *testing.T) {
+	// process
+	text := HighlightSolidity(`uint256 x = 1; // set [x]`)
+
+	// verify
+	assert.Equal(t, "[deepskyblue]uint256[-] x = [sandybrown]1[-]; [dimgray]// set [[dimgray]x][-]", text)
+}
+
+func TestHighlightSolidity_Brackets(t *testing.T) {
+	// process
+	text := HighlightSolidity(`return a[i] + "[b]";`)
+
+	// verify
+	assert.Equal(t, `[orchid]return[-] a[[-]i] + [lightgreen]"[[lightgreen]b]"[-];`, text)
+}
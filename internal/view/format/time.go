@@ -1,7 +1,32 @@
 package format
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 func ToDatetime(sec uint64) string {
 	return time.Unix(int64(sec), 0).Format("2006-01-02 15:04:05")
 }
+
+// ToAge formats a timestamp as time elapsed since then, e.g. "12s ago".
+func ToAge(sec uint64) string {
+	return durationToAge(time.Since(time.Unix(int64(sec), 0)))
+}
+
+func durationToAge(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds ago", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
@@ -2,6 +2,7 @@ package format
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -9,3 +10,10 @@ import (
 func TestToDatetime(t *testing.T) {
 	assert.Equal(t, "2023-01-06 15:09:27", ToDatetime(1672988967))
 }
+
+func TestDurationToAge(t *testing.T) {
+	assert.Equal(t, "12s ago", durationToAge(12*time.Second))
+	assert.Equal(t, "3m ago", durationToAge(3*time.Minute+20*time.Second))
+	assert.Equal(t, "5h ago", durationToAge(5*time.Hour))
+	assert.Equal(t, "2d ago", durationToAge(50*time.Hour))
+}
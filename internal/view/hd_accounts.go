@@ -0,0 +1,249 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/dyng/ramen/internal/common/conv"
+	"github.com/dyng/ramen/internal/service"
+	"github.com/dyng/ramen/internal/view/format"
+	"github.com/dyng/ramen/internal/view/style"
+	"github.com/dyng/ramen/internal/view/util"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	// hdAccountsPageSize is the number of accounts derived at a time.
+	hdAccountsPageSize = 10
+
+	// hdAccountsDialogHeight is the height of the account picker.
+	hdAccountsDialogHeight = hdAccountsPageSize + 4
+	// hdAccountsDialogMinWidth is the minimum width of the account picker.
+	hdAccountsDialogMinWidth = 50
+)
+
+// HDAccountPicker lists accounts derived from a mnemonic with their balances,
+// and signs in the selected one. Selected account is labeled by its index
+// unless a label is given.
+type HDAccountPicker struct {
+	*tview.Table
+	app       *App
+	display   bool
+	lastFocus tview.Primitive
+	loader    *util.Loader
+
+	label      string
+	mnemonic   string
+	passphrase string
+	accounts   []*service.HDAccount
+	balances   map[common.Address]common.BigInt
+}
+
+func NewHDAccountPicker(app *App) *HDAccountPicker {
+	p := &HDAccountPicker{
+		Table:    tview.NewTable(),
+		app:      app,
+		display:  false,
+		loader:   util.NewLoader(app.Application),
+		accounts: []*service.HDAccount{},
+		balances: map[common.Address]common.BigInt{},
+	}
+
+	// setup layout
+	p.initLayout()
+
+	// setup keymap
+	p.initKeymap()
+
+	return p
+}
+
+func (p *HDAccountPicker) initLayout() {
+	s := p.app.config.Style()
+
+	p.SetBorder(true)
+	p.SetBorderColor(s.DialogBorderColor)
+	p.SetTitle(style.BoldPadding("Choose Account"))
+	p.SetTitleColor(s.TitleColor)
+
+	headers := []string{"index", "path", "address", "balance"}
+	for i, header := range headers {
+		p.SetCell(0, i,
+			tview.NewTableCell(strings.ToUpper(header)).
+				SetExpansion(1).
+				SetAlign(tview.AlignLeft).
+				SetStyle(s.TableHeaderStyle).
+				SetSelectable(false))
+	}
+	p.SetSelectable(true, false)
+	p.SetFixed(1, 1)
+	p.SetSelectedFunc(p.handleSelected)
+
+	// loader
+	p.loader.SetTitleColor(s.PrgBarTitleColor)
+	p.loader.SetBorderColor(s.PrgBarBorderColor)
+	p.loader.SetCellColor(s.PrgBarCellColor)
+}
+
+func (p *HDAccountPicker) initKeymap() {
+	InitKeymap(p, p.app)
+}
+
+// KeyMaps implements KeymapPrimitive
+func (p *HDAccountPicker) KeyMaps() util.KeyMaps {
+	keymaps := make(util.KeyMaps, 0)
+	keymaps = append(keymaps, util.NewSimpleKey(tcell.KeyEsc, p.Hide))
+	keymaps = append(keymaps, util.NewSimpleKey(util.KeyM, p.loadMore))
+	return keymaps
+}
+
+// SetMnemonic derives the first page of accounts from mnemonic and shows them
+func (p *HDAccountPicker) SetMnemonic(label string, mnemonic string, passphrase string) {
+	p.label = label
+	p.mnemonic = mnemonic
+	p.passphrase = passphrase
+	p.accounts = []*service.HDAccount{}
+	p.balances = map[common.Address]common.BigInt{}
+	p.refresh()
+	p.loadMore()
+}
+
+// loadMore derives next page of accounts, then fetches their balances.
+func (p *HDAccountPicker) loadMore() {
+	mnemonic, passphrase := p.mnemonic, p.passphrase
+	offset := len(p.accounts)
+
+	p.loader.Start()
+	p.loader.Display(true)
+
+	go func() {
+		accounts, err := p.app.service.DeriveAccounts(mnemonic, passphrase, offset, hdAccountsPageSize)
+		p.app.QueueUpdateDraw(func() {
+			p.loader.Stop()
+			p.loader.Display(false)
+
+			// mnemonic may have changed during loading
+			if p.mnemonic != mnemonic || len(p.accounts) != offset {
+				return
+			}
+
+			if err != nil {
+				log.Error("Failed to derive accounts", "error", err)
+				p.Hide()
+				p.app.root.NotifyError(format.FineErrorMessage("Failed to derive accounts", err))
+				return
+			}
+
+			p.accounts = append(p.accounts, accounts...)
+			p.refresh()
+			p.loadBalancesAsync(accounts)
+		})
+	}()
+}
+
+// loadBalancesAsync fetches latest balances of accounts one by one.
+func (p *HDAccountPicker) loadBalancesAsync(accounts []*service.HDAccount) {
+	go func() {
+		for _, account := range accounts {
+			addr := account.GetAddress()
+			bal, err := account.GetBalanceForce()
+			if err != nil {
+				log.Error("Failed to fetch balance", "address", addr, "error", err)
+				continue
+			}
+			p.app.QueueUpdateDraw(func() {
+				p.balances[addr] = bal
+				p.refresh()
+			})
+		}
+	}()
+}
+
+func (p *HDAccountPicker) refresh() {
+	// clear previous content at first
+	for i := p.GetRowCount() - 1; i > 0; i-- {
+		p.RemoveRow(i)
+	}
+
+	for i, account := range p.accounts {
+		row := i + 1
+
+		balance := util.NAValue
+		if bal, ok := p.balances[account.GetAddress()]; ok {
+			balance = conv.ToEther(bal).String()
+		}
+
+		j := 0
+		p.SetCell(row, Inc(&j), tview.NewTableCell(fmt.Sprint(account.Index)))
+		p.SetCell(row, Inc(&j), tview.NewTableCell(account.Path.String()))
+		p.SetCell(row, Inc(&j), tview.NewTableCell(account.GetAddress().Hex()))
+		p.SetCell(row, Inc(&j), tview.NewTableCell(balance))
+	}
+}
+
+func (p *HDAccountPicker) handleSelected(row int, column int) {
+	if row <= 0 || row > len(p.accounts) {
+		return
+	}
+
+	account := p.accounts[row-1]
+	label := p.label
+	if label == "" {
+		label = fmt.Sprintf("#%d", account.Index)
+	}
+	p.Hide()
+	p.app.root.SignIn(label, account.KeySigner)
+}
+
+func (p *HDAccountPicker) Show() {
+	if !p.display {
+		// save last focused element
+		p.lastFocus = p.app.GetFocus()
+
+		p.Display(true)
+		p.app.SetFocus(p)
+	}
+}
+
+// Hide closes the picker and forgets the mnemonic
+func (p *HDAccountPicker) Hide() {
+	if p.display {
+		p.mnemonic = ""
+		p.passphrase = ""
+		p.accounts = []*service.HDAccount{}
+		p.balances = map[common.Address]common.BigInt{}
+		p.Display(false)
+		p.app.SetFocus(p.lastFocus)
+	}
+}
+
+func (p *HDAccountPicker) Display(display bool) {
+	p.display = display
+}
+
+func (p *HDAccountPicker) IsDisplay() bool {
+	return p.display
+}
+
+// Draw implements tview.Primitive
+func (p *HDAccountPicker) Draw(screen tcell.Screen) {
+	if p.display {
+		p.Table.Draw(screen)
+		p.loader.Draw(screen)
+	}
+}
+
+func (p *HDAccountPicker) SetCentral(x int, y int, width int, height int) {
+	dialogWidth := width - width/4
+	dialogHeight := hdAccountsDialogHeight
+	if dialogWidth < hdAccountsDialogMinWidth {
+		dialogWidth = hdAccountsDialogMinWidth
+	}
+	dialogX := x + ((width - dialogWidth) / 2)
+	dialogY := y + ((height - dialogHeight) / 2)
+	p.Table.SetRect(dialogX, dialogY, dialogWidth, dialogHeight)
+	p.loader.SetCentral(dialogX, dialogY, dialogWidth, dialogHeight)
+}
@@ -1,11 +1,18 @@
 package view
 
 import (
+	"bytes"
 	"fmt"
+	"math/big"
+	"sort"
+	"strings"
 
 	"github.com/dyng/ramen/internal/common"
+	"github.com/dyng/ramen/internal/common/conv"
 	serv "github.com/dyng/ramen/internal/service"
+	"github.com/dyng/ramen/internal/view/format"
 	"github.com/dyng/ramen/internal/view/util"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
@@ -90,6 +97,195 @@ func StyledNetworkName(n serv.Network) string {
 	return n.Name
 }
 
+func StyledTxnStatus(status serv.TxnStatus) string {
+	switch status {
+	case serv.TxnPending:
+		return fmt.Sprintf("[sandybrown]%s[-]", status)
+	case serv.TxnMined:
+		return fmt.Sprintf("[lightgreen]%s[-]", status)
+	default:
+		return fmt.Sprintf("[dimgray]%s[-]", status)
+	}
+}
+
+// StyledReceiptStatus shows whether a transaction succeeded, nil receipt means pending.
+func StyledReceiptStatus(receipt *common.Receipt) string {
+	if receipt == nil {
+		return "[sandybrown]Pending[-]"
+	}
+	if receipt.Succeeded() {
+		return "[lightgreen]Success[-]"
+	} else {
+		return "[crimson]Failed[-]"
+	}
+}
+
+// StyledRevertReason shows decoded revert reason of a failed transaction.
+func StyledRevertReason(reason string) string {
+	return fmt.Sprintf("[crimson](%s)[-]", tview.Escape(reason))
+}
+
+// StyledSimulation shows the result of simulating a transaction, i.e. the
+// outputs of method if succeeded, or the error if failed.
+func StyledSimulation(sim *serv.Simulation) string {
+	if sim == nil {
+		return "[dimgray]not simulated[-]"
+	}
+	if !sim.Succeeded() {
+		return fmt.Sprintf("[crimson]Failed[-] [dimgray](simulated)[-] %s", tview.Escape(sim.Err.Error()))
+	}
+
+	outputs := make([]string, len(sim.Outputs))
+	for i, val := range sim.Outputs {
+		valStr, err := conv.PackArgument(sim.Method.Outputs[i].Type, val)
+		if err != nil {
+			valStr = fmt.Sprint(val)
+		}
+		outputs[i] = tview.Escape(valStr)
+	}
+
+	text := "[lightgreen]Success[-] [dimgray](simulated)[-]"
+	if len(outputs) > 0 {
+		text += fmt.Sprintf(" returns %s", strings.Join(outputs, ", "))
+	}
+	return text
+}
+
+// StyledStateChanges describes balance changes of sender and receiver, and
+// all changed accounts if state diff is traced.
+func StyledStateChanges(sim *serv.Simulation) string {
+	lines := []string{"[::b]Balance Changes:[::-]"}
+	for _, change := range sim.BalanceChanges {
+		line := fmt.Sprintf("  %s %s → %s Ether (%s)", change.Address.Hex(),
+			format.Ether(change.Before), format.Ether(change.After), styledDelta(change.Delta()))
+		if !change.Exact {
+			line += " [dimgray](estimated by max fee)[-]"
+		}
+		lines = append(lines, line)
+	}
+
+	if sim.StateDiff == nil {
+		lines = append(lines, "", "[dimgray]State diff is not available, as provider does not support debug_traceCall with prestateTracer.[-]")
+		return strings.Join(lines, "\n")
+	}
+
+	lines = append(lines, "", "[::b]State Diff:[::-]")
+	for _, addr := range changedAccounts(sim.StateDiff) {
+		pre, post := sim.StateDiff.Pre[addr], sim.StateDiff.Post[addr]
+		if pre == nil {
+			pre = &common.AccountState{}
+		}
+		if post == nil {
+			// account is self-destructed
+			lines = append(lines, fmt.Sprintf("  %s [crimson]destroyed[-]", addr.Hex()))
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("  %s", addr.Hex()))
+		if post.Balance != nil && pre.Balance != nil {
+			lines = append(lines, fmt.Sprintf("    balance: %s → %s Ether", format.Ether(pre.Balance.ToInt()), format.Ether(post.Balance.ToInt())))
+		}
+		if post.Nonce != 0 && post.Nonce != pre.Nonce {
+			lines = append(lines, fmt.Sprintf("    nonce: %d → %d", pre.Nonce, post.Nonce))
+		}
+		if len(post.Code) > 0 && len(pre.Code) == 0 {
+			lines = append(lines, fmt.Sprintf("    code: deployed (%d bytes)", len(post.Code)))
+		}
+		for _, slot := range sortedSlots(pre.Storage, post.Storage) {
+			lines = append(lines, fmt.Sprintf("    %s: %s → %s", slot.Hex(), pre.Storage[slot].Hex(), post.Storage[slot].Hex()))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func styledDelta(delta common.BigInt) string {
+	switch delta.Sign() {
+	case 1:
+		return fmt.Sprintf("[lightgreen]+%s[-]", format.Ether(delta))
+	case -1:
+		return fmt.Sprintf("[sandybrown]-%s[-]", format.Ether(new(big.Int).Neg(delta)))
+	default:
+		return "0"
+	}
+}
+
+// changedAccounts returns addresses in state diff in ascending order.
+func changedAccounts(diff *common.StateDiff) []common.Address {
+	addrs := make([]common.Address, 0, len(diff.Pre)+len(diff.Post))
+	seen := make(map[common.Address]bool)
+	for _, states := range []map[common.Address]*common.AccountState{diff.Pre, diff.Post} {
+		for addr := range states {
+			if !seen[addr] {
+				seen[addr] = true
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i][:], addrs[j][:]) < 0
+	})
+	return addrs
+}
+
+// sortedSlots returns changed storage slots in ascending order, where slots
+// absent in post state are cleared.
+func sortedSlots(pre map[common.Hash]common.Hash, post map[common.Hash]common.Hash) []common.Hash {
+	seen := make(map[common.Hash]bool)
+	slots := make([]common.Hash, 0, len(pre)+len(post))
+	for _, storage := range []map[common.Hash]common.Hash{pre, post} {
+		for slot := range storage {
+			if !seen[slot] && pre[slot] != post[slot] {
+				seen[slot] = true
+				slots = append(slots, slot)
+			}
+		}
+	}
+	sort.Slice(slots, func(i, j int) bool {
+		return bytes.Compare(slots[i][:], slots[j][:]) < 0
+	})
+	return slots
+}
+
+// StyledGasUsage shows gas used by a block and its percentage of gas limit.
+func StyledGasUsage(block *common.Block) string {
+	if block.GasLimit() == 0 {
+		return fmt.Sprint(block.GasUsed())
+	}
+	percent := float64(block.GasUsed()) * 100 / float64(block.GasLimit())
+	return fmt.Sprintf("%d [dimgray](%.1f%%)[-]", block.GasUsed(), percent)
+}
+
+// StyledBaseFee shows base fee of a block in Gwei, n/a for pre-London blocks.
+func StyledBaseFee(block *common.Block) string {
+	if block.BaseFee() == nil {
+		return util.NAValue
+	}
+	return fmt.Sprintf("%s Gwei", format.Gwei(block.BaseFee()))
+}
+
+// StyledContractName shows name of contract in parentheses.
+func StyledContractName(name string) string {
+	return fmt.Sprintf("[dimgray](%s)[-]", tview.Escape(name))
+}
+
+// StyledProxy returns implementation address and kind of proxy, or n/a if
+// contract is not a proxy.
+func StyledProxy(contract *serv.Contract) string {
+	if contract == nil || !contract.IsProxy() {
+		return util.NAValue
+	}
+	proxy := contract.GetProxy()
+	return fmt.Sprintf("%s [dimgray](%s)[-]", proxy.Implementation.Hex(), proxy.Kind)
+}
+
+// StyledAddressWithName shows an address followed by its ENS primary name if any.
+func StyledAddressWithName(address string, name string) string {
+	if name == "" {
+		return address
+	}
+	return fmt.Sprintf("%s [dodgerblue](%s)[-]", address, tview.Escape(name))
+}
+
 func StyledTxnDirection(base *common.Address, txn common.Transaction) string {
 	if base == nil {
 		return ""
@@ -105,3 +301,58 @@ func StyledTxnDirection(base *common.Address, txn common.Transaction) string {
 
 	return ""
 }
+
+// StyledFee shows fee breakdown and maximum cost of a transaction with given gas limit.
+func StyledFee(fee *common.FeeData, gasLimit uint64) string {
+	return fmt.Sprintf("%s\n[dimgray]Max Cost: %s Ether[-]",
+		format.FeeBreakdown(fee), format.Ether(fee.MaxCost(gasLimit)))
+}
+
+// StyledTxnRequest describes a transaction request for review before signing.
+// Calldata is decoded if ABI of receiver is given.
+func StyledTxnRequest(from common.Address, req *common.TxnRequest, contractABI *abi.ABI) string {
+	lines := []string{
+		fmt.Sprintf("[::b]From:[::-] %s", from.Hex()),
+		fmt.Sprintf("[::b]To:[::-] %s", format.NormalizeReceiverAddress(req.To)),
+		fmt.Sprintf("[::b]Value:[::-] %s Ether", format.Ether(req.Value)),
+	}
+
+	if len(req.Data) > 0 {
+		lines = append(lines, fmt.Sprintf("[::b]Call:[::-] %s", styledCalldata(req.Data, contractABI)))
+	}
+
+	lines = append(lines,
+		fmt.Sprintf("[::b]GasLimit:[::-] %d", req.GasLimit),
+		fmt.Sprintf("[::b]GasPrice:[::-] %s", styledRequestFee(req)),
+		fmt.Sprintf("[::b]Max Fee:[::-] %s Ether", format.Ether(req.MaxFee())),
+	)
+	return strings.Join(lines, "\n")
+}
+
+// styledCalldata shows calldata as a method call if it can be decoded by ABI,
+// otherwise as hex string.
+func styledCalldata(data []byte, contractABI *abi.ABI) string {
+	raw := format.BytesToString(data, 64)
+	if contractABI == nil || len(data) < 4 {
+		return raw
+	}
+
+	method, err := contractABI.MethodById(data[:4])
+	if err != nil {
+		return raw
+	}
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return raw
+	}
+
+	argStrs := make([]string, len(args))
+	for i, input := range method.Inputs {
+		valStr, err := conv.PackArgument(input.Type, args[i])
+		if err != nil {
+			valStr = fmt.Sprint(args[i])
+		}
+		argStrs[i] = fmt.Sprintf("%s: %s", input.Name, tview.Escape(valStr))
+	}
+	return fmt.Sprintf("[dodgerblue]%s[-](%s)", method.RawName, strings.Join(argStrs, ", "))
+}
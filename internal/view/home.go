@@ -56,6 +56,10 @@ func (h *Home) onNewBlock(block *common.Block) {
 		return
 	}
 
+	// fetch receipts to show status of transactions
+	h.transactionList.PrefetchReceipts(txns)
+	h.transactionList.PrefetchNames(txns)
+
 	h.app.QueueUpdateDraw(func() {
 		h.transactionList.PrependTransactions(txns)
 	})
@@ -4,6 +4,7 @@ import (
 	"github.com/dyng/ramen/internal/view/format"
 	"github.com/dyng/ramen/internal/view/style"
 	"github.com/dyng/ramen/internal/view/util"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
@@ -48,7 +49,7 @@ func (d *ImportABIDialog) initLayout() {
 	desc.SetWrap(true)
 	desc.SetTextAlign(tview.AlignCenter)
 	desc.SetBorderPadding(0, 0, 1, 1)
-	desc.SetText("Cannot find ABI for this contract. But you can upload an ABI json instead.\nGenerate ABI json by solc command: `solc filename.sol --abi`.")
+	desc.SetText("Upload an ABI json, or a solc output containing `abi` and `storageLayout`.\nGenerate them by solc command: `solc filename.sol --abi --storage-layout`.")
 
 	// textarea
 	input := tview.NewTextArea()
@@ -103,19 +104,32 @@ func (d *ImportABIDialog) focusNext() {
 func (d *ImportABIDialog) doImport() {
 	account := d.app.root.account
 
-	// read and parse abi json
-	err := account.contract.ImportABI(d.input.GetText())
+	// read and parse abi json, or an object containing abi and storage layout
+	text := d.input.GetText()
+	err := account.contract.ImportJSON(text)
 	if err != nil {
 		d.app.root.NotifyError(format.FineErrorMessage("Cannot import ABI json", err))
 		return
 	}
 
+	// save to registry, so that it is still there after restart
+	err = d.app.service.RegisterABI(account.contract.GetAddress(), text)
+	if err != nil {
+		log.Error("Cannot save ABI to registry", "address", account.contract.GetAddress(), "error", err)
+		d.app.root.NotifyError(format.FineErrorMessage("Cannot save ABI to registry", err))
+	}
+
 	// hide dialog if importation complete
 	d.Hide()
 
+	account.contractViewer.SetContract(account.contract)
+	account.storageViewer.SetContract(account.contract)
+
 	// show callMethod dialog
-	account.methodCall.refresh()
-	account.ShowMethodCallDialog()
+	if account.contract.HasABI() {
+		account.methodCall.refresh()
+		account.ShowMethodCallDialog()
+	}
 }
 
 func (d *ImportABIDialog) Show() {
@@ -3,7 +3,10 @@ package view
 import (
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/dyng/ramen/internal/common"
 	"github.com/dyng/ramen/internal/common/conv"
 	"github.com/dyng/ramen/internal/service"
 	"github.com/dyng/ramen/internal/view/format"
@@ -12,6 +15,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/gdamore/tcell/v2"
+	"github.com/pkg/errors"
 	"github.com/rivo/tview"
 )
 
@@ -20,6 +24,12 @@ const (
 	methodCallDialogMinHeight = 16
 	// methodCallDialogMinWidth is the minimum width of the method call dialog.
 	methodCallDialogMinWidth  = 80
+
+	// labels of option fields for non-constant method
+	valueLabel       = "Value (Ether)"
+	gasLimitLabel    = "Gas Limit"
+	maxFeeLabel      = "Max Fee (Gwei)"
+	priorityFeeLabel = "Priority Fee (Gwei)"
 )
 
 type MethodCallDialog struct {
@@ -228,7 +238,93 @@ func (d *MethodCallDialog) showArguments() {
 			argName = "<unknown>"
 		}
 		d.args.AddInputField(argName, "", 999, nil, nil)
+		d.args.GetFormItem(d.args.GetFormItemCount() - 1).(*tview.InputField).SetPlaceholder(arg.Type.String())
+	}
+
+	// show options and fee breakdown for non-constant method
+	d.result.Clear()
+	if !method.IsConstant() {
+		d.showOptions(method)
+		d.showFee()
+	}
+}
+
+// showOptions adds fields of value and gas overrides, empty fields keep the
+// estimated values.
+func (d *MethodCallDialog) showOptions(method abi.Method) {
+	if method.IsPayable() {
+		d.args.AddInputField(valueLabel, "", 999, nil, nil)
+	}
+	d.args.AddInputField(gasLimitLabel, "", 999, nil, nil)
+	d.args.AddInputField(maxFeeLabel, "", 999, nil, nil)
+	d.args.AddInputField(priorityFeeLabel, "", 999, nil, nil)
+
+	for _, label := range []string{gasLimitLabel, maxFeeLabel, priorityFeeLabel} {
+		d.args.GetFormItemByLabel(label).(*tview.InputField).SetPlaceholder("estimated")
+	}
+}
+
+// parseOptions reads value and gas overrides from option fields.
+func (d *MethodCallDialog) parseOptions() (*common.CallOpts, error) {
+	opts := &common.CallOpts{}
+
+	if text, ok := d.optionText(valueLabel); ok {
+		value, err := conv.ParseUnits(text, 18)
+		if err != nil {
+			return nil, err
+		}
+		opts.Value = value
+	}
+	if text, ok := d.optionText(gasLimitLabel); ok {
+		gasLimit, err := strconv.ParseUint(text, 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("Invalid gas limit %s", text)
+		}
+		opts.GasLimit = gasLimit
+	}
+	if text, ok := d.optionText(maxFeeLabel); ok {
+		fee, err := conv.ParseUnits(text, 9)
+		if err != nil {
+			return nil, err
+		}
+		opts.GasFeeCap = fee
+	}
+	if text, ok := d.optionText(priorityFeeLabel); ok {
+		fee, err := conv.ParseUnits(text, 9)
+		if err != nil {
+			return nil, err
+		}
+		opts.GasTipCap = fee
+	}
+
+	return opts, nil
+}
+
+// optionText returns text of option field, or false if it is absent or empty.
+func (d *MethodCallDialog) optionText(label string) (string, bool) {
+	item := d.args.GetFormItemByLabel(label)
+	if item == nil {
+		return "", false
 	}
+	text := strings.TrimSpace(item.(*tview.InputField).GetText())
+	return text, text != ""
+}
+
+func (d *MethodCallDialog) showFee() {
+	methodName := d.methodSelected()
+	go func() {
+		fee, err := d.app.service.GetFeeData()
+		if err != nil {
+			log.Error("Failed to fetch fee data", "error", err)
+			return
+		}
+		d.app.QueueUpdateDraw(func() {
+			// selection may have changed during loading
+			if d.methodSelected() == methodName {
+				d.result.SetText(fmt.Sprintf("Fee: %s", format.FeeBreakdown(fee)))
+			}
+		})
+	}()
 }
 
 func (d *MethodCallDialog) focusNext() {
@@ -257,9 +353,7 @@ func (d *MethodCallDialog) methodSelected() string {
 }
 
 func (d *MethodCallDialog) methodHasNoArg() bool {
-	methodName := d.methodSelected()
-	method := d.contract.GetABI().Methods[methodName]
-	return len(method.Inputs) == 0
+	return d.args.GetFormItemCount() == 0
 }
 
 func (d *MethodCallDialog) callMethod() {
@@ -268,9 +362,8 @@ func (d *MethodCallDialog) callMethod() {
 
 	// unpack arguments
 	args := make([]any, 0)
-	for i := 0; i < d.args.GetFormItemCount(); i++ {
+	for i, arg := range method.Inputs {
 		item := d.args.GetFormItem(i).(*tview.InputField)
-		arg := method.Inputs[i]
 		val, err := conv.UnpackArgument(arg.Type, item.GetText())
 		if err == nil {
 			args = append(args, val)
@@ -282,40 +375,126 @@ func (d *MethodCallDialog) callMethod() {
 		}
 	}
 
-	// ensure signer has signed in
-	var signer *service.Signer
-	if !method.IsConstant() {
-		signer = d.app.root.signer.GetSigner()
-		if signer == nil {
-			d.app.root.NotifyError(format.FineErrorMessage("Cannot call a non-constant method without a signer. Please signin first."))
-			return
-		}
+	if method.IsConstant() {
+		d.callConstant(method, args)
+	} else {
+		d.reviewCall(method, args)
 	}
+}
 
+// callConstant calls a constant method and shows its result.
+func (d *MethodCallDialog) callConstant(method abi.Method, args []any) {
+	methodName := method.Name
 	log.Info("Invoke contract method", "contract", d.contract.GetAddress(), "method", methodName, "args", args)
 
 	// start spinner
 	d.spinner.StartAndShow()
 
 	go func() {
-		var res []any
-		var err error
+		res, err := d.contract.Call(methodName, args...)
 
-		if method.IsConstant() {
-			res, err = d.contract.Call(methodName, args...)
-		} else {
-			hash, e := d.contract.Send(signer, methodName, args...)
-			res = []any{fmt.Sprintf("Transaction has been submitted to network.\n\nTxnHash: %s", hash)}
-			err = e
+		d.app.QueueUpdateDraw(func() {
+			if err != nil {
+				d.spinner.StopAndHide() // must stop spinner before show error message
+				log.Error("Method call is failed", "name", methodName, "args", args, "error", err)
+				d.app.root.NotifyError(format.FineErrorMessage("Cannot call contract method '%s'.", methodName, err))
+			} else {
+				d.result.SetText(formatOutputs(method, res))
+				d.spinner.StopAndHide()
+			}
+		})
+	}()
+}
+
+// formatOutputs pretty-prints return values of method, one per line.
+func formatOutputs(method abi.Method, vals []any) string {
+	lines := make([]string, len(vals))
+	for i, val := range vals {
+		output := method.Outputs[i]
+		valStr, err := conv.PackArgumentPretty(output.Type, val)
+		if err != nil {
+			log.Error("Failed to pack return value", "value", val, "type", output.Type, "error", err)
+			valStr = fmt.Sprint(val)
+		}
+		if output.Name != "" {
+			valStr = fmt.Sprintf("%s: %s", output.Name, valStr)
+		}
+		lines[i] = valStr
+	}
+	return strings.Join(lines, "\n")
+}
+
+// reviewCall prepares a transaction calling a non-constant method, simulates
+// it, then shows it for review before signing.
+func (d *MethodCallDialog) reviewCall(method abi.Method, args []any) {
+	methodName := method.Name
+
+	// ensure signer has signed in
+	signer := d.app.root.signer.GetSigner()
+	if signer == nil {
+		d.app.root.NotifyError(format.FineErrorMessage("Cannot call a non-constant method without a signer. Please signin first."))
+		return
+	}
+
+	opts, err := d.parseOptions()
+	if err != nil {
+		d.app.root.NotifyError(format.FineErrorMessage("Invalid transaction options", err))
+		return
+	}
+
+	log.Info("Prepare contract method call", "contract", d.contract.GetAddress(), "method", methodName, "args", args)
+
+	// start spinner
+	d.spinner.StartAndShow()
+
+	go func() {
+		txnReq, err := d.contract.Prepare(signer, opts, methodName, args...)
+		if err != nil {
+			d.app.QueueUpdateDraw(func() {
+				d.spinner.StopAndHide() // must stop spinner before show error message
+				log.Error("Failed to prepare method call", "name", methodName, "args", args, "error", err)
+				d.app.root.NotifyError(format.FineErrorMessage("Cannot call contract method '%s'.", methodName, err))
+			})
+			return
+		}
+
+		var sim *service.Simulation
+		if !d.app.config.SkipSimulation {
+			sim, err = d.contract.Simulate(txnReq)
+			if err != nil {
+				log.Warn("Cannot simulate method call", "name", methodName, "args", args, "error", err)
+			} else if !sim.Succeeded() {
+				log.Warn("Simulation of method call is failed", "name", methodName, "args", args, "error", sim.Err)
+			}
 		}
 
+		d.app.QueueUpdateDraw(func() {
+			d.spinner.StopAndHide()
+			summary := fmt.Sprintf("Call %s", methodName)
+			d.app.root.ShowTxnReview(summary, txnReq, sim, func() {
+				d.sendCall(signer, methodName, txnReq)
+			})
+		})
+	}()
+}
+
+// sendCall signs and sends a reviewed transaction.
+func (d *MethodCallDialog) sendCall(signer service.Signer, methodName string, txnReq *common.TxnRequest) {
+	log.Info("Invoke contract method", "contract", d.contract.GetAddress(), "method", methodName)
+
+	// start spinner
+	d.spinner.StartAndShow()
+
+	go func() {
+		hash, err := signer.Send(txnReq)
+
 		d.app.QueueUpdateDraw(func() {
 			if err != nil {
 				d.spinner.StopAndHide() // must stop spinner before show error message
-				log.Error("Method call is failed", "name", methodName, "args", args, "error", err)
+				log.Error("Method call is failed", "name", methodName, "error", err)
 				d.app.root.NotifyError(format.FineErrorMessage("Cannot call contract method '%s'.", methodName, err))
 			} else {
-				d.result.SetText(fmt.Sprint(res...))
+				d.result.SetText(fmt.Sprintf("Transaction has been submitted to network.\n\nTxnHash: %s", hash))
 				d.spinner.StopAndHide()
 			}
 		})
@@ -0,0 +1,182 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dyng/ramen/internal/service"
+	"github.com/dyng/ramen/internal/view/format"
+	"github.com/dyng/ramen/internal/view/style"
+	"github.com/dyng/ramen/internal/view/util"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gdamore/tcell/v2"
+	"github.com/pkg/errors"
+	"github.com/rivo/tview"
+)
+
+type NFTList struct {
+	*tview.Table
+	app    *App
+	loader *util.Loader
+
+	nfts []*service.NFT
+}
+
+func NewNFTList(app *App) *NFTList {
+	n := &NFTList{
+		Table:  tview.NewTable(),
+		app:    app,
+		loader: util.NewLoader(app.Application),
+		nfts:   []*service.NFT{},
+	}
+
+	// setup layout
+	n.initLayout()
+
+	return n
+}
+
+func (n *NFTList) initLayout() {
+	s := n.app.config.Style()
+
+	n.SetBorder(true)
+	n.SetTitle(style.BoldPadding("NFTs"))
+
+	headers := []string{"collection", "token id", "standard", "amount", "name", "attributes"}
+	for i, header := range headers {
+		n.SetCell(0, i,
+			tview.NewTableCell(strings.ToUpper(header)).
+				SetExpansion(1).
+				SetAlign(tview.AlignLeft).
+				SetStyle(s.TableHeaderStyle).
+				SetSelectable(false))
+	}
+	n.SetSelectable(true, false)
+	n.SetFixed(1, 1)
+	n.SetSelectedFunc(n.handleSelected)
+
+	// loader
+	n.loader.SetTitleColor(s.PrgBarTitleColor)
+	n.loader.SetBorderColor(s.PrgBarBorderColor)
+	n.loader.SetCellColor(s.PrgBarCellColor)
+}
+
+// SetNFTs sets NFTs to display
+func (n *NFTList) SetNFTs(nfts []*service.NFT) {
+	n.nfts = nfts
+	n.refresh()
+}
+
+// LoadAsync loads NFTs asynchronously
+func (n *NFTList) LoadAsync(loader func() ([]*service.NFT, error)) {
+	// clear current content
+	n.Clear()
+
+	// start loading animation
+	n.loader.Start()
+	n.loader.Display(true)
+
+	go func() {
+		nfts, err := loader()
+		n.app.QueueUpdateDraw(func() {
+			// stop loading animation
+			n.loader.Stop()
+			n.loader.Display(false)
+
+			switch {
+			case err == nil:
+				n.SetNFTs(nfts)
+			case errors.Is(err, service.ErrNFTsNotSupported):
+				n.SetTitle(style.BoldPadding("NFTs [dimgray](need Alchemy provider)[-]"))
+			default:
+				log.Error("Failed to load NFTs", "error", err)
+				n.app.root.NotifyError(format.FineErrorMessage("Error occurs when loading NFTs.", err))
+			}
+		})
+	}()
+}
+
+func (n *NFTList) Clear() {
+	for i := n.GetRowCount() - 1; i > 0; i-- {
+		n.RemoveRow(i)
+	}
+}
+
+func (n *NFTList) refresh() {
+	// clear previous content at first
+	n.Clear()
+
+	// show NFT count
+	n.SetTitle(style.BoldPadding(fmt.Sprintf("NFTs[[coral]%d[-]]", len(n.nfts))))
+
+	for i, nft := range n.nfts {
+		row := i + 1
+
+		name := util.NAValue
+		attrs := util.NAValue
+		if nft.Metadata != nil {
+			name = tview.Escape(format.TruncateText(nft.Metadata.Name, 30))
+			attrs = tview.Escape(format.TruncateText(formatNFTAttributes(nft.Metadata), 60))
+		}
+
+		j := 0
+		n.SetCell(row, Inc(&j), tview.NewTableCell(styledCollection(nft)))
+		n.SetCell(row, Inc(&j), tview.NewTableCell(format.TruncateText(nft.TokenId.String(), 20)))
+		n.SetCell(row, Inc(&j), tview.NewTableCell(nft.Standard))
+		n.SetCell(row, Inc(&j), tview.NewTableCell(nft.Balance.String()))
+		n.SetCell(row, Inc(&j), tview.NewTableCell(name))
+		n.SetCell(row, Inc(&j), tview.NewTableCell(attrs))
+	}
+}
+
+// handleSelected shows metadata of selected NFT
+func (n *NFTList) handleSelected(row int, column int) {
+	if row <= 0 || row > len(n.nfts) {
+		return
+	}
+
+	nft := n.nfts[row-1]
+	lines := []string{
+		fmt.Sprintf("Contract: %s", nft.Contract.Hex()),
+		fmt.Sprintf("TokenId: %s", nft.TokenId),
+		fmt.Sprintf("URI: %s", format.TruncateText(nft.URI, 80)),
+	}
+	if nft.Metadata != nil {
+		lines = append(lines, "", fmt.Sprintf("Name: %s", nft.Metadata.Name))
+		if nft.Metadata.Description != "" {
+			lines = append(lines, fmt.Sprintf("Description: %s", format.TruncateText(nft.Metadata.Description, 200)))
+		}
+		for _, attr := range nft.Metadata.Attributes {
+			lines = append(lines, fmt.Sprintf("%s: %v", attr.TraitType, attr.Value))
+		}
+	}
+
+	n.app.root.ShowNotification(styledCollection(nft), strings.Join(lines, "\n"))
+}
+
+// SetRect implements tview.SetRect
+func (n *NFTList) SetRect(x, y, width, height int) {
+	n.Table.SetRect(x, y, width, height)
+	n.loader.SetCentral(x, y, width, height)
+}
+
+// Draw implements tview.Draw
+func (n *NFTList) Draw(screen tcell.Screen) {
+	n.Table.Draw(screen)
+	n.loader.Draw(screen)
+}
+
+func styledCollection(nft *service.NFT) string {
+	if nft.Collection == "" {
+		return format.TruncateText(nft.Contract.Hex(), 20)
+	}
+	return tview.Escape(format.TruncateText(nft.Collection, 20))
+}
+
+func formatNFTAttributes(metadata *service.NFTMetadata) string {
+	attrs := make([]string, len(metadata.Attributes))
+	for i, attr := range metadata.Attributes {
+		attrs[i] = fmt.Sprintf("%s=%v", attr.TraitType, attr.Value)
+	}
+	return strings.Join(attrs, ", ")
+}
@@ -0,0 +1,195 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/dyng/ramen/internal/service"
+	"github.com/dyng/ramen/internal/view/format"
+	"github.com/dyng/ramen/internal/view/style"
+	"github.com/dyng/ramen/internal/view/util"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+type PendingTxnList struct {
+	*tview.Table
+	app *App
+
+	txns []*service.PendingTxn
+}
+
+func NewPendingTxnList(app *App) *PendingTxnList {
+	p := &PendingTxnList{
+		Table: tview.NewTable(),
+		app:   app,
+		txns:  []*service.PendingTxn{},
+	}
+
+	// setup layout
+	p.initLayout()
+
+	// setup keymap
+	p.initKeymap()
+
+	// subscribe to new blocks
+	app.eventBus.Subscribe(service.TopicNewBlock, p.onNewBlock)
+
+	return p
+}
+
+func (p *PendingTxnList) initLayout() {
+	s := p.app.config.Style()
+
+	p.SetBorder(true)
+	p.SetTitle(style.BoldPadding("Pending Transactions"))
+	p.SetTitleColor(s.TitleColor)
+	p.SetBorderColor(s.BorderColor)
+
+	headers := []string{"hash", "nonce", "to", "value", "fee", "status", "submitted"}
+	for i, header := range headers {
+		p.SetCell(0, i,
+			tview.NewTableCell(strings.ToUpper(header)).
+				SetExpansion(1).
+				SetAlign(tview.AlignLeft).
+				SetStyle(s.TableHeaderStyle).
+				SetSelectable(false))
+	}
+	p.SetSelectable(true, false)
+	p.SetFixed(1, 1)
+}
+
+func (p *PendingTxnList) initKeymap() {
+	InitKeymap(p, p.app)
+}
+
+// KeyMaps implements bodyPage
+func (p *PendingTxnList) KeyMaps() util.KeyMaps {
+	keymaps := make(util.KeyMaps, 0)
+
+	// KeyU: speed up selected transaction
+	keymaps = append(keymaps, util.KeyMap{
+		Key:         util.KeyU,
+		Shortcut:    "u",
+		Description: "Speed Up",
+		Handler: func(*tcell.EventKey) {
+			p.SpeedUp()
+		},
+	})
+
+	// KeyX: cancel selected transaction
+	keymaps = append(keymaps, util.KeyMap{
+		Key:         util.KeyX,
+		Shortcut:    "x",
+		Description: "Cancel",
+		Handler: func(*tcell.EventKey) {
+			p.Cancel()
+		},
+	})
+
+	return keymaps
+}
+
+// SpeedUp resends selected transaction with a higher fee
+func (p *PendingTxnList) SpeedUp() {
+	p.replace("speed up", func(signer service.Signer, hash common.Hash) (common.Hash, error) {
+		return signer.SpeedUp(hash)
+	})
+}
+
+// Cancel replaces selected transaction with a zero-value self-transfer
+func (p *PendingTxnList) Cancel() {
+	p.replace("cancel", func(signer service.Signer, hash common.Hash) (common.Hash, error) {
+		return signer.Cancel(hash)
+	})
+}
+
+func (p *PendingTxnList) replace(action string, replacer func(service.Signer, common.Hash) (common.Hash, error)) {
+	signer := p.app.root.signer.GetSigner()
+	current := p.selection()
+	if signer == nil || current == nil {
+		return
+	}
+
+	log.Info("Replace pending transaction", "action", action, "hash", current.Hash)
+	go func() {
+		hash, err := replacer(signer, current.Hash)
+		p.app.QueueUpdateDraw(func() {
+			if err != nil {
+				log.Error("Failed to replace transaction", "action", action, "hash", current.Hash, "error", err)
+				p.app.root.NotifyError(format.FineErrorMessage("Failed to %s transaction %s", action, current.Hash.Hex(), err))
+			} else {
+				p.refresh()
+				p.app.root.NotifyInfo(fmt.Sprintf("Replacement transaction has been submitted.\n\nTxnHash: %s", hash))
+			}
+		})
+	}()
+}
+
+func (p *PendingTxnList) Clear() {
+	for i := p.GetRowCount() - 1; i > 0; i-- {
+		p.RemoveRow(i)
+	}
+}
+
+func (p *PendingTxnList) refresh() {
+	signer := p.app.root.signer.GetSigner()
+	if signer == nil {
+		p.txns = []*service.PendingTxn{}
+	} else {
+		p.txns = signer.GetPendingTxns()
+	}
+
+	// clear previous content at first
+	p.Clear()
+
+	for i, txn := range p.txns {
+		row := i + 1
+		req := txn.Request
+
+		j := 0
+		p.SetCell(row, Inc(&j), tview.NewTableCell(format.TruncateText(txn.Hash.Hex(), 8)))
+		p.SetCell(row, Inc(&j), tview.NewTableCell(fmt.Sprint(txn.Nonce)))
+		p.SetCell(row, Inc(&j), tview.NewTableCell(format.TruncateText(
+			format.NormalizeReceiverAddress(req.To), 20)))
+		p.SetCell(row, Inc(&j), tview.NewTableCell(format.Ether(req.Value)))
+		p.SetCell(row, Inc(&j), tview.NewTableCell(styledRequestFee(req)))
+		p.SetCell(row, Inc(&j), tview.NewTableCell(StyledTxnStatus(txn.Status)))
+		p.SetCell(row, Inc(&j), tview.NewTableCell(format.ToDatetime(uint64(txn.SubmittedAt.Unix()))))
+	}
+}
+
+func (p *PendingTxnList) selection() *service.PendingTxn {
+	row, _ := p.GetSelection()
+	if row > 0 && row <= len(p.txns) {
+		return p.txns[row-1]
+	} else {
+		return nil
+	}
+}
+
+func (p *PendingTxnList) onNewBlock(block *common.Block) {
+	accounts := p.app.root.signer.GetWallet().Accounts()
+	if len(accounts) == 0 {
+		return
+	}
+
+	// transactions of inactive accounts may also be mined
+	for _, account := range accounts {
+		account.Signer.SyncPendingTxns(block)
+	}
+
+	p.app.QueueUpdateDraw(func() {
+		p.refresh()
+	})
+}
+
+func styledRequestFee(req *common.TxnRequest) string {
+	if req.IsDynamicFee() {
+		return fmt.Sprintf("%s/%s Gwei", format.Gwei(req.GasTipCap), format.Gwei(req.GasFeeCap))
+	} else {
+		return fmt.Sprintf("%s Gwei", format.Gwei(req.GasPrice))
+	}
+}
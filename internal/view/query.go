@@ -1,6 +1,8 @@
 package view
 
 import (
+	"strings"
+
 	"github.com/dyng/ramen/internal/view/format"
 	"github.com/dyng/ramen/internal/view/style"
 	"github.com/dyng/ramen/internal/view/util"
@@ -36,7 +38,7 @@ func (d *QueryDialog) initLayout() {
 	input.SetFieldWidth(80)
 	input.SetBorder(true)
 	input.SetBorderColor(s.DialogBorderColor)
-	input.SetTitle(style.Padding("Address"))
+	input.SetTitle(style.Padding("Address / Txn / Block / ENS"))
 	input.SetTitleColor(s.FgColor)
 	input.SetLabel("> ")
 	input.SetLabelColor(s.InputFieldLableColor)
@@ -48,29 +50,26 @@ func (d *QueryDialog) initLayout() {
 func (d *QueryDialog) handleKey(key tcell.Key) {
 	switch key {
 	case tcell.KeyEnter:
-		// start spinner
-		d.Loading()
-
-		address := d.GetText()
-		if address == "" {
+		query := strings.TrimSpace(d.GetText())
+		if query == "" {
 			return
 		}
 
+		// start spinner
+		d.Loading()
+
 		go func() {
-			account, err := d.app.service.GetAccount(address)
-			account.UpdateBalance() // populate balance cache
+			show, err := d.search(query)
 			d.app.QueueUpdateDraw(func() {
 				if err != nil {
 					d.Finished() // must stop loading animation before show error message
-					log.Error("Failed to fetch account of given address",
-						"address", address, "error", err)
+					log.Error("Failed to search", "query", query, "error", err)
 					d.app.root.NotifyError(format.FineErrorMessage(
-						"Failed to fetch account of address %s", address, err))
+						"Failed to search for %s", query, err))
 				} else {
-					d.app.root.ShowAccountPage(account)
+					show()
 					d.Finished()
 				}
-
 			})
 		}()
 	case tcell.KeyEsc:
@@ -78,6 +77,24 @@ func (d *QueryDialog) handleKey(key tcell.Key) {
 	}
 }
 
+// search looks up the query as address, transaction, block or ENS name, and
+// returns a function which shows the result page.
+func (d *QueryDialog) search(query string) (func(), error) {
+	result, err := d.app.service.Search(query)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case result.Transaction != nil:
+		return func() { d.app.root.ShowTransactionPage(result.Transaction) }, nil
+	case result.Block != nil:
+		return func() { d.app.root.ShowBlockPage(result.Block) }, nil
+	default:
+		return func() { d.app.root.ShowAccountPage(result.Account) }, nil
+	}
+}
+
 func (d *QueryDialog) Show() {
 	if !d.display {
 		d.Display(true)
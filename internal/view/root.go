@@ -28,12 +28,20 @@ type Root struct {
 	home        *Home
 	account     *Account
 	transaction *TransactionDetail
+	trace       *TraceViewer
+	pending     *PendingTxnList
+	blocks      *BlockList
+	block       *BlockDetail
 
 	// dialogs
 	query        *QueryDialog
 	notification *Notification
 	signin       *SignInDialog
+	hdAccounts   *HDAccountPicker
+	wallet       *WalletDialog
 	transfer     *TransferDialog
+	confirm      *ConfirmDialog
+	review       *TxnPreviewDialog
 }
 
 func NewRoot(app *App) *Root {
@@ -88,6 +96,26 @@ func (r *Root) initLayout() {
 	body.AddPage("transaction", transaction, true, false)
 	r.transaction = transaction
 
+	// call trace page
+	trace := NewTraceViewer(r.app)
+	body.AddPage("trace", trace, true, false)
+	r.trace = trace
+
+	// pending transactions page
+	pending := NewPendingTxnList(r.app)
+	body.AddPage("pending", pending, true, false)
+	r.pending = pending
+
+	// block list page
+	blocks := NewBlockList(r.app)
+	body.AddPage("blocks", blocks, true, false)
+	r.blocks = blocks
+
+	// block detail page
+	block := NewBlockDetail(r.app)
+	body.AddPage("block", block, true, false)
+	r.block = block
+
 	// query dialog
 	query := NewQueryDialog(r.app)
 	r.query = query
@@ -100,10 +128,26 @@ func (r *Root) initLayout() {
 	signin := NewSignInDialog(r.app)
 	r.signin = signin
 
+	// account picker of mnemonic
+	hdAccounts := NewHDAccountPicker(r.app)
+	r.hdAccounts = hdAccounts
+
+	// wallet dialog
+	wallet := NewWalletDialog(r.app)
+	r.wallet = wallet
+
 	// transfer dialog
 	transfer := NewTransferDialog(r.app)
 	r.transfer = transfer
 
+	// confirm dialog
+	confirm := NewConfirmDialog(r.app)
+	r.confirm = confirm
+
+	// review dialog of transaction request
+	review := NewTxnPreviewDialog(r.app)
+	r.review = review
+
 	// root
 	flex := tview.NewFlex().
 		SetDirection(tview.FlexRow).
@@ -139,6 +183,16 @@ func (r *Root) KeyMaps() util.KeyMaps {
 		},
 	})
 
+	// KeyB: block list
+	keymaps = append(keymaps, util.KeyMap{
+		Key:         util.KeyB,
+		Shortcut:    "b",
+		Description: "Blocks",
+		Handler: func(*tcell.EventKey) {
+			r.ShowBlockListPage()
+		},
+	})
+
 	// KeyS: signin
 	keymaps = append(keymaps, util.KeyMap{
 		Key:         util.KeyS,
@@ -149,6 +203,16 @@ func (r *Root) KeyMaps() util.KeyMaps {
 		},
 	})
 
+	// KeyW: wallet
+	keymaps = append(keymaps, util.KeyMap{
+		Key:         util.KeyW,
+		Shortcut:    "w",
+		Description: "Wallet",
+		Handler: func(*tcell.EventKey) {
+			r.ShowWalletDialog()
+		},
+	})
+
 	// KeyM: transfer
 	keymaps = append(keymaps, util.KeyMap{
 		Key:         util.KeyM,
@@ -159,6 +223,16 @@ func (r *Root) KeyMaps() util.KeyMaps {
 		},
 	})
 
+	// KeyP: pending transactions
+	keymaps = append(keymaps, util.KeyMap{
+		Key:         util.KeyP,
+		Shortcut:    "p",
+		Description: "Pending",
+		Handler: func(*tcell.EventKey) {
+			r.ShowPendingPage()
+		},
+	})
+
 	// KeyCtrlC: quit
 	keymaps = append(keymaps, util.KeyMap{
 		Key:         tcell.KeyCtrlC,
@@ -190,11 +264,28 @@ func (r *Root) ShowNotification(title string, text string) {
 	r.notification.Show()
 }
 
+func (r *Root) ShowConfirmDialog(title string, text string, onConfirm func()) {
+	r.confirm.SetContent(title, text, onConfirm)
+	r.confirm.Show()
+}
+
+// ShowTxnReview shows a transaction request and result of its simulation for
+// review before it is signed, summary is shown as title
+func (r *Root) ShowTxnReview(summary string, txnReq *common.TxnRequest, sim *service.Simulation, onConfirm func()) {
+	r.review.SetRequest(summary, txnReq, sim, onConfirm)
+	r.review.Show()
+}
+
 func (r *Root) ShowSignInDialog() {
-	r.signin.Clear()
+	r.signin.ClearAndRefresh()
 	r.signin.Show()
 }
 
+func (r *Root) ShowHDAccountPicker(label string, mnemonic string, passphrase string) {
+	r.hdAccounts.SetMnemonic(label, mnemonic, passphrase)
+	r.hdAccounts.Show()
+}
+
 func (r *Root) ShowTransferDialog() {
 	if r.signer.HasSignedIn() {
 		r.transfer.ClearAndRefresh()
@@ -202,10 +293,30 @@ func (r *Root) ShowTransferDialog() {
 	}
 }
 
-func (r *Root) SignIn(signer *service.Signer) {
-	log.Debug("Account signed in", "account", signer.GetAddress())
-	r.signer.SetSigner(signer)
-	r.transfer.SetSender(signer)
+func (r *Root) ShowWalletDialog() {
+	if r.signer.HasSignedIn() {
+		r.wallet.SetWallet(r.signer.GetWallet())
+		r.wallet.Show()
+	}
+}
+
+// SignIn adds signer to wallet with given label and makes it active
+func (r *Root) SignIn(label string, signer service.Signer) {
+	log.Debug("Account signed in", "account", signer.GetAddress(), "backend", signer.Backend())
+	r.signer.AddSigner(label, signer)
+	r.onSignerChanged()
+}
+
+// SwitchSigner makes the account at given index of wallet active
+func (r *Root) SwitchSigner(index int) {
+	r.signer.SwitchTo(index)
+	log.Debug("Switch active account", "account", r.signer.GetSigner().GetAddress())
+	r.onSignerChanged()
+}
+
+func (r *Root) onSignerChanged() {
+	r.transfer.SetSender(r.signer.GetSigner())
+	r.pending.refresh()
 }
 
 func (r *Root) ShowHomePage() {
@@ -228,6 +339,33 @@ func (r *Root) ShowTransactionPage(transaction common.Transaction) {
 	r.updateHelp(r.transaction)
 }
 
+func (r *Root) ShowTracePage(transaction common.Transaction) {
+	log.Debug("Switch to call trace page", "transaction", transaction.Hash())
+	r.trace.SetTransaction(transaction)
+	r.body.SwitchToPage("trace")
+	r.updateHelp(r.trace)
+}
+
+func (r *Root) ShowBlockListPage() {
+	log.Debug("Switch to block list page")
+	r.body.SwitchToPage("blocks")
+	r.updateHelp(r.blocks)
+}
+
+func (r *Root) ShowBlockPage(block *common.Block) {
+	log.Debug("Switch to block page", "block", block.Number())
+	r.block.SetBlock(block)
+	r.body.SwitchToPage("block")
+	r.updateHelp(r.block)
+}
+
+func (r *Root) ShowPendingPage() {
+	log.Debug("Switch to pending transactions page")
+	r.pending.refresh()
+	r.body.SwitchToPage("pending")
+	r.updateHelp(r.pending)
+}
+
 func (r *Root) updateHelp(page bodyPage) {
 	keymaps := r.KeyMaps().
 		Add(page.KeyMaps())
@@ -246,9 +384,21 @@ func (r *Root) HasFocus() bool {
 	if r.signin.HasFocus() {
 		return true
 	}
+	if r.hdAccounts.HasFocus() {
+		return true
+	}
+	if r.wallet.HasFocus() {
+		return true
+	}
 	if r.transfer.HasFocus() {
 		return true
 	}
+	if r.confirm.HasFocus() {
+		return true
+	}
+	if r.review.HasFocus() {
+		return true
+	}
 	if r.notification.HasFocus() {
 		return true
 	}
@@ -276,12 +426,36 @@ func (r *Root) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.
 				return
 			}
 		}
+		if r.hdAccounts.HasFocus() {
+			if handler := r.hdAccounts.InputHandler(); handler != nil {
+				handler(event, setFocus)
+				return
+			}
+		}
+		if r.wallet.HasFocus() {
+			if handler := r.wallet.InputHandler(); handler != nil {
+				handler(event, setFocus)
+				return
+			}
+		}
 		if r.transfer.HasFocus() {
 			if handler := r.transfer.InputHandler(); handler != nil {
 				handler(event, setFocus)
 				return
 			}
 		}
+		if r.confirm.HasFocus() {
+			if handler := r.confirm.InputHandler(); handler != nil {
+				handler(event, setFocus)
+				return
+			}
+		}
+		if r.review.HasFocus() {
+			if handler := r.review.InputHandler(); handler != nil {
+				handler(event, setFocus)
+				return
+			}
+		}
 		if r.notification.HasFocus() {
 			if handler := r.notification.InputHandler(); handler != nil {
 				handler(event, setFocus)
@@ -296,7 +470,11 @@ func (r *Root) SetRect(x int, y int, width int, height int) {
 	r.Flex.SetRect(x, y, width, height)
 	r.query.SetCentral(r.GetInnerRect())
 	r.signin.SetCentral(r.GetInnerRect())
+	r.hdAccounts.SetCentral(r.GetInnerRect())
+	r.wallet.SetCentral(r.GetInnerRect())
 	r.transfer.SetCentral(r.GetInnerRect())
+	r.confirm.SetCentral(r.GetInnerRect())
+	r.review.SetCentral(r.GetInnerRect())
 	r.notification.SetCentral(r.GetInnerRect())
 }
 
@@ -305,6 +483,10 @@ func (r *Root) Draw(screen tcell.Screen) {
 	r.Flex.Draw(screen)
 	r.query.Draw(screen)
 	r.signin.Draw(screen)
+	r.hdAccounts.Draw(screen)
+	r.wallet.Draw(screen)
 	r.transfer.Draw(screen)
+	r.confirm.Draw(screen)
+	r.review.Draw(screen)
 	r.notification.Draw(screen)
 }
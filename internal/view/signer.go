@@ -1,6 +1,8 @@
 package view
 
 import (
+	"fmt"
+
 	"github.com/dyng/ramen/internal/common"
 	"github.com/dyng/ramen/internal/common/conv"
 	"github.com/dyng/ramen/internal/service"
@@ -9,14 +11,16 @@ import (
 	"github.com/rivo/tview"
 )
 
+// Signer shows the active account of wallet in header.
 type Signer struct {
 	tview.Primitive
 	app *App
 
-	signer      *service.Signer
+	wallet      *service.Wallet
 	initialized bool
 	avatar      *util.Avatar
 	table       *tview.Table
+	account     *util.Section
 	address     *util.Section
 	balance     *util.Section
 }
@@ -24,6 +28,7 @@ type Signer struct {
 func NewSigner(app *App) *Signer {
 	signer := &Signer{
 		app:    app,
+		wallet: service.NewWallet(),
 		avatar: util.NewAvatar(style.AvatarSize),
 		table:  tview.NewTable(),
 	}
@@ -43,27 +48,49 @@ func (si *Signer) initLayout() {
 }
 
 func (si *Signer) HasSignedIn() bool {
-	return si.signer != nil
+	return si.wallet.Active() != nil
+}
+
+// GetSigner returns the active signer, or nil if not signed in
+func (si *Signer) GetSigner() service.Signer {
+	active := si.wallet.Active()
+	if active == nil {
+		return nil
+	}
+	return active.Signer
 }
 
-func (si *Signer) GetSigner() *service.Signer {
-	return si.signer
+// GetWallet returns the wallet holding all signed in accounts
+func (si *Signer) GetWallet() *service.Wallet {
+	return si.wallet
 }
 
-func (si *Signer) SetSigner(signer *service.Signer) {
-	si.signer = signer
+// AddSigner adds a signer to wallet and makes it active
+func (si *Signer) AddSigner(label string, signer service.Signer) {
+	si.wallet.Add(label, signer)
 	si.refresh()
 }
 
+// SwitchTo makes the account at given index of wallet active
+func (si *Signer) SwitchTo(index int) {
+	if si.wallet.SetActive(index) {
+		si.refresh()
+	}
+}
+
 func (si *Signer) refresh() {
 	if !si.initialized {
 		si.layoutSomeSigner()
 		si.initialized = true
 	}
 
-	current := si.signer
+	active := si.wallet.Active()
+	current := active.Signer
 	addr := current.GetAddress()
 
+	// update label
+	si.account.SetText(fmt.Sprintf("%s (%d/%d)", tview.Escape(active.Label), si.wallet.ActiveIndex()+1, si.wallet.Size()))
+
 	// update avatar
 	si.avatar.SetAddress(addr)
 
@@ -91,20 +118,24 @@ func (si *Signer) layoutSomeSigner() {
 	flex.AddItem(si.avatar, style.AvatarSize*2+1, 0, false)
 	flex.AddItem(si.table, 0, 1, false)
 
+	account := util.NewSectionWithColor("Account:", s.SectionColor2, util.NAValue, s.FgColor)
+	account.AddToTable(si.table, 0, 0)
+	si.account = account
+
 	address := util.NewSectionWithColor("Address:", s.SectionColor2, util.NAValue, s.FgColor)
-	address.AddToTable(si.table, 0, 0)
+	address.AddToTable(si.table, 1, 0)
 	si.address = address
 
 	balance := util.NewSectionWithColor("Balance:", s.SectionColor2, util.NAValue, s.FgColor)
-	balance.AddToTable(si.table, 1, 0)
+	balance.AddToTable(si.table, 2, 0)
 	si.balance = balance
 
 	si.Primitive = flex
 }
 
 func (si *Signer) onNewBlock(block *common.Block) {
-	if si.signer != nil {
-		si.signer.UpdateBalance()
-		si.refresh()
+	if signer := si.GetSigner(); signer != nil {
+		signer.UpdateBalance()
+		si.app.QueueUpdateDraw(si.refresh)
 	}
 }
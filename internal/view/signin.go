@@ -1,6 +1,10 @@
 package view
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/dyng/ramen/internal/service"
 	"github.com/dyng/ramen/internal/view/format"
 	"github.com/dyng/ramen/internal/view/style"
 	"github.com/dyng/ramen/internal/view/util"
@@ -9,12 +13,37 @@ import (
 	"github.com/rivo/tview"
 )
 
+const (
+	// signInDialogHeight is the height of the sign in dialog.
+	signInDialogHeight = 13
+	// signInDialogWidth is the width of the sign in dialog.
+	signInDialogWidth = 100
+
+	// privateKeyOption is the option to sign in with a raw private key or mnemonic.
+	privateKeyOption = "Private Key / Mnemonic"
+	// externalSignerOption is the option to sign in with an external signer.
+	externalSignerOption = "External Signer"
+)
+
+// SignInDialog signs in with a keystore from the keystore directory, an
+// external signer, or a raw private key or mnemonic. It can also create a
+// new keystore, or export current signer's key into a keystore.
+//
+// Password field is used as passphrase when a mnemonic is given, and secret
+// field is used as account address when an external signer is chosen.
+// Private keys, mnemonics and passwords must never be logged.
 type SignInDialog struct {
-	*tview.InputField
+	*tview.Form
 	app       *App
 	display   bool
 	lastFocus tview.Primitive
 	spinner   *util.Spinner
+
+	account    *tview.DropDown
+	label      *tview.InputField
+	privateKey *tview.InputField
+	password   *tview.InputField
+	keystores  []*service.KeystoreFile
 }
 
 func NewSignInDialog(app *App) *SignInDialog {
@@ -27,52 +56,225 @@ func NewSignInDialog(app *App) *SignInDialog {
 	// setup layout
 	d.initLayout()
 
+	// setup keymap
+	d.initKeymap()
+
 	return d
 }
 
 func (d *SignInDialog) initLayout() {
 	s := d.app.config.Style()
 
-	input := tview.NewInputField()
-	input.SetFieldWidth(80)
-	input.SetBorder(true)
-	input.SetBorderColor(s.DialogBorderColor)
-	input.SetTitle(style.Padding("Private Key"))
-	input.SetTitleColor(s.FgColor)
-	input.SetLabel(" ")
-	input.SetMaskCharacter('*')
-	input.SetFieldBackgroundColor(s.DialogBgColor)
-	input.SetDoneFunc(d.handleKey)
-	d.InputField = input
+	form := tview.NewForm()
+	form.SetBorder(true)
+	form.SetBorderColor(s.DialogBorderColor)
+	form.SetTitle(style.BoldPadding("Sign In"))
+	form.SetLabelColor(s.InputFieldLableColor)
+	form.SetFieldBackgroundColor(s.InputFieldBgColor)
+	form.SetButtonsAlign(tview.AlignRight)
+	form.SetButtonBackgroundColor(s.ButtonBgColor)
+	form.AddDropDown("Account", []string{privateKeyOption}, 0, nil)
+	form.AddInputField("Label", "", 999, nil, nil)
+	form.AddPasswordField("Secret", "", 999, '*', nil)
+	form.AddPasswordField("Password", "", 999, '*', nil)
+	form.AddButton("Sign In", d.doSignIn)
+	form.AddButton("Create", d.doCreate)
+	form.AddButton("Export", d.doExport)
+	form.AddButton("Test Accounts", d.doTestAccounts)
+	d.account = form.GetFormItemByLabel("Account").(*tview.DropDown)
+	d.label = form.GetFormItemByLabel("Label").(*tview.InputField)
+	d.privateKey = form.GetFormItemByLabel("Secret").(*tview.InputField)
+	d.password = form.GetFormItemByLabel("Password").(*tview.InputField)
+	d.privateKey.SetDoneFunc(d.handleKey)
+	d.password.SetDoneFunc(d.handleKey)
+	d.Form = form
+}
+
+func (d *SignInDialog) initKeymap() {
+	InitKeymap(d, d.app)
+}
+
+// KeyMaps implements KeymapPrimitive
+func (d *SignInDialog) KeyMaps() util.KeyMaps {
+	keymaps := make(util.KeyMaps, 0)
+	keymaps = append(keymaps, util.NewSimpleKey(tcell.KeyEsc, d.Hide))
+	return keymaps
 }
 
 func (d *SignInDialog) handleKey(key tcell.Key) {
-	switch key {
-	case tcell.KeyEnter:
-		// start spinner
-		d.Loading()
+	if key == tcell.KeyEnter {
+		d.doSignIn()
+	}
+}
 
-		privateKey := d.GetText()
-		if privateKey == "" {
-			return
-		}
+// refresh reloads keystores in keystore directory.
+func (d *SignInDialog) refresh() {
+	keystores, err := d.app.service.ListKeystores()
+	if err != nil {
+		log.Error("Failed to list keystores", "dir", d.app.config.KeystoreDir, "error", err)
+		d.app.root.NotifyError(format.FineErrorMessage("Failed to list keystores", err))
+	}
+	d.setKeystores(keystores)
+}
 
-		go func() {
-			signer, err := d.app.service.GetSigner(privateKey)
-			signer.UpdateBalance() // populate balance cache
-			d.app.QueueUpdateDraw(func() {
-				if err != nil {
-					d.Finished()
-					log.Error("Failed to create signer", "error", err)
-					d.app.root.NotifyError(format.FineErrorMessage("Failed to create signer", err))
-				} else {
-					d.app.root.SignIn(signer)
-					d.Finished()
-				}
-			})
-		}()
-	case tcell.KeyEsc:
+func (d *SignInDialog) setKeystores(keystores []*service.KeystoreFile) {
+	d.keystores = keystores
+
+	options := []string{privateKeyOption}
+	for _, ks := range keystores {
+		options = append(options, fmt.Sprintf("%s (%s)", ks.Address.Hex(), ks.Name()))
+	}
+	if d.hasExternalSigner() {
+		options = append(options, fmt.Sprintf("%s (%s)", externalSignerOption, d.app.config.ExternalSigner))
+	}
+	d.account.SetOptions(options, nil)
+
+	// prefer external signer and keystore to raw private key
+	d.account.SetCurrentOption(len(options) - 1)
+}
+
+func (d *SignInDialog) hasExternalSigner() bool {
+	return d.app.config.ExternalSigner != ""
+}
+
+// isExternalSignerSelected returns true if external signer is selected.
+func (d *SignInDialog) isExternalSignerSelected() bool {
+	index, _ := d.account.GetCurrentOption()
+	return d.hasExternalSigner() && index == len(d.keystores)+1
+}
+
+// selectedKeystore returns the keystore to unlock, or nil if other option is selected.
+func (d *SignInDialog) selectedKeystore() *service.KeystoreFile {
+	index, _ := d.account.GetCurrentOption()
+	if index <= 0 || index > len(d.keystores) {
+		return nil
+	}
+	return d.keystores[index-1]
+}
+
+func (d *SignInDialog) doSignIn() {
+	keystore := d.selectedKeystore()
+	external := d.isExternalSignerSelected()
+	label := strings.TrimSpace(d.label.GetText())
+	privateKey := strings.TrimSpace(d.privateKey.GetText())
+	password := d.password.GetText()
+	if keystore == nil && !external && privateKey == "" {
+		return
+	}
+
+	// choose one of accounts derived from mnemonic
+	if keystore == nil && !external && service.IsMnemonic(privateKey) {
 		d.Hide()
+		d.app.root.ShowHDAccountPicker(label, privateKey, password)
+		return
+	}
+
+	// start spinner
+	d.Loading()
+
+	go func() {
+		var (
+			signer service.Signer
+			err    error
+		)
+		switch {
+		case external:
+			signer, err = d.app.service.GetExternalSigner(d.app.config.ExternalSigner, privateKey)
+		case keystore != nil:
+			signer, err = d.app.service.UnlockKeystore(keystore.Path, password)
+		default:
+			signer, err = d.app.service.GetSigner(privateKey)
+		}
+		if err == nil {
+			signer.UpdateBalance() // populate balance cache
+		}
+
+		d.app.QueueUpdateDraw(func() {
+			d.Finished()
+			if err != nil {
+				log.Error("Failed to create signer", "error", err)
+				d.app.root.NotifyError(format.FineErrorMessage("Failed to create signer", err))
+			} else {
+				d.app.root.SignIn(label, signer)
+			}
+		})
+	}()
+}
+
+// doCreate generates a new key and saves it as a keystore encrypted with password.
+func (d *SignInDialog) doCreate() {
+	password := d.password.GetText()
+	if password == "" {
+		d.app.root.NotifyError("Please enter a password to encrypt the new keystore.")
+		return
+	}
+
+	d.Loading()
+
+	go func() {
+		keystore, err := d.app.service.CreateKeystore(password)
+		d.app.QueueUpdateDraw(func() {
+			d.spinner.StopAndHide()
+			if err != nil {
+				log.Error("Failed to create keystore", "error", err)
+				d.app.root.NotifyError(format.FineErrorMessage("Failed to create keystore", err))
+				return
+			}
+
+			log.Info("Keystore created", "address", keystore.Address, "path", keystore.Path)
+			d.refresh()
+			d.selectKeystore(keystore)
+			d.app.root.NotifyInfo(fmt.Sprintf("Keystore of %s has been created at %s", keystore.Address.Hex(), keystore.Path))
+		})
+	}()
+}
+
+// doExport saves key of current signer as a keystore encrypted with password.
+func (d *SignInDialog) doExport() {
+	if !d.app.root.signer.HasSignedIn() {
+		d.app.root.NotifyError("Please sign in before exporting keystore.")
+		return
+	}
+	password := d.password.GetText()
+	if password == "" {
+		d.app.root.NotifyError("Please enter a password to encrypt the exported keystore.")
+		return
+	}
+
+	d.Loading()
+
+	signer := d.app.root.signer.GetSigner()
+	go func() {
+		keystore, err := d.app.service.ExportKeystore(signer, password)
+		d.app.QueueUpdateDraw(func() {
+			d.spinner.StopAndHide()
+			if err != nil {
+				log.Error("Failed to export keystore", "error", err)
+				d.app.root.NotifyError(format.FineErrorMessage("Failed to export keystore", err))
+				return
+			}
+
+			log.Info("Keystore exported", "address", keystore.Address, "path", keystore.Path)
+			d.refresh()
+			d.selectKeystore(keystore)
+			d.app.root.NotifyInfo(fmt.Sprintf("Keystore of %s has been exported to %s", keystore.Address.Hex(), keystore.Path))
+		})
+	}()
+}
+
+// doTestAccounts lists accounts of the test mnemonic, which are funded on
+// Hardhat and Anvil devnets.
+func (d *SignInDialog) doTestAccounts() {
+	d.Hide()
+	d.app.root.ShowHDAccountPicker(strings.TrimSpace(d.label.GetText()), service.TestMnemonic, "")
+}
+
+func (d *SignInDialog) selectKeystore(keystore *service.KeystoreFile) {
+	for i, ks := range d.keystores {
+		if ks.Path == keystore.Path {
+			d.account.SetCurrentOption(i + 1)
+			return
+		}
 	}
 }
 
@@ -105,8 +307,16 @@ func (d *SignInDialog) Finished() {
 	d.Hide()
 }
 
-func (d *SignInDialog) Clear() {
-	d.InputField.SetText("")
+// ClearAndRefresh clears entered secrets and reloads keystores
+func (d *SignInDialog) ClearAndRefresh() {
+	// clear
+	d.label.SetText("")
+	d.privateKey.SetText("")
+	d.password.SetText("")
+	d.SetFocus(0)
+
+	// refresh
+	d.refresh()
 }
 
 func (d *SignInDialog) Display(display bool) {
@@ -120,32 +330,26 @@ func (d *SignInDialog) IsDisplay() bool {
 // Draw implements tview.Primitive
 func (d *SignInDialog) Draw(screen tcell.Screen) {
 	if d.display {
-		d.InputField.Draw(screen)
+		d.Form.Draw(screen)
 	}
 	d.spinner.Draw(screen)
 }
 
 func (d *SignInDialog) SetCentral(x int, y int, width int, height int) {
-	inputWidth, inputHeight := d.inputSize()
-	if inputWidth > width-2 {
-		inputWidth = width - 2
+	dialogWidth := signInDialogWidth
+	dialogHeight := signInDialogHeight
+	if dialogWidth > width-2 {
+		dialogWidth = width - 2
 	}
-	if inputHeight > height-2 {
-		inputHeight = height
+	if dialogHeight > height-2 {
+		dialogHeight = height
 	}
-	ws := (width - inputWidth) / 2
-	hs := (height - inputHeight) / 2
-	d.InputField.SetRect(x+ws, y+hs, inputWidth, inputHeight)
-}
-
-func (d *SignInDialog) inputSize() (int, int) {
-	width := len(d.GetLabel()) + d.GetFieldWidth()
-	height := d.GetFieldHeight() + 2
-	return width, height
+	dialogX := x + ((width - dialogWidth) / 2)
+	dialogY := y + ((height - dialogHeight) / 2)
+	d.Form.SetRect(dialogX, dialogY, dialogWidth, dialogHeight)
 }
 
 func (d *SignInDialog) setSpinnerRect() {
-	x, y, _, _ := d.GetInnerRect()
-	sx := x + len(d.GetText()) + 1
-	d.spinner.SetRect(sx, y, 0, 0)
+	x, y, _, _ := d.GetRect()
+	d.spinner.SetRect(x+len(" Sign In ")+2, y, 0, 0)
 }
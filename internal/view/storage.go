@@ -0,0 +1,380 @@
+package view
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/dyng/ramen/internal/service"
+	"github.com/dyng/ramen/internal/view/format"
+	"github.com/dyng/ramen/internal/view/style"
+	"github.com/dyng/ramen/internal/view/util"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	blockLabel = "Block"
+	queryLabel = "Slot or Variable"
+)
+
+// StorageViewer reads storage slots of a contract at any block. Slots are
+// decoded into state variables if storage layout is known, and EIP-1967
+// proxy slots are shown automatically.
+type StorageViewer struct {
+	*tview.Flex
+	app *App
+
+	query     *tview.Form
+	block     *tview.InputField
+	input     *tview.InputField
+	proxy     *tview.TextView
+	variables *tview.Table
+	result    *tview.TextView
+	spinner   *util.Spinner
+
+	contract *service.Contract
+	loaded   common.BigInt // block of values, nil means latest block
+	values   map[string]*service.StorageValue
+}
+
+func NewStorageViewer(app *App) *StorageViewer {
+	v := &StorageViewer{
+		app:     app,
+		spinner: util.NewSpinner(app.Application),
+		values:  map[string]*service.StorageValue{},
+	}
+
+	// setup layout
+	v.initLayout()
+
+	return v
+}
+
+func (v *StorageViewer) initLayout() {
+	s := v.app.config.Style()
+
+	// query form
+	query := tview.NewForm()
+	query.SetHorizontal(true)
+	query.SetBorder(true)
+	query.SetBorderColor(s.BorderColor2)
+	query.SetTitle(style.Padding("Query"))
+	query.SetTitleColor(s.TitleColor2)
+	query.SetLabelColor(s.InputFieldLableColor)
+	query.SetFieldBackgroundColor(s.InputFieldBgColor)
+	query.AddInputField(blockLabel, "", 12, nil, nil)
+	query.AddInputField(queryLabel, "", 0, nil, nil)
+	v.block = query.GetFormItemByLabel(blockLabel).(*tview.InputField)
+	v.block.SetPlaceholder("latest")
+	v.input = query.GetFormItemByLabel(queryLabel).(*tview.InputField)
+	v.input.SetPlaceholder("e.g. 0x0, owner, balances[0x...]")
+	v.input.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			v.doQuery()
+		case tcell.KeyTab:
+			v.app.SetFocus(v.variables)
+		}
+	})
+	v.block.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			v.reload()
+		case tcell.KeyTab:
+			v.app.SetFocus(v.input)
+		}
+	})
+	v.query = query
+
+	// proxy slots
+	proxy := tview.NewTextView()
+	proxy.SetBorder(true)
+	proxy.SetBorderColor(s.BorderColor2)
+	proxy.SetTitle(style.Padding("EIP-1967 Proxy"))
+	proxy.SetTitleColor(s.TitleColor2)
+	proxy.SetDynamicColors(true)
+	v.proxy = proxy
+
+	// state variables
+	variables := tview.NewTable()
+	variables.SetBorder(true)
+	variables.SetBorderColor(s.BorderColor2)
+	variables.SetTitle(style.Padding("Variables"))
+	variables.SetTitleColor(s.TitleColor2)
+	variables.SetSelectable(true, false)
+	variables.SetFixed(1, 0)
+	headers := []string{"name", "type", "slot", "offset", "value"}
+	for i, header := range headers {
+		variables.SetCell(0, i,
+			tview.NewTableCell(strings.ToUpper(header)).
+				SetExpansion(1).
+				SetAlign(tview.AlignLeft).
+				SetStyle(s.TableHeaderStyle).
+				SetSelectable(false))
+	}
+	variables.SetSelectedFunc(v.handleSelected)
+	variables.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if util.AsKey(event) == tcell.KeyTAB {
+			v.app.SetFocus(v.block)
+			return nil
+		}
+		return event
+	})
+	v.variables = variables
+
+	// result
+	result := tview.NewTextView()
+	result.SetBorder(true)
+	result.SetBorderColor(s.MethResultBorderColor)
+	result.SetTitle(style.Padding("Result"))
+	result.SetDynamicColors(true)
+	v.result = result
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(query, 3, 0, false)
+	flex.AddItem(proxy, 5, 0, false)
+	flex.AddItem(variables, 0, 1, false)
+	flex.AddItem(result, 6, 0, false)
+	v.Flex = flex
+}
+
+// SetContract shows storage of contract, nil means current account is not a contract.
+func (v *StorageViewer) SetContract(contract *service.Contract) {
+	v.contract = contract
+	v.block.SetText("")
+	v.input.SetText("")
+	v.result.Clear()
+	v.reload()
+}
+
+// reload reads proxy slots and state variables at the block being inspected
+func (v *StorageViewer) reload() {
+	v.values = map[string]*service.StorageValue{}
+	v.refreshVariables()
+
+	if v.contract == nil {
+		v.proxy.SetText("[dimgray]Not a contract account[-]")
+		return
+	}
+
+	blockNumber, err := v.blockNumber()
+	if err != nil {
+		v.app.root.NotifyError(format.FineErrorMessage("Invalid block number", err))
+		return
+	}
+
+	v.loaded = blockNumber
+	v.loadProxySlotsAsync(blockNumber)
+	v.loadVariablesAsync(blockNumber)
+}
+
+// isLoading returns true if storage of contract at block is still the one
+// being shown, so that results of an earlier reload are dropped.
+func (v *StorageViewer) isLoading(contract *service.Contract, blockNumber common.BigInt) bool {
+	if v.contract != contract {
+		return false
+	}
+	if v.loaded == nil || blockNumber == nil {
+		return v.loaded == blockNumber
+	}
+	return v.loaded.Cmp(blockNumber) == 0
+}
+
+func (v *StorageViewer) loadProxySlotsAsync(blockNumber common.BigInt) {
+	contract := v.contract
+	v.proxy.SetText(util.NAValue)
+
+	go func() {
+		slots, err := contract.GetProxySlots(blockNumber)
+		if err != nil {
+			log.Error("Failed to read proxy slots", "address", contract.GetAddress(), "error", err)
+			return
+		}
+
+		v.app.QueueUpdateDraw(func() {
+			// contract or block may have changed during loading
+			if !v.isLoading(contract, blockNumber) {
+				return
+			}
+
+			if len(slots) == 0 {
+				v.proxy.SetText("[dimgray]Not an EIP-1967 proxy[-]")
+				return
+			}
+			lines := make([]string, len(slots))
+			for i, slot := range slots {
+				lines[i] = fmt.Sprintf("[::b]%-14s[::-] %s", slot.Name, slot.Address.Hex())
+			}
+			v.proxy.SetText(strings.Join(lines, "\n"))
+		})
+	}()
+}
+
+// loadVariablesAsync reads state variables of value type one by one
+func (v *StorageViewer) loadVariablesAsync(blockNumber common.BigInt) {
+	contract := v.contract
+	if !contract.HasStorageLayout() {
+		return
+	}
+
+	layout := contract.GetStorageLayout()
+	go func() {
+		for _, variable := range layout.Storage {
+			label := variable.Label
+			val, err := contract.ReadVariable(label, blockNumber)
+			if err != nil {
+				log.Error("Failed to read state variable", "address", contract.GetAddress(), "variable", label, "error", err)
+				continue
+			}
+
+			v.app.QueueUpdateDraw(func() {
+				// contract or block may have changed during loading
+				if v.isLoading(contract, blockNumber) {
+					v.values[label] = val
+					v.refreshVariables()
+				}
+			})
+		}
+	}()
+}
+
+func (v *StorageViewer) refreshVariables() {
+	// clear previous content at first
+	for i := v.variables.GetRowCount() - 1; i > 0; i-- {
+		v.variables.RemoveRow(i)
+	}
+
+	if v.contract == nil || !v.contract.HasStorageLayout() {
+		v.variables.SetCell(1, 0, tview.NewTableCell("[dimgray]storage layout is unknown, press 'i' to import solc output with storageLayout[-]").SetSelectable(false))
+		return
+	}
+
+	layout := v.contract.GetStorageLayout()
+	for i, variable := range layout.Storage {
+		row := i + 1
+
+		typeLabel := variable.Type
+		if t, ok := layout.Types[variable.Type]; ok {
+			typeLabel = t.Label
+		}
+		value := util.NAValue
+		if val, ok := v.values[variable.Label]; ok {
+			value = tview.Escape(val.Value)
+		}
+
+		j := 0
+		v.variables.SetCell(row, Inc(&j), tview.NewTableCell(variable.Label))
+		v.variables.SetCell(row, Inc(&j), tview.NewTableCell(tview.Escape(typeLabel)))
+		v.variables.SetCell(row, Inc(&j), tview.NewTableCell(variable.Slot))
+		v.variables.SetCell(row, Inc(&j), tview.NewTableCell(fmt.Sprint(variable.Offset)))
+		v.variables.SetCell(row, Inc(&j), tview.NewTableCell(value))
+	}
+}
+
+// handleSelected fills query with the selected variable, so that its elements
+// or members can be accessed.
+func (v *StorageViewer) handleSelected(row int, column int) {
+	if v.contract == nil || !v.contract.HasStorageLayout() {
+		return
+	}
+	layout := v.contract.GetStorageLayout()
+	if row <= 0 || row > len(layout.Storage) {
+		return
+	}
+
+	variable := layout.Storage[row-1]
+	text := variable.Label
+	if t, ok := layout.Types[variable.Type]; ok {
+		switch {
+		case t.Encoding == service.EncodingMapping || t.Base != "":
+			text += "["
+		case len(t.Members) > 0:
+			text += "."
+		}
+	}
+	v.input.SetText(text)
+	v.app.SetFocus(v.input)
+}
+
+// doQuery reads a raw slot, or a variable if storage layout is known
+func (v *StorageViewer) doQuery() {
+	if v.contract == nil {
+		return
+	}
+
+	blockNumber, err := v.blockNumber()
+	if err != nil {
+		v.app.root.NotifyError(format.FineErrorMessage("Invalid block number", err))
+		return
+	}
+
+	text := strings.TrimSpace(v.input.GetText())
+	if text == "" {
+		return
+	}
+
+	contract := v.contract
+	v.spinner.StartAndShow()
+
+	go func() {
+		var output string
+		var err error
+		if slot, e := service.ParseSlot(text); e == nil {
+			var raw common.Hash
+			raw, err = contract.ReadStorage(slot, blockNumber)
+			output = fmt.Sprintf("[::b]Slot:[::-]  %s\n[::b]Value:[::-] %s\n[::b]Int:[::-]   %s", slot.Hex(), raw.Hex(), raw.Big())
+		} else if contract.HasStorageLayout() {
+			var val *service.StorageValue
+			val, err = contract.ReadVariable(text, blockNumber)
+			if err == nil {
+				output = fmt.Sprintf("[::b]Slot:[::-]  %s (offset %d)\n[::b]Raw:[::-]   %s\n[::b]%s:[::-] %s",
+					val.Slot.Hex(), val.Offset, val.Raw.Hex(), tview.Escape(val.Path), tview.Escape(val.Value))
+			}
+		} else {
+			err = e
+		}
+
+		v.app.QueueUpdateDraw(func() {
+			v.spinner.StopAndHide()
+			if err != nil {
+				log.Error("Failed to read storage", "address", contract.GetAddress(), "query", text, "error", err)
+				v.app.root.NotifyError(format.FineErrorMessage("Failed to read storage", err))
+				return
+			}
+			v.result.SetText(output)
+		})
+	}()
+}
+
+// blockNumber returns the block being inspected, nil means latest block
+func (v *StorageViewer) blockNumber() (common.BigInt, error) {
+	text := strings.TrimSpace(v.block.GetText())
+	if text == "" || text == "latest" {
+		return nil, nil
+	}
+	n, ok := new(big.Int).SetString(text, 0)
+	if !ok || n.Sign() < 0 {
+		return nil, fmt.Errorf("%s is not a block number", text)
+	}
+	return n, nil
+}
+
+// Focus implements tview.Focus
+func (v *StorageViewer) Focus(delegate func(p tview.Primitive)) {
+	delegate(v.input)
+}
+
+// SetRect implements tview.SetRect
+func (v *StorageViewer) SetRect(x int, y int, width int, height int) {
+	v.Flex.SetRect(x, y, width, height)
+	v.spinner.SetCentral(v.result.GetInnerRect())
+}
+
+// Draw implements tview.Primitive
+func (v *StorageViewer) Draw(screen tcell.Screen) {
+	v.Flex.Draw(screen)
+	v.spinner.Draw(screen)
+}
@@ -0,0 +1,98 @@
+package view
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dyng/ramen/internal/view/util"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Tabs shows one of several primitives at a time, with a tab bar on the top.
+// Tabs are switched by number keys.
+type Tabs struct {
+	*tview.Flex
+	app *App
+
+	bar     *tview.TextView
+	pages   *tview.Pages
+	names   []string
+	current int
+}
+
+func NewTabs(app *App) *Tabs {
+	t := &Tabs{
+		Flex:  tview.NewFlex(),
+		app:   app,
+		bar:   tview.NewTextView(),
+		pages: tview.NewPages(),
+	}
+
+	// setup layout
+	t.initLayout()
+
+	return t
+}
+
+func (t *Tabs) initLayout() {
+	t.bar.SetDynamicColors(true)
+	t.bar.SetRegions(true)
+
+	t.SetDirection(tview.FlexRow)
+	t.AddItem(t.bar, 1, 0, false)
+	t.AddItem(t.pages, 0, 1, true)
+}
+
+// AddTab appends a tab, the first tab is shown by default
+func (t *Tabs) AddTab(name string, item tview.Primitive) {
+	t.pages.AddPage(name, item, true, len(t.names) == 0)
+	t.names = append(t.names, name)
+	t.refreshBar()
+}
+
+// SwitchTo shows the tab at given index
+func (t *Tabs) SwitchTo(index int) {
+	if index < 0 || index >= len(t.names) {
+		return
+	}
+
+	t.current = index
+	t.pages.SwitchToPage(t.names[index])
+	t.refreshBar()
+	t.app.SetFocus(t.pages)
+}
+
+// Current returns the index of the tab being shown
+func (t *Tabs) Current() int {
+	return t.current
+}
+
+// KeyMaps returns keymaps to switch tabs
+func (t *Tabs) KeyMaps() util.KeyMaps {
+	keymaps := make(util.KeyMaps, 0)
+
+	for i, name := range t.names {
+		index := i
+		keymaps = append(keymaps, util.KeyMap{
+			Key:         util.Key1 + tcell.Key(i),
+			Shortcut:    strconv.Itoa(i + 1),
+			Description: name,
+			Handler: func(*tcell.EventKey) {
+				t.SwitchTo(index)
+			},
+		})
+	}
+
+	return keymaps
+}
+
+func (t *Tabs) refreshBar() {
+	titles := make([]string, len(t.names))
+	for i, name := range t.names {
+		titles[i] = fmt.Sprintf(`["%d"] <%d> %s [""]`, i, i+1, name)
+	}
+	t.bar.SetText(strings.Join(titles, " "))
+	t.bar.Highlight(strconv.Itoa(t.current))
+}
@@ -0,0 +1,164 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dyng/ramen/internal/service"
+	"github.com/dyng/ramen/internal/view/format"
+	"github.com/dyng/ramen/internal/view/style"
+	"github.com/dyng/ramen/internal/view/util"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
+)
+
+type TokenList struct {
+	*tview.Table
+	app    *App
+	loader *util.Loader
+
+	balances []*service.TokenBalance
+}
+
+func NewTokenList(app *App) *TokenList {
+	t := &TokenList{
+		Table:    tview.NewTable(),
+		app:      app,
+		loader:   util.NewLoader(app.Application),
+		balances: []*service.TokenBalance{},
+	}
+
+	// setup layout
+	t.initLayout()
+
+	return t
+}
+
+func (t *TokenList) initLayout() {
+	s := t.app.config.Style()
+
+	t.SetBorder(true)
+	t.SetTitle(style.BoldPadding("Tokens"))
+
+	headers := []string{"symbol", "name", "balance", "value", "contract"}
+	for i, header := range headers {
+		t.SetCell(0, i,
+			tview.NewTableCell(strings.ToUpper(header)).
+				SetExpansion(1).
+				SetAlign(tview.AlignLeft).
+				SetStyle(s.TableHeaderStyle).
+				SetSelectable(false))
+	}
+	t.SetSelectable(true, false)
+	t.SetFixed(1, 1)
+	t.SetSelectedFunc(t.handleSelected)
+
+	// loader
+	t.loader.SetTitleColor(s.PrgBarTitleColor)
+	t.loader.SetBorderColor(s.PrgBarBorderColor)
+	t.loader.SetCellColor(s.PrgBarCellColor)
+}
+
+// SetBalances sets token balances to display
+func (t *TokenList) SetBalances(balances []*service.TokenBalance) {
+	t.balances = balances
+	t.refresh()
+}
+
+// LoadAsync loads token balances asynchronously
+func (t *TokenList) LoadAsync(loader func() ([]*service.TokenBalance, error)) {
+	// clear current content
+	t.Clear()
+
+	// start loading animation
+	t.loader.Start()
+	t.loader.Display(true)
+
+	go func() {
+		balances, err := loader()
+		t.app.QueueUpdateDraw(func() {
+			// stop loading animation
+			t.loader.Stop()
+			t.loader.Display(false)
+
+			if err == nil {
+				t.SetBalances(balances)
+			} else {
+				log.Error("Failed to load token balances", "error", err)
+				t.app.root.NotifyError(format.FineErrorMessage("Error occurs when loading token balances.", err))
+			}
+		})
+	}()
+}
+
+func (t *TokenList) Clear() {
+	for i := t.GetRowCount() - 1; i > 0; i-- {
+		t.RemoveRow(i)
+	}
+}
+
+func (t *TokenList) refresh() {
+	// clear previous content at first
+	t.Clear()
+
+	// show token count
+	t.SetTitle(style.BoldPadding(fmt.Sprintf("Tokens[[coral]%d[-]]", len(t.balances))))
+
+	ethPrice := t.app.root.chainInfo.GetEthPrice()
+	for i, tb := range t.balances {
+		row := i + 1
+
+		j := 0
+		t.SetCell(row, Inc(&j), tview.NewTableCell(tview.Escape(tb.Token.Symbol)))
+		t.SetCell(row, Inc(&j), tview.NewTableCell(tview.Escape(format.TruncateText(tb.Token.Name, 20))))
+		t.SetCell(row, Inc(&j), tview.NewTableCell(styledTokenAmount(tb)))
+		t.SetCell(row, Inc(&j), tview.NewTableCell(styledTokenValue(tb.Value(ethPrice))))
+		t.SetCell(row, Inc(&j), tview.NewTableCell(tb.Token.Address.Hex()))
+	}
+}
+
+// handleSelected jumps to the account page of selected token
+func (t *TokenList) handleSelected(row int, column int) {
+	if row <= 0 || row > len(t.balances) {
+		return
+	}
+
+	address := t.balances[row-1].Token.Address.Hex()
+	account, err := t.app.service.GetAccount(address)
+	if err != nil {
+		log.Error("Failed to fetch account of given address", "address", address, "error", err)
+		t.app.root.NotifyError(format.FineErrorMessage(
+			"Failed to fetch account of address %s", address, err))
+	} else {
+		t.app.root.ShowAccountPage(account)
+	}
+}
+
+// SetRect implements tview.SetRect
+func (t *TokenList) SetRect(x, y, width, height int) {
+	t.Table.SetRect(x, y, width, height)
+	t.loader.SetCentral(x, y, width, height)
+}
+
+// Draw implements tview.Draw
+func (t *TokenList) Draw(screen tcell.Screen) {
+	t.Table.Draw(screen)
+	t.loader.Draw(screen)
+}
+
+func styledTokenValue(value *decimal.Decimal) string {
+	if value == nil {
+		return util.NAValue
+	}
+	return "$" + value.StringFixed(2)
+}
+
+// styledTokenAmount marks raw balance of tokens whose decimals is unknown.
+func styledTokenAmount(tb *service.TokenBalance) string {
+	if !tb.Token.DecimalsKnown {
+		return tb.Amount().String() + " (raw)"
+	}
+	return tb.Amount().String()
+}
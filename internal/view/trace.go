@@ -0,0 +1,324 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/dyng/ramen/internal/common/conv"
+	"github.com/dyng/ramen/internal/service"
+	"github.com/dyng/ramen/internal/view/format"
+	"github.com/dyng/ramen/internal/view/style"
+	"github.com/dyng/ramen/internal/view/util"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gdamore/tcell/v2"
+	"github.com/pkg/errors"
+	"github.com/rivo/tview"
+)
+
+// TraceViewer shows the tree of internal calls made by a transaction, which
+// is replayed by debug_traceTransaction with callTracer.
+type TraceViewer struct {
+	*tview.Flex
+	app *App
+
+	tree    *tview.TreeView
+	detail  *tview.TextView
+	spinner *util.Spinner
+
+	transaction common.Transaction
+}
+
+func NewTraceViewer(app *App) *TraceViewer {
+	v := &TraceViewer{
+		app:     app,
+		spinner: util.NewSpinner(app.Application),
+	}
+
+	// setup layout
+	v.initLayout()
+
+	// setup keymap
+	v.initKeymap()
+
+	return v
+}
+
+func (v *TraceViewer) initLayout() {
+	s := v.app.config.Style()
+
+	// call tree
+	tree := tview.NewTreeView()
+	tree.SetGraphicsColor(s.SectionColor2)
+	tree.SetSelectedFunc(func(node *tview.TreeNode) {
+		node.SetExpanded(!node.IsExpanded())
+	})
+	tree.SetChangedFunc(func(node *tview.TreeNode) {
+		v.showDetail(node)
+	})
+	v.tree = tree
+
+	// detail of selected call
+	detail := tview.NewTextView()
+	detail.SetBorder(true)
+	detail.SetBorderColor(s.BorderColor2)
+	detail.SetTitle(style.Padding("Call"))
+	detail.SetTitleColor(s.TitleColor2)
+	detail.SetDynamicColors(true)
+	detail.SetWrap(true)
+	v.detail = detail
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.SetBorder(true)
+	flex.SetTitle(style.BoldPadding("Call Trace"))
+	flex.SetTitleColor(s.TitleColor)
+	flex.SetBorderColor(s.BorderColor)
+	flex.AddItem(tree, 0, 3, true)
+	flex.AddItem(detail, 0, 2, false)
+	v.Flex = flex
+}
+
+func (v *TraceViewer) initKeymap() {
+	InitKeymap(v, v.app)
+}
+
+// KeyMaps implements bodyPage
+func (v *TraceViewer) KeyMaps() util.KeyMaps {
+	keymaps := make(util.KeyMaps, 0)
+
+	// KeyE: expand all calls
+	keymaps = append(keymaps, util.KeyMap{
+		Key:         util.KeyE,
+		Shortcut:    "e",
+		Description: "Expand All",
+		Handler: func(*tcell.EventKey) {
+			v.setExpanded(true)
+		},
+	})
+
+	// KeyC: collapse all calls but the top one
+	keymaps = append(keymaps, util.KeyMap{
+		Key:         util.KeyC,
+		Shortcut:    "c",
+		Description: "Collapse All",
+		Handler: func(*tcell.EventKey) {
+			v.setExpanded(false)
+		},
+	})
+
+	// KeyT: back to transaction page
+	keymaps = append(keymaps, util.KeyMap{
+		Key:         util.KeyT,
+		Shortcut:    "t",
+		Description: "To Transaction",
+		Handler: func(*tcell.EventKey) {
+			if v.transaction != nil {
+				v.app.root.ShowTransactionPage(v.transaction)
+			}
+		},
+	})
+
+	return keymaps
+}
+
+// SetTransaction traces transaction and shows its calls.
+func (v *TraceViewer) SetTransaction(transaction common.Transaction) {
+	v.transaction = transaction
+	v.detail.Clear()
+
+	if transaction.BlockNumber() == nil {
+		v.setMessage("[dimgray]transaction is not mined yet[-]")
+		return
+	}
+
+	v.setMessage("")
+	v.loadAsync()
+}
+
+func (v *TraceViewer) loadAsync() {
+	txn := v.transaction
+	v.spinner.StartAndShow()
+
+	go func() {
+		trace, err := v.app.service.TraceTransaction(txn.Hash())
+		if err != nil && !errors.Is(err, service.ErrTraceNotSupported) {
+			log.Error("Failed to trace transaction", "hash", txn.Hash(), "error", err)
+		}
+
+		v.app.QueueUpdateDraw(func() {
+			v.spinner.StopAndHide()
+
+			// transaction may have changed during loading
+			if v.transaction == nil || v.transaction.Hash() != txn.Hash() {
+				return
+			}
+
+			switch {
+			case errors.Is(err, service.ErrTraceNotSupported):
+				v.setMessage("[dimgray]provider does not support debug_traceTransaction[-]")
+			case err != nil:
+				v.setMessage("[crimson]failed to trace transaction[-]")
+				v.app.root.NotifyError(format.FineErrorMessage(
+					"Failed to trace transaction %s", txn.Hash().Hex(), err))
+			default:
+				root := v.newNode(trace)
+				v.tree.SetRoot(root)
+				v.tree.SetCurrentNode(root)
+				v.showDetail(root)
+			}
+		})
+	}()
+}
+
+// setMessage shows a message in place of call tree.
+func (v *TraceViewer) setMessage(message string) {
+	root := tview.NewTreeNode(message).SetSelectable(false)
+	v.tree.SetRoot(root)
+	v.tree.SetCurrentNode(nil)
+}
+
+func (v *TraceViewer) newNode(trace *service.Trace) *tview.TreeNode {
+	node := tview.NewTreeNode(styledTraceCall(trace)).
+		SetReference(trace).
+		SetExpanded(true)
+	for _, call := range trace.Calls {
+		node.AddChild(v.newNode(call))
+	}
+	return node
+}
+
+// setExpanded expands or collapses all calls, the top call is always expanded.
+func (v *TraceViewer) setExpanded(expanded bool) {
+	root := v.tree.GetRoot()
+	if root == nil {
+		return
+	}
+	root.Walk(func(node, parent *tview.TreeNode) bool {
+		node.SetExpanded(expanded || parent == nil)
+		return true
+	})
+	if !expanded {
+		v.tree.SetCurrentNode(root)
+		v.showDetail(root)
+	}
+}
+
+func (v *TraceViewer) showDetail(node *tview.TreeNode) {
+	v.detail.Clear()
+	if node == nil {
+		return
+	}
+	trace, ok := node.GetReference().(*service.Trace)
+	if !ok {
+		return
+	}
+
+	lines := []string{
+		fmt.Sprintf("[::b]Type:[::-] %s", trace.Type),
+		fmt.Sprintf("[::b]From:[::-] %s", trace.From.Hex()),
+		fmt.Sprintf("[::b]To:[::-] %s", format.NormalizeReceiverAddress(trace.To)),
+		fmt.Sprintf("[::b]Value:[::-] %s Ether", format.Ether(trace.GetValue())),
+		fmt.Sprintf("[::b]Gas:[::-] %d [dimgray](limit %d)[-]", trace.GasUsed, trace.Gas),
+	}
+
+	if trace.Method != nil {
+		title := tview.Escape(trace.Method.Sig)
+		if trace.Guessed {
+			title += " [dimgray](guessed)[-]"
+		}
+		lines = append(lines, fmt.Sprintf("[::b]Function:[::-] %s", title))
+		for i, arg := range trace.Method.Inputs {
+			lines = append(lines, fmt.Sprintf("  [dimgray]%s:[-] %s", argumentName(arg, i), styledTraceValue(arg.Type, trace.Args[i])))
+		}
+		if len(trace.Outputs) > 0 {
+			lines = append(lines, "[::b]Returns:[::-]")
+			for i, arg := range trace.Method.Outputs {
+				lines = append(lines, fmt.Sprintf("  [dimgray]%s:[-] %s", argumentName(arg, i), styledTraceValue(arg.Type, trace.Outputs[i])))
+			}
+		}
+	}
+
+	if trace.Method == nil && len(trace.Input) > 0 {
+		lines = append(lines, fmt.Sprintf("[::b]Input:[::-] 0x%x", []byte(trace.Input)))
+	}
+	if trace.Outputs == nil && len(trace.Output) > 0 {
+		lines = append(lines, fmt.Sprintf("[::b]Output:[::-] 0x%x", []byte(trace.Output)))
+	}
+	if trace.Failed() {
+		lines = append(lines, fmt.Sprintf("[::b]Error:[::-] [crimson]%s[-]", tview.Escape(trace.Error)))
+	}
+	if trace.Reason != "" {
+		lines = append(lines, fmt.Sprintf("[::b]Revert Reason:[::-] [crimson]%s[-]", tview.Escape(trace.Reason)))
+	}
+
+	v.detail.SetText(strings.Join(lines, "\n"))
+	v.detail.ScrollToBeginning()
+}
+
+// styledTraceCall describes a call in one line, such as
+// `CALL 0x... transfer(0x..., 100) → true`.
+func styledTraceCall(trace *service.Trace) string {
+	text := fmt.Sprintf("[dodgerblue]%s[-] %s", trace.Type, format.NormalizeReceiverAddress(trace.To))
+
+	if trace.Method != nil {
+		args := make([]string, len(trace.Args))
+		for i, arg := range trace.Args {
+			args[i] = styledTraceValue(trace.Method.Inputs[i].Type, arg)
+		}
+		text += fmt.Sprintf(" [::b]%s[::-](%s)", tview.Escape(trace.Method.Name), strings.Join(args, ", "))
+		if trace.Guessed {
+			text += " [dimgray](guessed)[-]"
+		}
+	} else if len(trace.Input) >= 4 && !trace.IsCreate() {
+		text += fmt.Sprintf(" [::b]0x%x[::-]", []byte(trace.Input[:4]))
+	}
+
+	if value := trace.GetValue(); value.Sign() > 0 {
+		text += fmt.Sprintf(" [sandybrown]%s Ether[-]", format.Ether(value))
+	}
+
+	if len(trace.Outputs) > 0 {
+		outputs := make([]string, len(trace.Outputs))
+		for i, output := range trace.Outputs {
+			outputs[i] = styledTraceValue(trace.Method.Outputs[i].Type, output)
+		}
+		text += " → " + strings.Join(outputs, ", ")
+	}
+
+	if trace.Failed() {
+		reason := trace.Reason
+		if reason == "" {
+			reason = trace.Error
+		}
+		text += fmt.Sprintf(" [crimson]✗ %s[-]", tview.Escape(reason))
+	}
+	return text
+}
+
+func styledTraceValue(t abi.Type, v any) string {
+	valStr, err := conv.PackArgument(t, v)
+	if err != nil {
+		valStr = fmt.Sprint(v)
+	}
+	return tview.Escape(valStr)
+}
+
+func argumentName(arg abi.Argument, i int) string {
+	if arg.Name == "" {
+		return fmt.Sprintf("#%d", i)
+	}
+	return tview.Escape(arg.Name)
+}
+
+// SetRect implements tview.SetRect
+func (v *TraceViewer) SetRect(x int, y int, width int, height int) {
+	v.Flex.SetRect(x, y, width, height)
+	v.spinner.SetCentral(v.tree.GetInnerRect())
+}
+
+// Draw implements tview.Primitive
+func (v *TraceViewer) Draw(screen tcell.Screen) {
+	v.Flex.Draw(screen)
+	v.spinner.Draw(screen)
+}
@@ -6,12 +6,15 @@ import (
 
 	"github.com/dyng/ramen/internal/common"
 	"github.com/dyng/ramen/internal/common/conv"
+	"github.com/dyng/ramen/internal/service"
 	"github.com/dyng/ramen/internal/view/format"
 	"github.com/dyng/ramen/internal/view/style"
 	"github.com/dyng/ramen/internal/view/util"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
 )
 
 var (
@@ -25,13 +28,20 @@ type TransactionDetail struct {
 
 	transaction common.Transaction
 	hash        *util.Section
+	status      *util.Section
 	blockNumber *util.Section
 	timestamp   *util.Section
 	from        *util.Section
 	to          *util.Section
 	value       *util.Section
+	gasUsed     *util.Section
+	gasPrice    *util.Section
+	fee         *util.Section
+	contract    *util.Section
 	data        *util.Section
 	calldata    *CallData
+	logs        *EventLogList
+	changes     *tview.TextView
 }
 
 func NewTransactionDetail(app *App) *TransactionDetail {
@@ -59,33 +69,74 @@ func (t *TransactionDetail) initLayout() {
 	t.SetBorderColor(s.BorderColor)
 
 	table := tview.NewTable()
+	row := 0
 
 	t.hash = util.NewSectionWithStyle("Hash", util.EmptyValue, s)
-	t.hash.AddToTable(table, 0, 0)
+	t.hash.AddToTable(table, Inc(&row), 0)
+
+	t.status = util.NewSectionWithStyle("Status", util.EmptyValue, s)
+	t.status.AddToTable(table, Inc(&row), 0)
 
 	t.blockNumber = util.NewSectionWithStyle("BlockNumber", util.EmptyValue, s)
-	t.blockNumber.AddToTable(table, 1, 0)
+	t.blockNumber.AddToTable(table, Inc(&row), 0)
 
 	t.timestamp = util.NewSectionWithStyle("Timestamp", util.EmptyValue, s)
-	t.timestamp.AddToTable(table, 2, 0)
+	t.timestamp.AddToTable(table, Inc(&row), 0)
 
 	t.from = util.NewSectionWithStyle("From", util.EmptyValue, s)
-	t.from.AddToTable(table, 3, 0)
+	t.from.AddToTable(table, Inc(&row), 0)
 
 	t.to = util.NewSectionWithStyle("To", util.EmptyValue, s)
-	t.to.AddToTable(table, 4, 0)
+	t.to.AddToTable(table, Inc(&row), 0)
 
 	t.value = util.NewSectionWithStyle("Value", util.EmptyValue, s)
-	t.value.AddToTable(table, 5, 0)
+	t.value.AddToTable(table, Inc(&row), 0)
+
+	t.gasUsed = util.NewSectionWithStyle("GasUsed", util.EmptyValue, s)
+	t.gasUsed.AddToTable(table, Inc(&row), 0)
+
+	t.gasPrice = util.NewSectionWithStyle("GasPrice", util.EmptyValue, s)
+	t.gasPrice.AddToTable(table, Inc(&row), 0)
+
+	t.fee = util.NewSectionWithStyle("Fee", util.EmptyValue, s)
+	t.fee.AddToTable(table, Inc(&row), 0)
+
+	t.contract = util.NewSectionWithStyle("Created", util.EmptyValue, s)
+	t.contract.AddToTable(table, Inc(&row), 0)
 
 	t.data = util.NewSectionWithStyle("Data", util.EmptyValue, s)
-	t.data.AddToTable(table, 6, 0)
+	t.data.AddToTable(table, Inc(&row), 0)
 
 	t.calldata = NewCalldata(t.app)
 
+	t.logs = NewEventLogList(t.app)
+
+	// state changes of simulated request, hidden for mined transactions
+	changes := tview.NewTextView()
+	changes.SetBorder(true)
+	changes.SetBorderColor(s.BorderColor2)
+	changes.SetTitle(style.Padding("Simulated Changes"))
+	changes.SetTitleColor(s.TitleColor2)
+	changes.SetDynamicColors(true)
+	t.changes = changes
+
 	// add to layout
-	t.AddItem(table, 7, 0, false)
+	t.AddItem(table, row, 0, false)
 	t.AddItem(t.calldata, 0, 1, false)
+	t.AddItem(t.logs, 0, 2, true)
+	t.AddItem(t.changes, 0, 0, false)
+}
+
+// showChanges switches between event logs of a mined transaction and state
+// changes of a simulated request.
+func (t *TransactionDetail) showChanges(show bool) {
+	if show {
+		t.ResizeItem(t.logs, 0, 0)
+		t.ResizeItem(t.changes, 0, 2)
+	} else {
+		t.ResizeItem(t.logs, 0, 2)
+		t.ResizeItem(t.changes, 0, 0)
+	}
 }
 
 func (t *TransactionDetail) initKeymap() {
@@ -114,6 +165,28 @@ func (t *TransactionDetail) KeyMaps() util.KeyMaps {
 		},
 	})
 
+	// KeyE: jump to emitter's account page
+	keymaps = append(keymaps, util.KeyMap{
+		Key:         util.KeyE,
+		Shortcut:    "e",
+		Description: "To Emitter",
+		Handler: func(*tcell.EventKey) {
+			t.logs.ViewEmitter()
+		},
+	})
+
+	// KeyC: show call trace
+	keymaps = append(keymaps, util.KeyMap{
+		Key:         util.KeyC,
+		Shortcut:    "c",
+		Description: "Call Trace",
+		Handler: func(*tcell.EventKey) {
+			if t.transaction != nil {
+				t.app.root.ShowTracePage(t.transaction)
+			}
+		},
+	})
+
 	return keymaps
 }
 
@@ -122,18 +195,56 @@ func (t *TransactionDetail) SetTransaction(transaction common.Transaction) {
 	t.refresh()
 }
 
+// SetRequest shows a transaction request which is not signed yet, along with
+// the result of simulating it, which is nil if simulation is skipped.
+func (t *TransactionDetail) SetRequest(txnReq *common.TxnRequest, sim *service.Simulation) {
+	t.transaction = nil
+	t.hash.SetText("[dimgray]not signed yet[-]")
+	t.status.SetText(StyledSimulation(sim))
+	t.blockNumber.SetText(util.NAValue)
+	t.timestamp.SetText(util.NAValue)
+	t.from.SetText(txnReq.From.Hex())
+	t.to.SetText(format.NormalizeReceiverAddress(txnReq.To))
+	t.value.SetText(fmt.Sprintf("%s (%g Ether)", txnReq.Value, conv.ToEther(txnReq.Value)))
+	if sim != nil {
+		t.gasUsed.SetText(fmt.Sprintf("%d [dimgray](simulated, limit %d)[-]", sim.GasUsed, txnReq.GasLimit))
+	} else {
+		t.gasUsed.SetText(fmt.Sprintf("%d [dimgray](limit)[-]", txnReq.GasLimit))
+	}
+	t.gasPrice.SetText(styledRequestFee(txnReq))
+	t.fee.SetText(fmt.Sprintf("%s [dimgray](max)[-]", t.styledFee(txnReq.MaxFee())))
+	t.contract.SetText(util.EmptyValue)
+	t.data.SetText(format.BytesToString(txnReq.Data, 64))
+	t.calldata.LoadAsync(txnReq.To, txnReq.Data)
+	t.logs.SetLogs(nil)
+	if sim != nil {
+		t.changes.SetText(StyledStateChanges(sim))
+		t.changes.ScrollToBeginning()
+		t.showChanges(true)
+	} else {
+		t.showChanges(false)
+	}
+}
+
 func (t *TransactionDetail) ViewSender() {
+	if t.transaction == nil {
+		return
+	}
 	log.Debug("View transaction sender", "transaction", t.transaction.Hash())
-	t.viewAccount(t.from.GetText())
+	t.viewAccount(t.transaction.From().Hex())
 }
 
 func (t *TransactionDetail) ViewReceiver() {
+	if t.transaction == nil {
+		return
+	}
 	log.Debug("View transaction receiver", "transaction", t.transaction.Hash())
-	t.viewAccount(t.to.GetText())
+	t.viewAccount(format.NormalizeReceiverAddress(t.transaction.To()))
 }
 
 func (t *TransactionDetail) refresh() {
 	txn := t.transaction
+	t.showChanges(false)
 	t.hash.SetText(txn.Hash().Hex())
 	t.blockNumber.SetText(txn.BlockNumber().String())
 	t.timestamp.SetText(format.ToDatetime(txn.Timestamp()))
@@ -142,6 +253,136 @@ func (t *TransactionDetail) refresh() {
 	t.value.SetText(fmt.Sprintf("%s (%g Ether)", txn.Value(), conv.ToEther(txn.Value())))
 	t.data.SetText(format.BytesToString(txn.Data(), 64))
 	t.calldata.LoadAsync(t.transaction.To(), t.transaction.Data())
+	t.loadReceiptAsync()
+	t.loadNamesAsync()
+}
+
+func (t *TransactionDetail) loadNamesAsync() {
+	txn := t.transaction
+	addrs := []common.Address{*txn.From()}
+	if txn.To() != nil {
+		addrs = append(addrs, *txn.To())
+	}
+
+	go func() {
+		names, err := t.app.service.GetENS().LookupAddresses(addrs)
+		if err != nil {
+			log.Error("Failed to look up ENS names", "hash", txn.Hash(), "error", err)
+			return
+		}
+
+		t.app.QueueUpdateDraw(func() {
+			// transaction may have changed during loading
+			if t.transaction == nil || t.transaction.Hash() != txn.Hash() {
+				return
+			}
+			t.from.SetText(StyledAddressWithName(txn.From().Hex(), names[*txn.From()]))
+			if txn.To() != nil {
+				t.to.SetText(StyledAddressWithName(txn.To().Hex(), names[*txn.To()]))
+			}
+		})
+	}()
+}
+
+func (t *TransactionDetail) loadReceiptAsync() {
+	// clear previous receipt
+	t.setReceipt(nil)
+	t.logs.SetLogs(nil)
+
+	txn := t.transaction
+	if txn.BlockNumber() == nil {
+		t.status.SetText(StyledReceiptStatus(nil))
+		return
+	}
+
+	go func() {
+		receipt, err := t.app.service.GetReceipt(txn.Hash())
+		if err != nil {
+			log.Error("Failed to fetch transaction receipt", "hash", txn.Hash(), "error", err)
+			return
+		}
+
+		var logs []*service.EventLog
+		if receipt != nil {
+			// receiver emits most logs, make sure its ABI is loaded
+			if txn.To() != nil && len(txn.Data()) > 0 {
+				if _, err := t.app.service.GetContract(*txn.To()); err != nil {
+					log.Debug("Cannot fetch receiver of transaction", "address", *txn.To(), "error", err)
+				}
+			}
+			logs = t.app.service.DecodeLogs(receipt.Logs)
+		}
+
+		t.app.QueueUpdateDraw(func() {
+			// transaction may have changed during loading
+			if t.transaction != nil && t.transaction.Hash() == txn.Hash() {
+				t.setReceipt(receipt)
+				t.logs.SetLogs(logs)
+				if receipt != nil && !receipt.Succeeded() {
+					t.loadRevertReasonAsync(receipt)
+				}
+			}
+		})
+	}()
+}
+
+// loadRevertReasonAsync replays a failed transaction to find out why it is
+// reverted, and shows the reason next to its status.
+func (t *TransactionDetail) loadRevertReasonAsync(receipt *common.Receipt) {
+	txn := t.transaction
+
+	go func() {
+		reason, err := t.app.service.GetRevertReason(txn)
+		if err != nil {
+			log.Error("Failed to replay transaction", "hash", txn.Hash(), "error", err)
+			return
+		}
+		if reason == "" {
+			return
+		}
+
+		t.app.QueueUpdateDraw(func() {
+			// transaction may have changed during loading
+			if t.transaction != nil && t.transaction.Hash() == txn.Hash() {
+				t.status.SetText(fmt.Sprintf("%s %s", StyledReceiptStatus(receipt), StyledRevertReason(reason)))
+			}
+		})
+	}()
+}
+
+func (t *TransactionDetail) setReceipt(receipt *common.Receipt) {
+	if receipt == nil {
+		t.status.SetText(util.NAValue)
+		t.gasUsed.SetText(util.NAValue)
+		t.gasPrice.SetText(util.NAValue)
+		t.fee.SetText(util.NAValue)
+		t.contract.SetText(util.EmptyValue)
+		return
+	}
+
+	t.status.SetText(StyledReceiptStatus(receipt))
+	t.gasUsed.SetText(fmt.Sprint(receipt.GasUsed))
+
+	if receipt.EffectiveGasPrice != nil {
+		t.gasPrice.SetText(fmt.Sprintf("%s Gwei", format.Gwei(receipt.EffectiveGasPrice)))
+		t.fee.SetText(t.styledFee(receipt.Fee()))
+	}
+
+	if receipt.HasContractAddress() {
+		t.contract.SetText(receipt.ContractAddress.Hex())
+	} else {
+		t.contract.SetText(util.EmptyValue)
+	}
+}
+
+func (t *TransactionDetail) styledFee(fee common.BigInt) string {
+	price := t.app.root.chainInfo.GetEthPrice()
+	if price == nil {
+		return fmt.Sprintf("%s Ether", format.Ether(fee))
+	}
+
+	usd := decimal.NewFromBigInt(fee, -18).Mul(*price)
+	return fmt.Sprintf("%s Ether ($%s)", format.Ether(fee), usd.StringFixed(2))
 }
 
 func (t *TransactionDetail) viewAccount(address string) {
@@ -193,7 +434,7 @@ func (c *CallData) LoadAsync(address *common.Address, data []byte) {
 			} else {
 				c.app.QueueUpdateDraw(func() {
 					hasABI := c.parseData(*address, data)
-					if !hasABI {
+					if !hasABI && !c.guessData(data) {
 						c.warnNoABI()
 					}
 					c.spinner.StopAndHide()
@@ -204,8 +445,6 @@ func (c *CallData) LoadAsync(address *common.Address, data []byte) {
 }
 
 func (c *CallData) parseData(address common.Address, data []byte) bool {
-	s := c.app.config.Style()
-
 	if len(data) == 0 {
 		return false
 	}
@@ -226,9 +465,45 @@ func (c *CallData) parseData(address common.Address, data []byte) bool {
 		return false
 	}
 
+	c.showMethod(method, method.Name, args)
+
+	return true
+}
+
+// guessData decodes calldata by its selector in signature database, and
+// returns false if selector is unknown.
+func (c *CallData) guessData(data []byte) bool {
+	s := c.app.config.Style()
+
+	guessed := c.app.service.GetSignatureDB().GuessCalldata(data)
+	if guessed == nil {
+		return false
+	}
+
+	title := fmt.Sprintf("%s [dimgray](guessed)[-]", tview.Escape(guessed.Method.Sig))
+	c.showMethod(guessed.Method, title, guessed.Args)
+
+	// list all candidates if selector is ambiguous
+	if len(guessed.Candidates) > 1 {
+		row := c.GetRowCount()
+		for i, candidate := range guessed.Candidates {
+			c.SetCell(row+i, 0, tview.NewTableCell(""))
+			if i == 0 {
+				c.SetCell(row+i, 1, tview.NewTableCell("candidates").SetTextColor(s.SectionColor2))
+			}
+			c.SetCell(row+i, 2, tview.NewTableCell(tview.Escape(candidate)))
+		}
+	}
+
+	return true
+}
+
+func (c *CallData) showMethod(method *abi.Method, title string, args []any) {
+	s := c.app.config.Style()
+
 	// set method name
 	c.SetCell(0, 1, tview.NewTableCell("[dodgerblue::b]function[-:-:-]"))
-	c.SetCell(0, 2, tview.NewTableCell(method.Name).SetAttributes(tcell.AttrBold))
+	c.SetCell(0, 2, tview.NewTableCell(title).SetAttributes(tcell.AttrBold))
 
 	// set arguments
 	for i, argVal := range args {
@@ -244,12 +519,10 @@ func (c *CallData) parseData(address common.Address, data []byte) bool {
 		c.SetCell(i+1, 1, tview.NewTableCell(arg.Name).SetTextColor(s.SectionColor2))
 		c.SetCell(i+1, 2, tview.NewTableCell(valStr))
 	}
-
-	return true
 }
 
 func (c *CallData) warnNoABI() {
-	c.SetCell(0, 1, tview.NewTableCell("[crimson]cannot decode calldata as ABI is unavailable[-]"))
+	c.SetCell(0, 1, tview.NewTableCell("[crimson]cannot decode calldata as both ABI and selector are unknown[-]"))
 }
 
 func (c *CallData) setSpinnerRect() {
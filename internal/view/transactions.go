@@ -58,9 +58,9 @@ func (t *TransactionList) initLayout() {
 	// table
 	var headers []string
 	if t.showInOut {
-		headers = []string{"hash", "block", "from", "to", "", "value", "datetime"}
+		headers = []string{"hash", "status", "block", "from", "to", "", "value", "datetime"}
 	} else {
-		headers = []string{"hash", "block", "from", "to", "value", "datetime"}
+		headers = []string{"hash", "status", "block", "from", "to", "value", "datetime"}
 	}
 	for i, header := range headers {
 		t.SetCell(0, i,
@@ -160,6 +160,10 @@ func (t *TransactionList) LoadAsync(loader func() (common.Transactions, error))
 
 	go func() {
 		txns, err := loader()
+		if err == nil {
+			t.PrefetchReceipts(txns)
+			t.PrefetchNames(txns)
+		}
 		t.app.QueueUpdateDraw(func() {
 			// stop loading animation
 			t.loader.Stop()
@@ -177,6 +181,36 @@ func (t *TransactionList) LoadAsync(loader func() (common.Transactions, error))
 	}()
 }
 
+// PrefetchReceipts fetches receipts of transactions in one batch, so that
+// their status can be shown. It blocks, so should not be called in UI thread.
+func (t *TransactionList) PrefetchReceipts(txns common.Transactions) {
+	if len(txns) == 0 {
+		return
+	}
+	if _, err := t.app.service.GetReceipts(txns); err != nil {
+		log.Error("Failed to fetch receipts of transactions", "error", err)
+	}
+}
+
+// PrefetchNames looks up ENS names of senders and receivers in batch, so that
+// they can be shown. It blocks, so should not be called in UI thread.
+func (t *TransactionList) PrefetchNames(txns common.Transactions) {
+	if len(txns) == 0 {
+		return
+	}
+
+	addrs := make([]common.Address, 0, 2*len(txns))
+	for _, txn := range txns {
+		addrs = append(addrs, *txn.From())
+		if txn.To() != nil {
+			addrs = append(addrs, *txn.To())
+		}
+	}
+	if _, err := t.app.service.GetENS().LookupAddresses(addrs); err != nil {
+		log.Error("Failed to look up ENS names of transactions", "error", err)
+	}
+}
+
 // ViewSender jumps to the sender's account page
 func (t *TransactionList) ViewSender() {
 	current := t.selection()
@@ -226,10 +260,10 @@ func (t *TransactionList) refresh() {
 
 		j := 0
 		t.SetCell(row, Inc(&j), tview.NewTableCell(format.TruncateText(tx.Hash().Hex(), 8)))
+		t.SetCell(row, Inc(&j), tview.NewTableCell(t.styledStatus(tx)))
 		t.SetCell(row, Inc(&j), tview.NewTableCell(tx.BlockNumber().String()))
-		t.SetCell(row, Inc(&j), tview.NewTableCell(format.TruncateText(tx.From().Hex(), 20)))
-		t.SetCell(row, Inc(&j), tview.NewTableCell(format.TruncateText(
-			format.NormalizeReceiverAddress(tx.To()), 20)))
+		t.SetCell(row, Inc(&j), tview.NewTableCell(t.styledAddress(tx.From())))
+		t.SetCell(row, Inc(&j), tview.NewTableCell(t.styledAddress(tx.To())))
 		if t.showInOut {
 			t.SetCell(row, Inc(&j), tview.NewTableCell(StyledTxnDirection(t.base, tx)))
 		}
@@ -238,6 +272,27 @@ func (t *TransactionList) refresh() {
 	}
 }
 
+func (t *TransactionList) styledStatus(txn common.Transaction) string {
+	receipt, found := t.app.service.GetCachedReceipt(txn.Hash())
+	if !found {
+		if txn.BlockNumber() == nil {
+			return StyledReceiptStatus(nil)
+		}
+		return util.NAValue
+	}
+	return StyledReceiptStatus(receipt)
+}
+
+// styledAddress shows ENS name of address if known, otherwise the address
+func (t *TransactionList) styledAddress(address *common.Address) string {
+	if address != nil {
+		if name, found := t.app.service.GetENS().CachedName(*address); found && name != "" {
+			return fmt.Sprintf("[dodgerblue]%s[-]", tview.Escape(format.TruncateText(name, 20)))
+		}
+	}
+	return format.TruncateText(format.NormalizeReceiverAddress(address), 20)
+}
+
 // handleSelected shows a preview of selected transaction
 func (t *TransactionList) handleSelected(row int, column int) {
 	if row > 0 && row <= len(t.txns) {
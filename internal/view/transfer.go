@@ -2,20 +2,28 @@ package view
 
 import (
 	"fmt"
-	"math/big"
+	"strings"
 
+	"github.com/dyng/ramen/internal/common"
 	"github.com/dyng/ramen/internal/common/conv"
 	"github.com/dyng/ramen/internal/service"
 	"github.com/dyng/ramen/internal/view/format"
 	"github.com/dyng/ramen/internal/view/style"
 	"github.com/dyng/ramen/internal/view/util"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	gcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+	"github.com/shopspring/decimal"
 )
 
 const (
+	// erc20TransferGas is the typical gas used by an ERC-20 transfer, used
+	// for fee estimation before the exact gas limit is known.
+	erc20TransferGas = 65000
+
 	// transferDialogMinHeight is the minimum height of the transfer dialog.
 	transferDialogMinHeight = 10
 	// transferDialogMinWidth is the minimum width of the transfer dialog.
@@ -28,10 +36,14 @@ type TransferDialog struct {
 	display   bool
 	lastFocus tview.Primitive
 
-	sender *service.Signer
-	info   *SenderFormItem
-	to     *tview.InputField
-	amount *tview.InputField
+	sender   service.Signer
+	info     *SenderFormItem
+	asset    *tview.DropDown
+	to       *tview.InputField
+	amount   *tview.InputField
+	fee      *tview.TextView
+	feeData  *common.FeeData
+	balances []*service.TokenBalance // tokens held by sender
 }
 
 func NewTransferDialog(app *App) *TransferDialog {
@@ -66,11 +78,15 @@ func (d *TransferDialog) initLayout() {
 	form.SetButtonsAlign(tview.AlignRight)
 	form.SetButtonBackgroundColor(s.ButtonBgColor)
 	form.AddFormItem(info)
+	form.AddDropDown("Asset", []string{"ETH"}, 0, nil)
 	form.AddInputField("To", "", 999, nil, nil)
 	form.AddInputField("Amount", "", 999, nil, nil)
+	form.AddTextView("Fee", util.NAValue, 0, 2, true, false)
 	form.AddButton("Transfer", d.doTransfer)
+	d.asset = form.GetFormItemByLabel("Asset").(*tview.DropDown)
 	d.to = form.GetFormItemByLabel("To").(*tview.InputField)
 	d.amount = form.GetFormItemByLabel("Amount").(*tview.InputField)
+	d.fee = form.GetFormItemByLabel("Fee").(*tview.TextView)
 	d.Form = form
 }
 
@@ -85,7 +101,7 @@ func (d *TransferDialog) KeyMaps() util.KeyMaps {
 	return keymaps
 }
 
-func (d *TransferDialog) SetSender(account *service.Signer) {
+func (d *TransferDialog) SetSender(account service.Signer) {
 	d.sender = account
 	d.refresh()
 }
@@ -93,29 +109,168 @@ func (d *TransferDialog) SetSender(account *service.Signer) {
 func (d *TransferDialog) refresh() {
 	// refresh sender's information (e.g. balance)
 	d.info.SetSender(d.sender)
+
+	// reset asset options to ETH only until tokens are loaded
+	d.balances = nil
+	d.asset.SetOptions([]string{"ETH"}, d.onAssetSelected)
+	d.asset.SetCurrentOption(0)
+
+	// refresh fee and token balances asynchronously
+	d.feeData = nil
+	d.fee.SetText(util.NAValue)
+	sender := d.sender
+	go func() {
+		fee, err := d.app.service.GetFeeData()
+		if err != nil {
+			log.Error("Failed to fetch fee data", "error", err)
+		}
+
+		balances, err := d.app.service.GetTokenBalances(sender.GetAddress())
+		if err != nil {
+			log.Error("Failed to fetch token balances", "address", sender.GetAddress(), "error", err)
+		}
+
+		d.app.QueueUpdateDraw(func() {
+			// sender may have changed during loading
+			if d.sender != sender {
+				return
+			}
+			d.feeData = fee
+			d.setBalances(balances)
+			d.refreshFee()
+		})
+	}()
+}
+
+func (d *TransferDialog) setBalances(balances []*service.TokenBalance) {
+	d.balances = balances
+
+	options := []string{"ETH"}
+	for _, tb := range balances {
+		options = append(options, fmt.Sprintf("%s (%s)", tb.Token.Symbol, styledTokenAmount(tb)))
+	}
+	d.asset.SetOptions(options, d.onAssetSelected)
+	d.asset.SetCurrentOption(0)
+}
+
+func (d *TransferDialog) onAssetSelected(text string, index int) {
+	d.refreshFee()
+}
+
+func (d *TransferDialog) refreshFee() {
+	if d.feeData == nil {
+		d.fee.SetText(util.NAValue)
+		return
+	}
+
+	if d.selectedToken() == nil {
+		d.fee.SetText(StyledFee(d.feeData, params.TxGas))
+	} else {
+		d.fee.SetText(StyledFee(d.feeData, erc20TransferGas))
+	}
+}
+
+// selectedToken returns the token to transfer, or nil if ETH is selected.
+func (d *TransferDialog) selectedToken() *service.TokenBalance {
+	index, _ := d.asset.GetCurrentOption()
+	if index <= 0 || index > len(d.balances) {
+		return nil
+	}
+	return d.balances[index-1]
 }
 
 // doTransfer is core method that do the whole things
 func (d *TransferDialog) doTransfer() {
-	i, ok := new(big.Float).SetString(d.amount.GetText())
-	if !ok {
-		d.app.root.NotifyError(fmt.Sprintf("Cannot parse amount value %s", d.amount.GetText()))
+	toText := strings.TrimSpace(d.to.GetText())
+	if !gcommon.IsHexAddress(toText) {
+		d.app.root.NotifyError(fmt.Sprintf("Invalid receiver address %s", toText))
+		return
+	}
+	toAddr := gcommon.HexToAddress(toText)
+
+	token := d.selectedToken()
+	decimals := uint8(18)
+	if token != nil {
+		if !token.Token.DecimalsKnown {
+			d.app.root.NotifyError(fmt.Sprintf("Decimals of token %s is unknown, cannot transfer it", tview.Escape(token.Token.Symbol)))
+			return
+		}
+		decimals = token.Token.Decimals
+	}
+	amount, err := conv.ParseUnits(strings.TrimSpace(d.amount.GetText()), decimals)
+	if err != nil {
+		d.app.root.NotifyError(format.FineErrorMessage("Invalid amount", err))
 		return
 	}
 
 	// close dialog
 	d.Hide()
 
-	amount := conv.FromEther(i)
-	toAddr := gcommon.HexToAddress(d.to.GetText())
-	log.Info("Transfer ethers to another account", "from", d.sender.GetAddress(), "to", toAddr, "amount", amount)
+	sender := d.sender
+	go func() {
+		var (
+			txnReq      *common.TxnRequest
+			contractABI *abi.ABI
+			err         error
+		)
+		if token == nil {
+			log.Info("Transfer ethers to another account", "from", sender.GetAddress(), "to", toAddr, "amount", amount)
+			txnReq, err = sender.PrepareTransfer(toAddr, amount)
+		} else {
+			log.Info("Transfer tokens to another account", "token", token.Token.Address, "from", sender.GetAddress(), "to", toAddr, "amount", amount)
+			txnReq, err = sender.PrepareTokenTransfer(token.Token, toAddr, amount)
+			contractABI = &service.ERC20ABI
+		}
+
+		var sim *service.Simulation
+		if err == nil && !d.app.config.SkipSimulation {
+			var simErr error
+			sim, simErr = d.app.service.Simulate(txnReq, contractABI)
+			if simErr != nil {
+				log.Warn("Cannot simulate transfer", "error", simErr)
+			}
+		}
+
+		d.app.QueueUpdateDraw(func() {
+			if err != nil {
+				log.Error("Failed to prepare transfer", "error", err)
+				d.app.root.NotifyError(format.FineErrorMessage("Failed to prepare transfer", err))
+				return
+			}
+
+			summary := fmt.Sprintf("Transfer %s Ether to %s", format.Ether(amount), toAddr.Hex())
+			if token != nil {
+				summary = fmt.Sprintf("Transfer %s %s to %s",
+					decimal.NewFromBigInt(amount, -int32(decimals)), tview.Escape(token.Token.Symbol), toAddr.Hex())
+			}
+
+			// review the simulated result, or confirm the request if not simulated
+			if sim != nil {
+				d.app.root.ShowTxnReview(summary, txnReq, sim, func() {
+					d.send(sender, txnReq)
+				})
+				return
+			}
+			text := fmt.Sprintf("[::b]%s[::-]\n\n%s", summary, StyledTxnRequest(sender.GetAddress(), txnReq, contractABI))
+			d.app.root.ShowConfirmDialog("Confirm Transfer", text, func() {
+				d.send(sender, txnReq)
+			})
+		})
+	}()
+}
 
-	hash, err := d.sender.TransferTo(toAddr, amount)
-	if err != nil {
-		d.app.root.NotifyError(format.FineErrorMessage("Failed to complete transfer", err))
-	} else {
-		d.app.root.NotifyInfo(fmt.Sprintf("Transaction has been submitted.\n\nTxnHash: %s", hash))
-	}
+func (d *TransferDialog) send(sender service.Signer, txnReq *common.TxnRequest) {
+	go func() {
+		hash, err := sender.Send(txnReq)
+		d.app.QueueUpdateDraw(func() {
+			if err != nil {
+				log.Error("Failed to complete transfer", "error", err)
+				d.app.root.NotifyError(format.FineErrorMessage("Failed to complete transfer", err))
+			} else {
+				d.app.root.NotifyInfo(fmt.Sprintf("Transaction has been submitted.\n\nTxnHash: %s", hash))
+			}
+		})
+	}()
 }
 
 func (d *TransferDialog) Show() {
@@ -161,7 +316,7 @@ func (d *TransferDialog) Draw(screen tcell.Screen) {
 
 func (d *TransferDialog) SetCentral(x int, y int, width int, height int) {
 	dialogWidth := width - width/2
-	dialogHeight := style.AvatarSize + 12
+	dialogHeight := style.AvatarSize + 17
 	if dialogHeight < transferDialogMinHeight {
 		dialogHeight = transferDialogMinHeight
 	}
@@ -225,7 +380,7 @@ func NewSenderFormItem(app *App) *SenderFormItem {
 	return fi
 }
 
-func (s *SenderFormItem) SetSender(account *service.Signer) {
+func (s *SenderFormItem) SetSender(account service.Signer) {
 	addr := account.GetAddress()
 
 	// avatar
@@ -241,7 +396,7 @@ func (s *SenderFormItem) SetSender(account *service.Signer) {
 
 // Focus implements tview.Primitive
 func (s *SenderFormItem) Focus(delegate func(p tview.Primitive)) {
-	delegate(s.app.root.transfer.GetFormItemByLabel("To"))
+	delegate(s.app.root.transfer.GetFormItemByLabel("Asset"))
 }
 
 // GetFieldHeight implements tview.FormItem
@@ -1,6 +1,11 @@
 package view
 
 import (
+	"fmt"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/dyng/ramen/internal/service"
+	"github.com/dyng/ramen/internal/view/style"
 	"github.com/dyng/ramen/internal/view/util"
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -13,11 +18,15 @@ const (
 	txnPreviewDialogMinWidth = 50
 )
 
+// TxnPreviewDialog shows a transaction in brief. It can also review a
+// transaction request before it is signed, in which case Enter confirms and
+// sends it.
 type TxnPreviewDialog struct {
 	*TransactionDetail
 	app       *App
 	display   bool
 	lastFocus tview.Primitive
+	onConfirm func()
 }
 
 func NewTxnPreviewDialog(app *App) *TxnPreviewDialog {
@@ -33,6 +42,21 @@ func NewTxnPreviewDialog(app *App) *TxnPreviewDialog {
 	return d
 }
 
+// SetTransaction shows a transaction in brief
+func (d *TxnPreviewDialog) SetTransaction(transaction common.Transaction) {
+	d.onConfirm = nil
+	d.SetTitle(style.BoldPadding("Transaction Detail"))
+	d.TransactionDetail.SetTransaction(transaction)
+}
+
+// SetRequest shows a transaction request and result of its simulation for
+// review, onConfirm is called if user confirms it.
+func (d *TxnPreviewDialog) SetRequest(summary string, txnReq *common.TxnRequest, sim *service.Simulation, onConfirm func()) {
+	d.onConfirm = onConfirm
+	d.SetTitle(style.BoldPadding(fmt.Sprintf("%s (Enter to send, Esc to cancel)", summary)))
+	d.TransactionDetail.SetRequest(txnReq, sim)
+}
+
 func (d *TxnPreviewDialog) Show() {
 	if !d.display {
 		// save last focused element
@@ -45,6 +69,7 @@ func (d *TxnPreviewDialog) Show() {
 
 func (d *TxnPreviewDialog) Hide() {
 	if d.display {
+		d.onConfirm = nil
 		d.Display(false)
 		d.app.SetFocus(d.lastFocus)
 	}
@@ -58,19 +83,43 @@ func (d *TxnPreviewDialog) initKeymap() {
 func (d *TxnPreviewDialog) KeyMaps() util.KeyMaps {
 	keymaps := make(util.KeyMaps, 0)
 	keymaps = append(keymaps, util.NewSimpleKey(tcell.KeyEsc, d.Hide))
-	keymaps = append(keymaps, util.NewSimpleKey(tcell.KeyEnter, d.Hide))
+	keymaps = append(keymaps, util.NewSimpleKey(tcell.KeyEnter, d.confirm))
 	keymaps = append(keymaps, util.NewSimpleKey(util.KeySpace, d.Hide))
 	keymaps = append(keymaps, util.NewSimpleKey(util.KeyF, func() {
-		d.Hide()
-		d.ViewSender()
+		if !d.isReview() {
+			d.Hide()
+			d.ViewSender()
+		}
 	}))
 	keymaps = append(keymaps, util.NewSimpleKey(util.KeyT, func() {
-		d.Hide()
-		d.ViewReceiver()
+		if !d.isReview() {
+			d.Hide()
+			d.ViewReceiver()
+		}
+	}))
+	keymaps = append(keymaps, util.NewSimpleKey(util.KeyE, func() {
+		if !d.isReview() {
+			d.Hide()
+			d.logs.ViewEmitter()
+		}
 	}))
 	return keymaps
 }
 
+// confirm sends the reviewed request, or simply closes the dialog if it is
+// not in review.
+func (d *TxnPreviewDialog) confirm() {
+	onConfirm := d.onConfirm
+	d.Hide()
+	if onConfirm != nil {
+		onConfirm()
+	}
+}
+
+func (d *TxnPreviewDialog) isReview() bool {
+	return d.onConfirm != nil
+}
+
 func (d *TxnPreviewDialog) Display(display bool) {
 	d.display = display
 }
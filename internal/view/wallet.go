@@ -0,0 +1,195 @@
+package view
+
+import (
+	"strings"
+
+	"github.com/dyng/ramen/internal/common"
+	"github.com/dyng/ramen/internal/common/conv"
+	"github.com/dyng/ramen/internal/service"
+	"github.com/dyng/ramen/internal/view/style"
+	"github.com/dyng/ramen/internal/view/util"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	// walletDialogMinHeight is the minimum height of the wallet dialog.
+	walletDialogMinHeight = 8
+	// walletDialogMinWidth is the minimum width of the wallet dialog.
+	walletDialogMinWidth = 50
+)
+
+// WalletDialog lists signed in accounts and switches the active one.
+type WalletDialog struct {
+	*tview.Table
+	app       *App
+	display   bool
+	lastFocus tview.Primitive
+
+	accounts []*service.WalletAccount
+	balances map[common.Address]common.BigInt
+}
+
+func NewWalletDialog(app *App) *WalletDialog {
+	d := &WalletDialog{
+		Table:    tview.NewTable(),
+		app:      app,
+		display:  false,
+		accounts: []*service.WalletAccount{},
+		balances: map[common.Address]common.BigInt{},
+	}
+
+	// setup layout
+	d.initLayout()
+
+	// setup keymap
+	d.initKeymap()
+
+	return d
+}
+
+func (d *WalletDialog) initLayout() {
+	s := d.app.config.Style()
+
+	d.SetBorder(true)
+	d.SetBorderColor(s.DialogBorderColor)
+	d.SetTitle(style.BoldPadding("Wallet"))
+	d.SetTitleColor(s.TitleColor)
+
+	headers := []string{"", "label", "address", "backend", "balance"}
+	for i, header := range headers {
+		d.SetCell(0, i,
+			tview.NewTableCell(strings.ToUpper(header)).
+				SetExpansion(1).
+				SetAlign(tview.AlignLeft).
+				SetStyle(s.TableHeaderStyle).
+				SetSelectable(false))
+	}
+	d.SetSelectable(true, false)
+	d.SetFixed(1, 1)
+	d.SetSelectedFunc(d.handleSelected)
+}
+
+func (d *WalletDialog) initKeymap() {
+	InitKeymap(d, d.app)
+}
+
+// KeyMaps implements KeymapPrimitive
+func (d *WalletDialog) KeyMaps() util.KeyMaps {
+	keymaps := make(util.KeyMaps, 0)
+	keymaps = append(keymaps, util.NewSimpleKey(tcell.KeyEsc, d.Hide))
+	return keymaps
+}
+
+// SetWallet shows accounts of wallet and refreshes their balances
+func (d *WalletDialog) SetWallet(wallet *service.Wallet) {
+	d.accounts = wallet.Accounts()
+	d.refresh(wallet.ActiveIndex())
+	d.loadBalancesAsync(wallet)
+}
+
+func (d *WalletDialog) loadBalancesAsync(wallet *service.Wallet) {
+	accounts := d.accounts
+	go func() {
+		for _, account := range accounts {
+			addr := account.Signer.GetAddress()
+			bal, err := account.Signer.GetBalanceForce()
+			if err != nil {
+				log.Error("Failed to fetch balance", "address", addr, "error", err)
+				continue
+			}
+			d.app.QueueUpdateDraw(func() {
+				d.balances[addr] = bal
+				d.refresh(wallet.ActiveIndex())
+			})
+		}
+	}()
+}
+
+func (d *WalletDialog) refresh(active int) {
+	// clear previous content at first
+	for i := d.GetRowCount() - 1; i > 0; i-- {
+		d.RemoveRow(i)
+	}
+
+	for i, account := range d.accounts {
+		row := i + 1
+		addr := account.Signer.GetAddress()
+
+		mark := ""
+		if i == active {
+			mark = "[lightgreen]*[-]"
+		}
+		balance := util.NAValue
+		if bal, ok := d.balances[addr]; ok {
+			balance = conv.ToEther(bal).String()
+		}
+
+		j := 0
+		d.SetCell(row, Inc(&j), tview.NewTableCell(mark))
+		d.SetCell(row, Inc(&j), tview.NewTableCell(tview.Escape(account.Label)))
+		d.SetCell(row, Inc(&j), tview.NewTableCell(addr.Hex()))
+		d.SetCell(row, Inc(&j), tview.NewTableCell(account.Signer.Backend()))
+		d.SetCell(row, Inc(&j), tview.NewTableCell(balance))
+	}
+
+	if active >= 0 {
+		d.Select(active+1, 0)
+	}
+}
+
+func (d *WalletDialog) handleSelected(row int, column int) {
+	if row <= 0 || row > len(d.accounts) {
+		return
+	}
+
+	d.Hide()
+	d.app.root.SwitchSigner(row - 1)
+}
+
+func (d *WalletDialog) Show() {
+	if !d.display {
+		// save last focused element
+		d.lastFocus = d.app.GetFocus()
+
+		d.Display(true)
+		d.app.SetFocus(d)
+	}
+}
+
+func (d *WalletDialog) Hide() {
+	if d.display {
+		d.Display(false)
+		d.app.SetFocus(d.lastFocus)
+	}
+}
+
+func (d *WalletDialog) Display(display bool) {
+	d.display = display
+}
+
+func (d *WalletDialog) IsDisplay() bool {
+	return d.display
+}
+
+// Draw implements tview.Primitive
+func (d *WalletDialog) Draw(screen tcell.Screen) {
+	if d.display {
+		d.Table.Draw(screen)
+	}
+}
+
+func (d *WalletDialog) SetCentral(x int, y int, width int, height int) {
+	dialogWidth := width - width/4
+	dialogHeight := height / 2
+	if dialogHeight < walletDialogMinHeight {
+		dialogHeight = walletDialogMinHeight
+	}
+	if dialogWidth < walletDialogMinWidth {
+		dialogWidth = walletDialogMinWidth
+	}
+	dialogX := x + ((width - dialogWidth) / 2)
+	dialogY := y + ((height - dialogHeight) / 2)
+	d.Table.SetRect(dialogX, dialogY, dialogWidth, dialogHeight)
+}